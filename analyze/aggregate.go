@@ -0,0 +1,101 @@
+package analyze
+
+import (
+	"math"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// Aggregates holds the metrics computed over a set of tickets in a single pass. Apart from Total, all metrics
+// only take into account high priority tickets closed within jira.MaxTimeToCloseH hours.
+type Aggregates struct {
+	Total  int
+	Closed int
+
+	MeanTimeToClose float64
+	MinTimeToClose  float64
+	MaxTimeToClose  float64
+
+	// Coverage fractions of the closed tickets having the given property.
+	AttachmentsCoverage      float64
+	StepsToReproduceCoverage float64
+	StackTracesCoverage      float64
+	SentimentCoverage        float64
+	GrammarCoverage          float64
+
+	// Series of values for the closed tickets, index-aligned with TimesToClose.
+	TimesToClose           []float64
+	CommentWordsCounts     []float64
+	SummaryDescWordsCounts []float64
+
+	// Times-to-close of the closed tickets grouped by sprint name, operating system and priority name.
+	BySprint   map[string][]float64
+	ByOS       map[string][]float64
+	ByPriority map[string][]float64
+}
+
+// Aggregate computes the time-to-close statistics, coverage fractions, word count series and groupings
+// of a variadic number of tickets in a single pass over them.
+func Aggregate(tickets ...jira.JiraIssue) Aggregates {
+	agg := Aggregates{
+		Total:          len(tickets),
+		MinTimeToClose: math.Inf(1),
+		MaxTimeToClose: math.Inf(-1),
+		BySprint:       make(map[string][]float64),
+		ByOS:           make(map[string][]float64),
+		ByPriority:     make(map[string][]float64),
+	}
+	var attachments, steps, stackTraces, sentiment, grammar int
+	var total float64
+	for _, ticket := range tickets {
+		if !jira.IsHighPriority(ticket) ||
+			ticket.TimeToClose <= 0 ||
+			ticket.TimeToClose > jira.MaxTimeToCloseH {
+			continue
+		}
+		ttc := ticket.TimeToClose
+		agg.Closed++
+		total += ttc
+		agg.MinTimeToClose = math.Min(agg.MinTimeToClose, ttc)
+		agg.MaxTimeToClose = math.Max(agg.MaxTimeToClose, ttc)
+		if len(ticket.Fields.Attachments) > 0 {
+			attachments++
+		}
+		if ticket.HasStepsToReproduce {
+			steps++
+		}
+		if ticket.HasStackTrace {
+			stackTraces++
+		}
+		if ticket.Sentiment.HasScore {
+			sentiment++
+		}
+		if ticket.GrammarCorrectness.HasScore {
+			grammar++
+		}
+		agg.TimesToClose = append(agg.TimesToClose, ttc)
+		agg.CommentWordsCounts = append(agg.CommentWordsCounts, float64(CommentWords(ticket)))
+		agg.SummaryDescWordsCounts = append(agg.SummaryDescWordsCounts, float64(SummaryDescWords(ticket)))
+		for _, sprint := range ticket.Fields.Sprints {
+			agg.BySprint[sprint.Name] = append(agg.BySprint[sprint.Name], ttc)
+		}
+		osName := ParseEnvironment(ticket.Fields.Environment).OS
+		if osName == "" {
+			osName = "Unknown"
+		}
+		agg.ByOS[osName] = append(agg.ByOS[osName], ttc)
+		agg.ByPriority[ticket.Fields.Priority.Name] = append(agg.ByPriority[ticket.Fields.Priority.Name], ttc)
+	}
+	if agg.Closed == 0 {
+		agg.MinTimeToClose, agg.MaxTimeToClose = 0, 0
+		return agg
+	}
+	closed := float64(agg.Closed)
+	agg.MeanTimeToClose = total / closed
+	agg.AttachmentsCoverage = float64(attachments) / closed
+	agg.StepsToReproduceCoverage = float64(steps) / closed
+	agg.StackTracesCoverage = float64(stackTraces) / closed
+	agg.SentimentCoverage = float64(sentiment) / closed
+	agg.GrammarCoverage = float64(grammar) / closed
+	return agg
+}
@@ -0,0 +1,73 @@
+package analyze
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestAggregate(t *testing.T) {
+	newTicket := func(key, priority string, hours float64, env, sprint string) jira.JiraIssue {
+		ticket := jira.JiraIssue{Key: key, TimeToClose: hours}
+		ticket.Fields.Priority = jira.Priority{ID: priority, Name: "P" + priority}
+		ticket.Fields.Environment = env
+		ticket.Fields.Summary = "login page broken"
+		if sprint != "" {
+			ticket.Fields.Sprints = []jira.Sprint{{Name: sprint}}
+		}
+		return ticket
+	}
+	withAttachment := newTicket("A-2", "2", 30, "Ubuntu 22.04", "Sprint 1")
+	withAttachment.Fields.Attachments = []jira.Attachment{{Type: jira.ImageAttachment}}
+	withAttachment.HasStepsToReproduce = true
+	withAttachment.Sentiment.HasScore = true
+	tickets := []jira.JiraIssue{
+		newTicket("A-1", "1", 10, "Windows 10", "Sprint 1"),
+		withAttachment,
+		newTicket("A-3", "1", 20, "", "Sprint 2"),
+		newTicket("A-4", "5", 40, "Windows 10", ""),
+		newTicket("A-5", "1", 0, "Windows 10", ""),
+		newTicket("A-6", "1", jira.MaxTimeToCloseH+1, "", ""),
+	}
+
+	agg := Aggregate(tickets...)
+	if agg.Total != 6 || agg.Closed != 3 {
+		t.Fatalf("Aggregate counted %d tickets, %d closed; want 6 and 3", agg.Total, agg.Closed)
+	}
+	if agg.MeanTimeToClose != 20 || agg.MinTimeToClose != 10 || agg.MaxTimeToClose != 30 {
+		t.Errorf("time-to-close mean, min, max = %v, %v, %v; want 20, 10, 30",
+			agg.MeanTimeToClose, agg.MinTimeToClose, agg.MaxTimeToClose)
+	}
+	third := 1.0 / 3
+	if agg.AttachmentsCoverage != third || agg.StepsToReproduceCoverage != third || agg.SentimentCoverage != third ||
+		agg.StackTracesCoverage != 0 || agg.GrammarCoverage != 0 {
+		t.Errorf("coverage fractions = %+v", agg)
+	}
+	if !reflect.DeepEqual(agg.TimesToClose, []float64{10, 30, 20}) {
+		t.Errorf("TimesToClose = %v", agg.TimesToClose)
+	}
+	if !reflect.DeepEqual(agg.SummaryDescWordsCounts, []float64{3, 3, 3}) {
+		t.Errorf("SummaryDescWordsCounts = %v", agg.SummaryDescWordsCounts)
+	}
+
+	// the single pass yields the same groupings as the dedicated analyses over the same closed tickets.
+	closed := []jira.JiraIssue{tickets[0], tickets[1], tickets[2]}
+	if want := TimeToResolveBySprint(closed...); !reflect.DeepEqual(agg.BySprint, want) {
+		t.Errorf("BySprint = %v, want %v", agg.BySprint, want)
+	}
+	if want := TimeToResolveByOS(closed...); !reflect.DeepEqual(agg.ByOS, want) {
+		t.Errorf("ByOS = %v, want %v", agg.ByOS, want)
+	}
+	if want := map[string][]float64{"P1": {10, 20}, "P2": {30}}; !reflect.DeepEqual(agg.ByPriority, want) {
+		t.Errorf("ByPriority = %v, want %v", agg.ByPriority, want)
+	}
+}
+
+func TestAggregateWithoutClosedTickets(t *testing.T) {
+	agg := Aggregate(jira.JiraIssue{Key: "A-1"})
+	if agg.Total != 1 || agg.Closed != 0 || agg.MinTimeToClose != 0 || agg.MaxTimeToClose != 0 ||
+		agg.MeanTimeToClose != 0 || agg.AttachmentsCoverage != 0 {
+		t.Errorf("Aggregate of an open ticket = %+v, want zero metrics", agg)
+	}
+}
@@ -1,9 +1,13 @@
 package analyze
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"os"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/nclandrei/ticketguru/jira"
@@ -13,54 +17,609 @@ import (
 // their metrics fields accordingly.
 type TicketAnalysis func(...jira.JiraIssue)
 
+// Parallel returns an analysis running f concurrently on up to workers contiguous, disjoint partitions of the
+// tickets. f must only update the tickets it is given and not depend on the other ones, as is the case for all
+// the local analyses of this package; a workers value below 2 runs f as is.
+func Parallel(workers int, f TicketAnalysis) TicketAnalysis {
+	if workers < 2 {
+		return f
+	}
+	return func(tickets ...jira.JiraIssue) {
+		size := (len(tickets) + workers - 1) / workers
+		if size == 0 {
+			return
+		}
+		var wg sync.WaitGroup
+		for low := 0; low < len(tickets); low += size {
+			high := low + size
+			if high > len(tickets) {
+				high = len(tickets)
+			}
+			wg.Add(1)
+			go func(partition []jira.JiraIssue) {
+				defer wg.Done()
+				f(partition...)
+			}(tickets[low:high])
+		}
+		wg.Wait()
+	}
+}
+
+// terminalStatuses holds the status names considered terminal for tickets whose status category is unknown.
+var terminalStatuses = map[string]bool{
+	"Closed":    true,
+	"Resolved":  true,
+	"Done":      true,
+	"Completed": true,
+	"Fixed":     true,
+}
+
+// workflowStatuses states whether terminalStatuses was derived from the statuses of the Jira instance's
+// workflows, in which case it is trusted over the status category of the tickets' current status.
+var workflowStatuses bool
+
+// UseWorkflowStatuses replaces the well known terminal status names with the statuses of the Jira instance
+// belonging to the done category, e.g. as returned by jira.Client.FetchStatuses. It is not safe to call while
+// tickets are being analyzed.
+func UseWorkflowStatuses(statuses []jira.Status) {
+	terminal := make(map[string]bool)
+	for _, status := range statuses {
+		if status.StatusCategory.Key == jira.DoneStatusCategory {
+			terminal[status.Name] = true
+		}
+	}
+	terminalStatuses = terminal
+	workflowStatuses = true
+}
+
+// ResolutionOptions configures how the time it took to resolve a ticket is computed.
+type ResolutionOptions struct {
+	// ExcludedStatuses holds the statuses (e.g. "Waiting for Customer") whose time is not counted.
+	ExcludedStatuses []string
+	// ExcludedResolutions holds the resolutions (e.g. "Duplicate") of the tickets left without a time-to-close.
+	ExcludedResolutions []string
+	// Calendar, if set, makes resolution times count only the working hours of the calendar.
+	Calendar *WorkCalendar
+	// LastTransition makes reopened tickets count as resolved at their last transition into a terminal status
+	// rather than at their first one.
+	LastTransition bool
+}
+
+// hoursBetween returns the number of hours counted between start and end according to the options.
+func (opts ResolutionOptions) hoursBetween(start, end time.Time) float64 {
+	if opts.Calendar != nil {
+		return BusinessHoursBetween(start, end, *opts.Calendar)
+	}
+	return end.Sub(start).Hours()
+}
+
+// DuplicateResolutions holds the resolutions of tickets closed as duplicates, which distort resolution times.
+var DuplicateResolutions = []string{"Duplicate"}
+
+// HasResolution returns whether a ticket is resolved with any of the given resolutions, compared case-insensitively.
+// Blank resolutions are ignored rather than matching the tickets without a resolution.
+func HasResolution(ticket jira.JiraIssue, resolutions []string) bool {
+	for _, resolution := range resolutions {
+		resolution = strings.TrimSpace(resolution)
+		if resolution != "" && strings.EqualFold(resolution, ticket.Fields.Resolution.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExcludeResolutions returns the tickets which are not resolved with any of the given resolutions, alongside
+// the number of excluded tickets.
+func ExcludeResolutions(resolutions []string, tickets ...jira.JiraIssue) ([]jira.JiraIssue, int) {
+	var result []jira.JiraIssue
+	for _, ticket := range tickets {
+		if !HasResolution(ticket, resolutions) {
+			result = append(result, ticket)
+		}
+	}
+	return result, len(tickets) - len(result)
+}
+
+// IsExcludedProject returns whether the key of a ticket starts with any of the given project key prefixes, e.g.
+// TEST- for the tickets of the TEST project only or INT for those of all projects whose key starts with INT,
+// compared case-insensitively.
+func IsExcludedProject(ticket jira.JiraIssue, prefixes []string) bool {
+	key := strings.ToUpper(ticket.Key)
+	for _, prefix := range prefixes {
+		if prefix = strings.ToUpper(strings.TrimSpace(prefix)); prefix != "" && strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExcludeProjects returns the tickets which do not belong to any of the projects matched by the given key
+// prefixes, e.g. internal or test projects, alongside the number of excluded tickets.
+func ExcludeProjects(prefixes []string, tickets ...jira.JiraIssue) ([]jira.JiraIssue, int) {
+	var result []jira.JiraIssue
+	for _, ticket := range tickets {
+		if !IsExcludedProject(ticket, prefixes) {
+			result = append(result, ticket)
+		}
+	}
+	return result, len(tickets) - len(result)
+}
+
 // TimesToClose returns how much time it took to close a variadic number of tickets.
 func TimesToClose(tickets ...jira.JiraIssue) {
-	var count int
+	TimesToCloseWith(ResolutionOptions{})(tickets...)
+}
+
+// TimesToCloseWith returns an analysis computing how much time it took to close a variadic number of
+// tickets according to the given options, printing how many were resolved, skipped or excluded to stderr.
+func TimesToCloseWith(opts ResolutionOptions) TicketAnalysis {
+	return func(tickets ...jira.JiraIssue) {
+		ResolveTimesToClose(opts, tickets...).report()
+	}
+}
+
+// ParallelTimesToClose returns an analysis like TimesToCloseWith running on up to workers partitions of the
+// tickets, as with Parallel, but printing the counts of all the partitions once instead of once per partition.
+func ParallelTimesToClose(workers int, opts ResolutionOptions) TicketAnalysis {
+	return func(tickets ...jira.JiraIssue) {
+		resolveInParallel(workers, opts, tickets...).report()
+	}
+}
+
+// resolveInParallel runs ResolveTimesToClose on up to workers partitions of the tickets and sums their counts.
+func resolveInParallel(workers int, opts ResolutionOptions, tickets ...jira.JiraIssue) ResolutionCounts {
+	var mu sync.Mutex
+	var total ResolutionCounts
+	Parallel(workers, func(partition ...jira.JiraIssue) {
+		counts := ResolveTimesToClose(opts, partition...)
+		mu.Lock()
+		defer mu.Unlock()
+		total.Resolved += counts.Resolved
+		total.Anomalies += counts.Anomalies
+		total.Excluded += counts.Excluded
+	})(tickets...)
+	return total
+}
+
+// ResolutionCounts holds how many tickets a computation of their times-to-close resolved, skipped or excluded.
+type ResolutionCounts struct {
+	// Resolved holds the number of high priority tickets found resolved.
+	Resolved int
+	// Anomalies holds the number of tickets skipped for their untrustworthy timestamps.
+	Anomalies int
+	// Excluded holds the number of tickets left without a time-to-close for their resolution.
+	Excluded int
+}
+
+// report prints the counts to stderr.
+func (c ResolutionCounts) report() {
+	fmt.Fprintln(os.Stderr, c.Resolved)
+	if c.Anomalies > 0 {
+		fmt.Fprintf(os.Stderr, "skipped %d tickets with anomalous timestamps\n", c.Anomalies)
+	}
+	if c.Excluded > 0 {
+		fmt.Fprintf(os.Stderr, "excluded %d tickets by resolution\n", c.Excluded)
+	}
+}
+
+// ResolveTimesToClose computes how much time it took to close a variadic number of tickets according to the
+// given options, like TimesToCloseWith, but returns the counts instead of printing them.
+func ResolveTimesToClose(opts ResolutionOptions, tickets ...jira.JiraIssue) ResolutionCounts {
+	var counts ResolutionCounts
 	for i := range tickets {
-		if !isTicketHighPriority(tickets[i]) || tickets[i].Fields.Status.Name == "Open" {
+		if !isTicketHighPriority(tickets[i]) {
 			continue
 		}
-		var closed bool
-		for _, history := range tickets[i].Changelog.Histories {
-			for _, item := range history.Items {
-				if item.Field == "status" && (item.ToString == "Closed" || item.ToString == "Resolved" ||
-					item.ToString == "Done" || item.ToString == "Completed" || item.ToString == "Fixed") {
-					tickets[i].TimeToClose = calculateTimeDifference(history.Created, tickets[i].Fields.Created)
-					count++
-					closed = true
-					break
-				}
-			}
-			if closed {
-				break
-			}
-		}
-		if !closed {
+		if HasResolution(tickets[i], opts.ExcludedResolutions) {
 			tickets[i].TimeToClose = 0
+			counts.Excluded++
+			continue
+		}
+		ttc, ok, err := timeToResolve(tickets[i], opts)
+		if err != nil {
+			counts.Anomalies++
+		}
+		if ok {
+			counts.Resolved++
+		}
+		tickets[i].TimeToClose = ttc
+	}
+	return counts
+}
+
+var (
+	// ErrCreatedInFuture is returned for tickets whose creation time lies in the future.
+	ErrCreatedInFuture = errors.New("ticket is created in the future")
+	// ErrResolvedBeforeCreated is returned for tickets whose resolution precedes their creation.
+	ErrResolvedBeforeCreated = errors.New("ticket is resolved before being created")
+)
+
+// TimestampAnomalies returns, indexed by ticket key, the tickets whose timestamps cannot be trusted to compute
+// their time-to-close along with the reason why.
+func TimestampAnomalies(tickets ...jira.JiraIssue) map[string]error {
+	anomalies := make(map[string]error)
+	for _, ticket := range tickets {
+		if _, _, err := timeToResolve(ticket, ResolutionOptions{}); err != nil {
+			anomalies[ticket.Key] = err
+		}
+	}
+	return anomalies
+}
+
+// timeToResolve returns the number of hours, counted according to the options, between the creation of a ticket and its resolution, minus the
+// hours spent in excluded statuses, and whether the ticket is resolved at all. Tickets created in the future or
+// resolved before being created are reported as unresolved alongside the anomaly.
+func timeToResolve(ticket jira.JiraIssue, opts ResolutionOptions) (float64, bool, error) {
+	created := time.Time(ticket.Fields.Created)
+	if created.After(time.Now()) {
+		return 0, false, ErrCreatedInFuture
+	}
+	resolvedAt, ok := resolutionTime(ticket)
+	if opts.LastTransition {
+		resolvedAt, ok = lastResolutionTime(ticket)
+	}
+	if !ok {
+		return 0, false, nil
+	}
+	if resolvedAt.Before(created) {
+		return 0, false, ErrResolvedBeforeCreated
+	}
+	ttc := opts.hoursBetween(created, resolvedAt)
+	if len(opts.ExcludedStatuses) > 0 {
+		timeInStatus := timeInStatus(ticket, resolvedAt, opts.hoursBetween)
+		for _, status := range opts.ExcludedStatuses {
+			ttc -= timeInStatus[status]
 		}
 	}
-	fmt.Println(count)
+	return ttc, true, nil
 }
 
-// FieldsComplexity counts the number of words in summary and description for a variadic number of tickets.
+// resolutionTime returns the time of a resolved ticket's first transition into a terminal status.
+func resolutionTime(ticket jira.JiraIssue) (time.Time, bool) {
+	if !isResolved(ticket) {
+		return time.Time{}, false
+	}
+	for _, t := range statusTransitions(ticket) {
+		if isTerminalStatus(ticket, t.To) {
+			return t.At, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// lastResolutionTime returns the time of a resolved ticket's last transition into a terminal status.
+func lastResolutionTime(ticket jira.JiraIssue) (time.Time, bool) {
+	if !isResolved(ticket) {
+		return time.Time{}, false
+	}
+	transitions := statusTransitions(ticket)
+	for i := len(transitions) - 1; i >= 0; i-- {
+		if isTerminalStatus(ticket, transitions[i].To) {
+			return transitions[i].At, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// isResolved returns whether a ticket currently is in a terminal state, i.e. its status belongs to the
+// done category or, if the category is unknown, its status name is a terminal one.
+func isResolved(ticket jira.JiraIssue) bool {
+	if ticket.Fields.Status.StatusCategory.Key != "" {
+		return ticket.Fields.Status.StatusCategory.Key == jira.DoneStatusCategory
+	}
+	return terminalStatuses[ticket.Fields.Status.Name]
+}
+
+// isTerminalStatus returns whether a status the ticket transitioned into is a terminal one. Unless the workflow
+// statuses are in use, only its current status is known to be terminal if the ticket's status category is known.
+func isTerminalStatus(ticket jira.JiraIssue, status string) bool {
+	if workflowStatuses {
+		return terminalStatuses[status]
+	}
+	if ticket.Fields.Status.StatusCategory.Key != "" {
+		return status == ticket.Fields.Status.Name
+	}
+	return terminalStatuses[status]
+}
+
+// FieldsComplexity counts the number of words in summary and description for a variadic number of tickets,
+// reusing the counts stored by WordCounts if any.
 func FieldsComplexity(tickets ...jira.JiraIssue) {
 	for i := range tickets {
 		if isTicketHighPriority(tickets[i]) {
-			tickets[i].SummaryDescWordsCount = calculateNumberOfWords(tickets[i].Fields.Description) +
-				calculateNumberOfWords(tickets[i].Fields.Summary)
+			tickets[i].SummaryDescWordsCount = SummaryDescWords(tickets[i])
 		}
 	}
 }
 
-// CommentsComplexity counts the number of words in all comments for a variadic number of tickets.
+// SummaryComplexity counts the number of words in summary for a variadic number of tickets, reusing the counts
+// stored by WordCounts if any.
+func SummaryComplexity(tickets ...jira.JiraIssue) {
+	for i := range tickets {
+		if isTicketHighPriority(tickets[i]) {
+			tickets[i].SummaryWordsCount = SummaryWords(tickets[i])
+		}
+	}
+}
+
+// DescriptionComplexity counts the number of words in description for a variadic number of tickets, reusing the
+// counts stored by WordCounts if any.
+func DescriptionComplexity(tickets ...jira.JiraIssue) {
+	for i := range tickets {
+		if isTicketHighPriority(tickets[i]) {
+			tickets[i].DescriptionWordsCount = DescriptionWords(tickets[i])
+		}
+	}
+}
+
+// CommentsComplexity counts the number of words in all comments for a variadic number of tickets, reusing the
+// counts stored by WordCounts if any.
 func CommentsComplexity(tickets ...jira.JiraIssue) {
 	for i := range tickets {
 		if isTicketHighPriority(tickets[i]) {
-			tickets[i].CommentWordsCount = calculateNumberOfWords(concatComments(tickets[i]))
+			tickets[i].CommentWordsCount = CommentWords(tickets[i])
 		}
 	}
 }
 
+// WordCounts computes and stores the summary, description and comment word counts of a variadic number of
+// tickets, regardless of their priority, so that later analyses and plots read them instead of recounting.
+// It should be run again whenever the text of the tickets changes to refresh the stored counts.
+func WordCounts(tickets ...jira.JiraIssue) {
+	for i := range tickets {
+		tickets[i].SummaryWordsCount = calculateNumberOfWords(tickets[i].Fields.Summary)
+		tickets[i].DescriptionWordsCount = calculateNumberOfWords(tickets[i].Fields.Description)
+		tickets[i].SummaryDescWordsCount = tickets[i].SummaryWordsCount + tickets[i].DescriptionWordsCount
+		tickets[i].CommentWordsCount = calculateNumberOfWords(concatComments(tickets[i]))
+		tickets[i].CodelessSummaryDescWordsCount = codelessSummaryDescWords(tickets[i])
+		tickets[i].CodelessCommentWordsCount = codelessCommentWords(tickets[i])
+		tickets[i].HasWordCounts = true
+	}
+}
+
+// SummaryWords returns the number of words in the summary of a ticket, as stored by WordCounts or computed
+// on demand if the counts have not been stored.
+func SummaryWords(ticket jira.JiraIssue) int {
+	if ticket.HasWordCounts {
+		return ticket.SummaryWordsCount
+	}
+	return calculateNumberOfWords(ticket.Fields.Summary)
+}
+
+// DescriptionWords returns the number of words in the description of a ticket, as stored by WordCounts or
+// computed on demand if the counts have not been stored.
+func DescriptionWords(ticket jira.JiraIssue) int {
+	if ticket.HasWordCounts {
+		return ticket.DescriptionWordsCount
+	}
+	return calculateNumberOfWords(ticket.Fields.Description)
+}
+
+// SummaryDescWords returns the number of words in the summary and description of a ticket, as stored by
+// WordCounts or computed on demand if the counts have not been stored.
+func SummaryDescWords(ticket jira.JiraIssue) int {
+	if ticket.HasWordCounts {
+		return ticket.SummaryDescWordsCount
+	}
+	return SummaryWords(ticket) + DescriptionWords(ticket)
+}
+
+// CommentWords returns the number of words in all comments of a ticket, as stored by WordCounts or computed
+// on demand if the counts have not been stored.
+func CommentWords(ticket jira.JiraIssue) int {
+	if ticket.HasWordCounts {
+		return ticket.CommentWordsCount
+	}
+	return calculateNumberOfWords(concatComments(ticket))
+}
+
+// CodelessSummaryDescWords returns the number of words in the summary and description of a ticket once code
+// blocks are stripped, as stored by WordCounts or computed on demand if the counts have not been stored.
+func CodelessSummaryDescWords(ticket jira.JiraIssue) int {
+	if ticket.HasWordCounts {
+		return ticket.CodelessSummaryDescWordsCount
+	}
+	return codelessSummaryDescWords(ticket)
+}
+
+// CodelessCommentWords returns the number of words in all comments of a ticket once code blocks are stripped,
+// as stored by WordCounts or computed on demand if the counts have not been stored.
+func CodelessCommentWords(ticket jira.JiraIssue) int {
+	if ticket.HasWordCounts {
+		return ticket.CodelessCommentWordsCount
+	}
+	return codelessCommentWords(ticket)
+}
+
+// codelessSummaryDescWords counts the words in the summary and description of a ticket without code blocks.
+func codelessSummaryDescWords(ticket jira.JiraIssue) int {
+	return calculateNumberOfWords(StripCode(ticket.Fields.Summary)) +
+		calculateNumberOfWords(StripCode(ticket.Fields.Description))
+}
+
+// codelessCommentWords counts the words in all comments of a ticket without code blocks.
+func codelessCommentWords(ticket jira.JiraIssue) int {
+	var count int
+	for _, comment := range uniqueComments(ticket) {
+		count += calculateNumberOfWords(StripCode(comment.Body))
+	}
+	return count
+}
+
+// TimeToResolveBySprint groups the times-to-close of closed tickets by the name of the sprints they were part of;
+// a ticket part of multiple sprints contributes to each of them.
+func TimeToResolveBySprint(tickets ...jira.JiraIssue) map[string][]float64 {
+	result := make(map[string][]float64)
+	for _, ticket := range tickets {
+		if ticket.TimeToClose <= 0 {
+			continue
+		}
+		for _, sprint := range ticket.Fields.Sprints {
+			result[sprint.Name] = append(result[sprint.Name], ticket.TimeToClose)
+		}
+	}
+	return result
+}
+
+// IsLowSignal returns whether a ticket holds fewer than minWords words across its summary, description and comments,
+// the words of each comment being counted separately.
+func IsLowSignal(ticket jira.JiraIssue, minWords int) bool {
+	return SummaryDescWords(ticket)+CommentWords(ticket) < minWords
+}
+
+// ExcludeLowSignal returns the tickets which are not low signal given a minimum number of words.
+func ExcludeLowSignal(minWords int, tickets ...jira.JiraIssue) []jira.JiraIssue {
+	var result []jira.JiraIssue
+	for _, ticket := range tickets {
+		if !IsLowSignal(ticket, minWords) {
+			result = append(result, ticket)
+		}
+	}
+	return result
+}
+
+// ReassignmentCount returns the number of times a ticket's assignee has been changed according to its changelog.
+func ReassignmentCount(ticket jira.JiraIssue) int {
+	var count int
+	for _, history := range ticket.Changelog.Histories {
+		for _, item := range history.Items {
+			if item.Field == "assignee" {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// DescriptionCommentRatio returns the fraction of a ticket's description and comment words found in its
+// description, or 0 if the ticket has neither.
+func DescriptionCommentRatio(ticket jira.JiraIssue) float64 {
+	descWords := calculateNumberOfWords(ticket.Fields.Description)
+	total := descWords
+	for _, comment := range uniqueComments(ticket) {
+		total += calculateNumberOfWords(comment.Body)
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(descWords) / float64(total)
+}
+
+// SilentlyClosed returns the keys of the resolved tickets which have been closed without any comment; their
+// number can be used as a metric for auditing silent closures.
+func SilentlyClosed(tickets ...jira.JiraIssue) []string {
+	var keys []string
+	for _, ticket := range tickets {
+		if isResolved(ticket) && len(ticket.Fields.Comments.Comments) == 0 {
+			keys = append(keys, ticket.Key)
+		}
+	}
+	return keys
+}
+
+// TimeToResolveByFixVersion groups the times-to-close of closed tickets by the name of the versions they were
+// fixed in; a ticket fixed in multiple versions contributes to each of them.
+func TimeToResolveByFixVersion(tickets ...jira.JiraIssue) map[string][]float64 {
+	result := make(map[string][]float64)
+	for _, ticket := range tickets {
+		if ticket.TimeToClose <= 0 {
+			continue
+		}
+		for _, version := range ticket.Fields.FixVersions {
+			result[version.Name] = append(result[version.Name], ticket.TimeToClose)
+		}
+	}
+	return result
+}
+
+// WordinessFields lists the ticket fields whose word counts can be analyzed, including the combined
+// summary and description mode.
+var WordinessFields = []string{"summary", "description", "comment", "summary+description"}
+
+// FieldWordCount returns the number of words inside the given field of a ticket.
+func FieldWordCount(ticket jira.JiraIssue, field string) (int, error) {
+	switch field {
+	case "summary":
+		return SummaryWords(ticket), nil
+	case "description":
+		return DescriptionWords(ticket), nil
+	case "comment":
+		return CommentWords(ticket), nil
+	case "summary+description":
+		return SummaryDescWords(ticket), nil
+	default:
+		return -1, fmt.Errorf("%s is not a valid wordiness field; valid fields are %s",
+			field, strings.Join(WordinessFields, ", "))
+	}
+}
+
+// WordCountHistogram returns, indexed by the lower bound of each bin of binSize words, the number of tickets whose
+// given field (see WordinessFields) holds a word count falling into that bin.
+func WordCountHistogram(field string, binSize int, tickets ...jira.JiraIssue) (map[int]int, error) {
+	if binSize <= 0 {
+		return nil, fmt.Errorf("word count bin size must be positive, got %d", binSize)
+	}
+	if _, err := FieldWordCount(jira.JiraIssue{}, field); err != nil {
+		return nil, err
+	}
+	histogram := make(map[int]int)
+	for _, ticket := range tickets {
+		count, _ := FieldWordCount(ticket, field)
+		histogram[count/binSize*binSize]++
+	}
+	return histogram, nil
+}
+
+// WeightedCommentsComplexity returns an analysis counting the number of words in all comments for a variadic
+// number of tickets, with each comment's count decaying exponentially with its age relative to the ticket's
+// last activity so that a comment halfLife older than the last activity counts half.
+func WeightedCommentsComplexity(halfLife time.Duration) TicketAnalysis {
+	return func(tickets ...jira.JiraIssue) {
+		for i := range tickets {
+			if isTicketHighPriority(tickets[i]) {
+				tickets[i].WeightedCommentWordsCount = weightedCommentWords(tickets[i], halfLife)
+			}
+		}
+	}
+}
+
+// weightedCommentWords returns the sum of the comments' word counts weighted by their recency.
+func weightedCommentWords(ticket jira.JiraIssue, halfLife time.Duration) float64 {
+	last := lastActivity(ticket)
+	var total float64
+	for _, comment := range uniqueComments(ticket) {
+		age := last.Sub(time.Time(comment.Created))
+		if age < 0 {
+			age = 0
+		}
+		weight := 1.0
+		if halfLife > 0 {
+			weight = math.Exp(-math.Ln2 * age.Hours() / halfLife.Hours())
+		}
+		total += weight * float64(calculateNumberOfWords(comment.Body))
+	}
+	return total
+}
+
+// lastActivity returns the time of the latest activity on a ticket, i.e. its creation, last comment
+// creation or update, or last changelog entry.
+func lastActivity(ticket jira.JiraIssue) time.Time {
+	last := time.Time(ticket.Fields.Created)
+	for _, comment := range ticket.Fields.Comments.Comments {
+		for _, t := range []jira.Time{comment.Created, comment.Updated} {
+			if time.Time(t).After(last) {
+				last = time.Time(t)
+			}
+		}
+	}
+	for _, history := range ticket.Changelog.Histories {
+		if time.Time(history.Created).After(last) {
+			last = time.Time(history.Created)
+		}
+	}
+	return last
+}
+
 // Attachments takes a variadic number of tickets and checks if they have attachments and what type they are.
 func Attachments(tickets ...jira.JiraIssue) {
 	for i := range tickets {
@@ -96,15 +655,61 @@ func attachmentType(a jira.Attachment) jira.AttachmentType {
 	}
 }
 
+// inlineImageRegex matches the names Jira generates for images pasted inside a ticket's text
+// (e.g. "image-2023-01-01-10-20-30-123.png", "screenshot-1.png").
+var inlineImageRegex = regexp.MustCompile(`(?i)^(image-\d{4}-\d{2}-\d{2}(-\d+)*|image|screenshot-\d+)\.(png|jpe?g|gif)$`)
+
+// IsInlineImage returns whether an attachment is an image pasted inline rather than a deliberately uploaded file,
+// based on Jira's auto-generated file names and the attachment's mime type.
+func IsInlineImage(a jira.Attachment) bool {
+	if a.MimeType != "" && !strings.HasPrefix(a.MimeType, "image/") {
+		return false
+	}
+	return inlineImageRegex.MatchString(a.Filename)
+}
+
+// InlineImageBreakdown groups the times-to-close of closed tickets under "Inline" if they have inline pasted
+// images and under "Uploaded" if they have deliberately uploaded attachments; a ticket can be part of both.
+func InlineImageBreakdown(tickets ...jira.JiraIssue) map[string][]float64 {
+	result := make(map[string][]float64)
+	for _, ticket := range tickets {
+		if ticket.TimeToClose <= 0 {
+			continue
+		}
+		var inline, uploaded bool
+		for _, a := range ticket.Fields.Attachments {
+			if IsInlineImage(a) {
+				inline = true
+			} else {
+				uploaded = true
+			}
+		}
+		if inline {
+			result["Inline"] = append(result["Inline"], ticket.TimeToClose)
+		}
+		if uploaded {
+			result["Uploaded"] = append(result["Uploaded"], ticket.TimeToClose)
+		}
+	}
+	return result
+}
+
 // fileExtension returns the lowercased extension of a file given that file's name.
 func fileExtension(f string) string {
 	return strings.ToLower(f[(strings.LastIndex(f, ".") + 1):])
 }
 
+const (
+	// stepsToReproduceExpr matches a list of at least 2 bullet points inside a ticket's text.
+	stepsToReproduceExpr = `(\n(\s*)\*(.*)){2,}`
+	// stackTraceExpr matches an exception followed by the lines of its stack trace inside a ticket's text.
+	stackTraceExpr = `^.+Exception[^\n]+\n(\s*at.+\s*\n)+`
+)
+
 // StepsToReproduce returns whether a variadic number of tickets have steps to reproduce or not inside
 // summary, description or any of the comments.
 func StepsToReproduce(tickets ...jira.JiraIssue) {
-	expr := `(\n(\s*)\*(.*)){2,}`
+	expr := stepsToReproduceExpr
 	for i := range tickets {
 		if !isTicketHighPriority(tickets[i]) {
 			continue
@@ -130,7 +735,7 @@ func StepsToReproduce(tickets ...jira.JiraIssue) {
 // StackTraces checks whether a variadic number of tickets have stack traces attached either
 // inside the description or any of the comments.
 func StackTraces(tickets ...jira.JiraIssue) {
-	expr := `^.+Exception[^\n]+\n(\s*at.+\s*\n)+`
+	expr := stackTraceExpr
 	for i := range tickets {
 		if !isTicketHighPriority(tickets[i]) {
 			continue
@@ -158,41 +763,51 @@ func containsRegex(s, expr string) bool {
 	return regex.FindStringIndex(s) != nil
 }
 
-// calculateNumberOfWords returns the number of words in a string.
+// calculateNumberOfWords returns the number of whitespace separated words in a string.
 func calculateNumberOfWords(s string) int {
-	wordCount := 0
-	lines := strings.Split(s, "\n")
-	for _, line := range lines {
-		wordCount += len(strings.Split(strings.TrimSpace(line), " "))
+	return len(strings.Fields(s))
+}
+
+// linkRegex matches http(s) URLs and Jira issue keys (e.g. PROJ-123) referenced inside a ticket's text.
+var linkRegex = regexp.MustCompile(`https?://[^\s\]\[|<>"']+|\b[A-Z][A-Z0-9]+-\d+\b`)
+
+// CountLinks returns the number of distinct http(s) URLs and Jira issue key references found in the
+// description and comments of a ticket; the same link referenced multiple times is only counted once.
+func CountLinks(ticket jira.JiraIssue) int {
+	links := make(map[string]bool)
+	texts := []string{ticket.Fields.Description}
+	for _, comment := range ticket.Fields.Comments.Comments {
+		texts = append(texts, comment.Body)
 	}
-	return wordCount
+	for _, text := range texts {
+		for _, link := range linkRegex.FindAllString(text, -1) {
+			links[strings.TrimRight(link, ".,;:!?)")] = true
+		}
+	}
+	delete(links, ticket.Key)
+	return len(links)
 }
 
-// concatAndRemoveNewLines takes a variadic number of strings and returns a concatenated form with
+// concatAndRemoveNewlines takes a variadic number of strings and returns a concatenated form with
 // all of them having newlines replaced by whitespaces.
-func concatAndRemoveNewlines(strs ...string) (string, error) {
+func concatAndRemoveNewlines(strs ...string) string {
 	var strBuilder strings.Builder
 	for _, str := range strs {
-		str = strings.Replace(str, "\n", " ", -1)
-		_, err := strBuilder.WriteString(str)
-		if err != nil {
-			return "", err
-		}
-		_, err = strBuilder.WriteRune(' ')
-		if err != nil {
-			return "", err
-		}
+		strBuilder.WriteString(strings.Replace(str, "\n", " ", -1))
+		strBuilder.WriteRune(' ')
 	}
-	return strBuilder.String(), nil
+	return strBuilder.String()
 }
 
-// concatComments returns a string containing all the comment bodies concatenated.
+// concatComments returns a string containing all the comment bodies, one per line so that the last word of a
+// comment is not merged with the first word of the next, skipping consecutive duplicates.
 func concatComments(ticket jira.JiraIssue) string {
-	var builder strings.Builder
-	for _, comment := range ticket.Fields.Comments.Comments {
-		builder.WriteString(comment.Body)
+	comments := uniqueComments(ticket)
+	bodies := make([]string, len(comments))
+	for i, comment := range comments {
+		bodies[i] = comment.Body
 	}
-	return builder.String()
+	return strings.Join(bodies, "\n")
 }
 
 // calculateTimeDifference calculates the duration in hours between 2 different timestamps.
@@ -0,0 +1,559 @@
+package analyze
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestFieldWordCount(t *testing.T) {
+	var ticket jira.JiraIssue
+	ticket.Fields.Summary = "app crashes on start"
+	ticket.Fields.Description = "the app crashes right after the splash screen"
+	ticket.Fields.Comments.Comments = []jira.Comment{{Body: "cannot reproduce on my machine"}}
+
+	for field, want := range map[string]int{
+		"summary":             4,
+		"description":         8,
+		"comment":             5,
+		"summary+description": 12,
+	} {
+		got, err := FieldWordCount(ticket, field)
+		if err != nil {
+			t.Errorf("FieldWordCount(%q) returned error: %v", field, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("FieldWordCount(%q) = %d, want %d", field, got, want)
+		}
+	}
+
+	if _, err := FieldWordCount(ticket, "environment"); err == nil {
+		t.Error("expected an error for an unknown wordiness field")
+	}
+}
+
+func TestWordCountHistogram(t *testing.T) {
+	var tickets []jira.JiraIssue
+	for _, words := range []int{3, 7, 12, 0, 5} {
+		var ticket jira.JiraIssue
+		ticket.Fields.Description = strings.TrimSpace(strings.Repeat("word ", words))
+		tickets = append(tickets, ticket)
+	}
+
+	histogram, err := WordCountHistogram("description", 5, tickets...)
+	if err != nil {
+		t.Fatalf("could not compute histogram: %v", err)
+	}
+	if want := map[int]int{0: 2, 5: 2, 10: 1}; !reflect.DeepEqual(histogram, want) {
+		t.Errorf("WordCountHistogram = %v, want %v", histogram, want)
+	}
+
+	if _, err := WordCountHistogram("environment", 5, tickets...); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+	if _, err := WordCountHistogram("description", 0, tickets...); err == nil {
+		t.Error("expected an error for a non-positive bin size")
+	}
+}
+
+func TestTimeToResolveBySprint(t *testing.T) {
+	sprint := func(names ...string) []jira.Sprint {
+		var sprints []jira.Sprint
+		for _, name := range names {
+			sprints = append(sprints, jira.Sprint{Name: name})
+		}
+		return sprints
+	}
+	tickets := []jira.JiraIssue{
+		{Key: "S-1", TimeToClose: 10, Fields: jira.Fields{Sprints: sprint("Sprint 1")}},
+		{Key: "S-2", TimeToClose: 20, Fields: jira.Fields{Sprints: sprint("Sprint 1", "Sprint 2")}},
+		{Key: "S-3", TimeToClose: -1, Fields: jira.Fields{Sprints: sprint("Sprint 2")}},
+		{Key: "S-4", TimeToClose: 5},
+	}
+	got := TimeToResolveBySprint(tickets...)
+	if len(got) != 2 || len(got["Sprint 1"]) != 2 || len(got["Sprint 2"]) != 1 || got["Sprint 2"][0] != 20 {
+		t.Errorf("TimeToResolveBySprint = %v", got)
+	}
+}
+
+func TestExcludeLowSignal(t *testing.T) {
+	empty := jira.JiraIssue{Key: "L-1"}
+	short := jira.JiraIssue{Key: "L-2", Fields: jira.Fields{Summary: "broken"}}
+	full := jira.JiraIssue{Key: "L-3", Fields: jira.Fields{Summary: "login broken", Description: "see logs"}}
+	full.Fields.Comments.Comments = []jira.Comment{{Body: "fixed in master"}}
+
+	if !IsLowSignal(empty, 1) {
+		t.Error("ticket without any text is not low signal")
+	}
+	if IsLowSignal(full, 7) {
+		t.Error("ticket with 7 words across its fields and comments is low signal for a minimum of 7")
+	}
+	if !IsLowSignal(full, 8) {
+		t.Error("ticket with 7 words is not low signal for a minimum of 8")
+	}
+
+	// words at the boundary of two comments count as two, whether the word counts are stored or not.
+	chatty := jira.JiraIssue{Key: "L-4", Fields: jira.Fields{Summary: "broken"}}
+	chatty.Fields.Comments.Comments = []jira.Comment{{ID: "1", Body: "same here"}, {ID: "2", Body: "me too"}}
+	stored := []jira.JiraIssue{chatty}
+	WordCounts(stored...)
+	for _, ticket := range []jira.JiraIssue{chatty, stored[0]} {
+		if IsLowSignal(ticket, 5) {
+			t.Errorf("ticket with 5 words across two comments is low signal for a minimum of 5 (stored counts: %v)",
+				ticket.HasWordCounts)
+		}
+	}
+
+	kept := ExcludeLowSignal(3, empty, short, full)
+	if len(kept) != 1 || kept[0].Key != "L-3" {
+		t.Errorf("ExcludeLowSignal kept %v, want only L-3", kept)
+	}
+}
+
+// withAssigneeChanges returns the ticket with a changelog history per assignee change.
+func withAssigneeChanges(ticket jira.JiraIssue, changes int) jira.JiraIssue {
+	for i := 0; i < changes; i++ {
+		ticket.Changelog.Histories = append(ticket.Changelog.Histories, jira.ChangelogHistory{
+			Items: []jira.ChangelogHistoryItem{{Field: "assignee"}, {Field: "status"}},
+		})
+	}
+	return ticket
+}
+
+func TestReassignmentCount(t *testing.T) {
+	ticket := withAssigneeChanges(jira.JiraIssue{}, 3)
+	ticket.Changelog.Histories = append(ticket.Changelog.Histories, jira.ChangelogHistory{
+		Items: []jira.ChangelogHistoryItem{{Field: "priority"}},
+	})
+	if got := ReassignmentCount(ticket); got != 3 {
+		t.Errorf("ReassignmentCount = %d, want 3", got)
+	}
+	if got := ReassignmentCount(jira.JiraIssue{}); got != 0 {
+		t.Errorf("ReassignmentCount of a ticket without changelog = %d, want 0", got)
+	}
+}
+
+// at returns the Jira time of the given day of March 2018, plus the given number of hours, in UTC.
+func at(day int, hours float64) jira.Time {
+	return jira.Time(time.Date(2018, 3, day, 0, 0, 0, 0, time.UTC).Add(time.Duration(hours * float64(time.Hour))))
+}
+
+func TestWeightedCommentsComplexity(t *testing.T) {
+	ticket := jira.JiraIssue{Key: "W-1"}
+	ticket.Fields.Priority.ID = "1"
+	ticket.Fields.Created = at(1, 0)
+	ticket.Fields.Comments.Comments = []jira.Comment{
+		{ID: "1", Body: "four words in here", Created: at(1, 0)},
+		{ID: "2", Body: "two words", Created: at(11, 0)},
+	}
+	low := ticket
+	low.Key, low.Fields.Priority.ID = "W-2", "5"
+	tickets := []jira.JiraIssue{ticket, low}
+
+	// the first comment is 10 days older than the last activity, i.e. a half-life, so its words count half.
+	WeightedCommentsComplexity(10 * 24 * time.Hour)(tickets...)
+	if got := tickets[0].WeightedCommentWordsCount; math.Abs(got-4) > 1e-9 {
+		t.Errorf("weighted comment words = %v, want 4", got)
+	}
+	if tickets[1].WeightedCommentWordsCount != 0 {
+		t.Errorf("low priority ticket was analyzed")
+	}
+
+	WeightedCommentsComplexity(0)(tickets...)
+	if got := tickets[0].WeightedCommentWordsCount; got != 6 {
+		t.Errorf("weighted comment words without half-life = %v, want the plain count 6", got)
+	}
+}
+
+// transition describes a status change happening the given number of hours after March 1st 2018.
+type transition struct {
+	hours    float64
+	from, to string
+}
+
+// ticketWith returns a high priority ticket created on March 1st 2018, currently in the given status and holding
+// a changelog history per transition.
+func ticketWith(key, status string, transitions ...transition) jira.JiraIssue {
+	ticket := jira.JiraIssue{Key: key}
+	ticket.Fields.Priority.ID = "1"
+	ticket.Fields.Created = at(1, 0)
+	ticket.Fields.Status.Name = status
+	for _, tr := range transitions {
+		ticket.Changelog.Histories = append(ticket.Changelog.Histories, jira.ChangelogHistory{
+			Created: at(1, tr.hours),
+			Items:   []jira.ChangelogHistoryItem{{Field: "status", FromString: tr.from, ToString: tr.to}},
+		})
+	}
+	return ticket
+}
+
+func TestTimesToCloseByStatusCategory(t *testing.T) {
+	// a custom terminal status is only recognized through the done status category.
+	shipped := ticketWith("C-1", "Shipped", transition{5, "Open", "In Progress"}, transition{12, "In Progress", "Shipped"})
+	shipped.Fields.Status.StatusCategory.Key = jira.DoneStatusCategory
+	// a well known terminal name outside the done category does not make a ticket resolved.
+	notDone := ticketWith("C-2", "Done", transition{3, "Open", "Done"})
+	notDone.Fields.Status.StatusCategory.Key = "indeterminate"
+	// without a status category, the well known terminal names are used.
+	closed := ticketWith("C-3", "Closed", transition{8, "Open", "Closed"})
+	open := ticketWith("C-4", "Open")
+
+	tickets := []jira.JiraIssue{shipped, notDone, closed, open}
+	TimesToClose(tickets...)
+	for i, want := range []float64{12, 0, 8, 0} {
+		if got := tickets[i].TimeToClose; got != want {
+			t.Errorf("time-to-close of %s = %v, want %v", tickets[i].Key, got, want)
+		}
+	}
+}
+
+func TestUseWorkflowStatuses(t *testing.T) {
+	previous := terminalStatuses
+	defer func() {
+		terminalStatuses = previous
+		workflowStatuses = false
+	}()
+	UseWorkflowStatuses([]jira.Status{
+		{Name: "Open", StatusCategory: jira.StatusCategory{Key: "new"}},
+		{Name: "Shipped", StatusCategory: jira.StatusCategory{Key: jira.DoneStatusCategory}},
+	})
+
+	// only the statuses of the done category are terminal, whatever their name.
+	shipped := ticketWith("W-1", "Shipped", transition{6, "Open", "Shipped"})
+	closed := ticketWith("W-2", "Closed", transition{6, "Open", "Closed"})
+	if !isResolved(shipped) || isResolved(closed) {
+		t.Errorf("resolved shipped and closed tickets = %v and %v, want only the shipped one", isResolved(shipped),
+			isResolved(closed))
+	}
+}
+
+func TestDescriptionCommentRatio(t *testing.T) {
+	descriptionHeavy := jira.JiraIssue{Key: "R-1"}
+	descriptionHeavy.Fields.Description = "the export fails with a timeout on large projects"
+	descriptionHeavy.Fields.Comments.Comments = []jira.Comment{{ID: "1", Body: "confirmed"}}
+	commentHeavy := jira.JiraIssue{Key: "R-2"}
+	commentHeavy.Fields.Description = "broken"
+	commentHeavy.Fields.Comments.Comments = []jira.Comment{
+		{ID: "1", Body: "what exactly is broken here"},
+		{ID: "2", Body: "the export times out on large projects"},
+	}
+
+	for _, tc := range []struct {
+		ticket jira.JiraIssue
+		want   float64
+	}{
+		{descriptionHeavy, 0.9},
+		{commentHeavy, 1.0 / 13},
+		{jira.JiraIssue{Key: "R-3"}, 0},
+	} {
+		if got := DescriptionCommentRatio(tc.ticket); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("DescriptionCommentRatio(%s) = %v, want %v", tc.ticket.Key, got, tc.want)
+		}
+	}
+}
+
+func TestIsInlineImage(t *testing.T) {
+	for _, tc := range []struct {
+		attachment jira.Attachment
+		want       bool
+	}{
+		{jira.Attachment{Filename: "image-2023-01-01-10-15-42-123.png", MimeType: "image/png"}, true},
+		{jira.Attachment{Filename: "image-2018-03-01.jpg"}, true},
+		{jira.Attachment{Filename: "screenshot-1.png", MimeType: "image/png"}, true},
+		{jira.Attachment{Filename: "login-error.png", MimeType: "image/png"}, false},
+		{jira.Attachment{Filename: "image-2023-01-01-10-15-42-123.png", MimeType: "application/zip"}, false},
+		{jira.Attachment{Filename: "server.log", MimeType: "text/plain"}, false},
+	} {
+		if got := IsInlineImage(tc.attachment); got != tc.want {
+			t.Errorf("IsInlineImage(%s, %s) = %v, want %v", tc.attachment.Filename, tc.attachment.MimeType, got, tc.want)
+		}
+	}
+
+	pasted := jira.JiraIssue{Key: "I-1", TimeToClose: 10}
+	pasted.Fields.Attachments = []jira.Attachment{{Filename: "image-2023-01-01-10-15-42-123.png"}}
+	both := jira.JiraIssue{Key: "I-2", TimeToClose: 20}
+	both.Fields.Attachments = []jira.Attachment{{Filename: "image.png"}, {Filename: "trace.txt"}}
+	open := jira.JiraIssue{Key: "I-3"}
+	open.Fields.Attachments = []jira.Attachment{{Filename: "trace.txt"}}
+	breakdown := InlineImageBreakdown(pasted, both, open)
+	if want := map[string][]float64{"Inline": {10, 20}, "Uploaded": {20}}; !reflect.DeepEqual(breakdown, want) {
+		t.Errorf("InlineImageBreakdown = %v, want %v", breakdown, want)
+	}
+}
+
+func TestTimesToCloseWithExcludedStatuses(t *testing.T) {
+	ticket := ticketWith("X-1", "Closed",
+		transition{2, "Open", "In Progress"},
+		transition{5, "In Progress", "Waiting for Customer"},
+		transition{15, "Waiting for Customer", "In Progress"},
+		transition{20, "In Progress", "Closed"},
+	)
+	want := map[string]float64{"Open": 2, "In Progress": 8, "Waiting for Customer": 10}
+	if got := TimeInStatus(ticket, time.Time(at(1, 20))); !reflect.DeepEqual(got, want) {
+		t.Errorf("TimeInStatus = %v, want %v", got, want)
+	}
+
+	tickets := []jira.JiraIssue{ticket}
+	TimesToClose(tickets...)
+	if tickets[0].TimeToClose != 20 {
+		t.Errorf("time-to-close = %v, want 20", tickets[0].TimeToClose)
+	}
+	TimesToCloseWith(ResolutionOptions{ExcludedStatuses: []string{"Waiting for Customer"}})(tickets...)
+	if tickets[0].TimeToClose != 10 {
+		t.Errorf("time-to-close without the time waiting for the customer = %v, want 10", tickets[0].TimeToClose)
+	}
+}
+
+func TestTimesToCloseAtLastTransition(t *testing.T) {
+	reopened := ticketWith("L-1", "Closed",
+		transition{4, "Open", "Closed"},
+		transition{10, "Closed", "Reopened"},
+		transition{16, "Reopened", "Closed"},
+	)
+	closedOnce := ticketWith("L-2", "Closed", transition{7, "Open", "Closed"})
+
+	tickets := []jira.JiraIssue{reopened, closedOnce}
+	TimesToCloseWith(ResolutionOptions{})(tickets...)
+	if tickets[0].TimeToClose != 4 || tickets[1].TimeToClose != 7 {
+		t.Errorf("times-to-close at the first transition = %v and %v, want 4 and 7", tickets[0].TimeToClose,
+			tickets[1].TimeToClose)
+	}
+	TimesToCloseWith(ResolutionOptions{LastTransition: true})(tickets...)
+	if tickets[0].TimeToClose != 16 || tickets[1].TimeToClose != 7 {
+		t.Errorf("times-to-close at the last transition = %v and %v, want 16 and 7", tickets[0].TimeToClose,
+			tickets[1].TimeToClose)
+	}
+}
+
+func TestExcludeDuplicateResolutions(t *testing.T) {
+	fixed := ticketWith("D-1", "Closed", transition{10, "Open", "Closed"})
+	fixed.Fields.Resolution.Name = "Fixed"
+	duplicate := ticketWith("D-2", "Closed", transition{2, "Open", "Closed"})
+	duplicate.Fields.Resolution.Name = "duplicate"
+	open := ticketWith("D-3", "Open")
+
+	kept, excluded := ExcludeResolutions(DuplicateResolutions, fixed, duplicate, open)
+	if excluded != 1 || len(kept) != 2 || kept[0].Key != "D-1" || kept[1].Key != "D-3" {
+		t.Errorf("ExcludeResolutions kept %v and excluded %d, want D-1, D-3 and 1", kept, excluded)
+	}
+	// a trailing comma of the flag does not exclude the tickets without a resolution.
+	kept, excluded = ExcludeResolutions(strings.Split("Duplicate, ", ","), fixed, duplicate, open)
+	if excluded != 1 || len(kept) != 2 || kept[1].Key != "D-3" {
+		t.Errorf("ExcludeResolutions with a blank resolution kept %v and excluded %d, want D-1, D-3 and 1", kept,
+			excluded)
+	}
+
+	tickets := []jira.JiraIssue{fixed, duplicate}
+	TimesToCloseWith(ResolutionOptions{ExcludedResolutions: DuplicateResolutions})(tickets...)
+	if tickets[0].TimeToClose != 10 || tickets[1].TimeToClose != 0 {
+		t.Errorf("times-to-close = %v and %v, want 10 and none for the duplicate", tickets[0].TimeToClose,
+			tickets[1].TimeToClose)
+	}
+}
+
+func TestExcludeProjects(t *testing.T) {
+	tickets := []jira.JiraIssue{{Key: "APP-1"}, {Key: "test-2"}, {Key: "INTERNAL-3"}, {Key: "TESTING-4"}}
+	kept, excluded := ExcludeProjects([]string{" TEST- ", "INT", ""}, tickets...)
+	if excluded != 2 || len(kept) != 2 || kept[0].Key != "APP-1" || kept[1].Key != "TESTING-4" {
+		t.Errorf("ExcludeProjects kept %v and excluded %d, want APP-1, TESTING-4 and 2", kept, excluded)
+	}
+	if kept, excluded := ExcludeProjects(nil, tickets...); excluded != 0 || len(kept) != len(tickets) {
+		t.Errorf("ExcludeProjects without prefixes kept %d and excluded %d tickets, want all kept", len(kept),
+			excluded)
+	}
+}
+
+func TestSilentlyClosed(t *testing.T) {
+	silent := ticketWith("S-1", "Closed", transition{4, "Open", "Closed"})
+	discussed := ticketWith("S-2", "Closed", transition{4, "Open", "Closed"}, transition{6, "Closed", "Reopened"},
+		transition{9, "Reopened", "Closed"})
+	discussed.Fields.Comments.Comments = []jira.Comment{{ID: "1", Body: "reopening, still failing"}}
+	open := ticketWith("S-3", "Open")
+
+	if got := SilentlyClosed(silent, discussed, open); !reflect.DeepEqual(got, []string{"S-1"}) {
+		t.Errorf("SilentlyClosed = %v, want [S-1]", got)
+	}
+}
+
+func TestSummaryAndDescriptionComplexity(t *testing.T) {
+	ticket := jira.JiraIssue{Key: "F-1"}
+	ticket.Fields.Priority.ID = "2"
+	ticket.Fields.Summary = "app crashes on start"
+	ticket.Fields.Description = "the app crashes right after the splash screen"
+	tickets := []jira.JiraIssue{ticket}
+
+	SummaryComplexity(tickets...)
+	DescriptionComplexity(tickets...)
+	FieldsComplexity(tickets...)
+	if got := tickets[0]; got.SummaryWordsCount != 4 || got.DescriptionWordsCount != 8 ||
+		got.SummaryDescWordsCount != 12 {
+		t.Errorf("summary, description and combined word counts = %d, %d, %d; want 4, 8, 12",
+			got.SummaryWordsCount, got.DescriptionWordsCount, got.SummaryDescWordsCount)
+	}
+
+	// the counts stored by WordCounts are reused rather than recounted from the text.
+	WordCounts(tickets...)
+	tickets[0].Fields.Description = "broken"
+	tickets[0].Fields.Comments.Comments = []jira.Comment{{ID: "1", Body: "same here"}}
+	SummaryComplexity(tickets...)
+	DescriptionComplexity(tickets...)
+	FieldsComplexity(tickets...)
+	CommentsComplexity(tickets...)
+	if got := tickets[0]; got.DescriptionWordsCount != 8 || got.SummaryDescWordsCount != 12 ||
+		got.CommentWordsCount != 0 {
+		t.Errorf("description, combined and comment word counts = %d, %d, %d; want the stored 8, 12, 0",
+			got.DescriptionWordsCount, got.SummaryDescWordsCount, got.CommentWordsCount)
+	}
+}
+
+func TestTimeToResolveByFixVersion(t *testing.T) {
+	both := jira.JiraIssue{Key: "V-1", TimeToClose: 10}
+	both.Fields.FixVersions = []jira.Version{{Name: "2.0"}, {Name: "2.1"}}
+	single := jira.JiraIssue{Key: "V-2", TimeToClose: 30}
+	single.Fields.FixVersions = []jira.Version{{Name: "2.1"}}
+	open := jira.JiraIssue{Key: "V-3"}
+	open.Fields.FixVersions = []jira.Version{{Name: "2.0"}}
+
+	want := map[string][]float64{"2.0": {10}, "2.1": {10, 30}}
+	if got := TimeToResolveByFixVersion(both, single, open); !reflect.DeepEqual(got, want) {
+		t.Errorf("TimeToResolveByFixVersion = %v, want %v", got, want)
+	}
+}
+
+func TestCountLinks(t *testing.T) {
+	ticket := jira.JiraIssue{Key: "LOG-1"}
+	ticket.Fields.Description = "See https://docs.example.com/setup and https://docs.example.com/setup. Related to PROJ-12."
+	ticket.Fields.Comments.Comments = []jira.Comment{
+		{ID: "1", Body: "Duplicate of PROJ-12, see also http://status.example.com"},
+		{ID: "2", Body: "Moved from LOG-1 to PROJ-13"},
+	}
+	// the docs URL and PROJ-12 are only counted once and the ticket's own key is not counted.
+	if got := CountLinks(ticket); got != 4 {
+		t.Errorf("CountLinks = %d, want 4", got)
+	}
+	if got := CountLinks(jira.JiraIssue{Key: "LOG-2"}); got != 0 {
+		t.Errorf("CountLinks of a ticket without text = %d, want 0", got)
+	}
+}
+
+func TestWordCountsStoredAndRefreshed(t *testing.T) {
+	ticket := jira.JiraIssue{Key: "WC-1"}
+	ticket.Fields.Summary = "login fails"
+	ticket.Fields.Description = "the login page returns an error"
+	tickets := []jira.JiraIssue{ticket}
+
+	if got := SummaryDescWords(tickets[0]); got != 8 {
+		t.Errorf("SummaryDescWords computed on demand = %d, want 8", got)
+	}
+	WordCounts(tickets...)
+	if !tickets[0].HasWordCounts || tickets[0].SummaryDescWordsCount != 8 {
+		t.Fatalf("stored word counts = %+v", tickets[0])
+	}
+
+	// the stored counts are read as long as they are not recomputed, even once the text changed.
+	tickets[0].Fields.Description = "broken"
+	tickets[0].Fields.Comments.Comments = []jira.Comment{{ID: "1", Body: "same here"}}
+	if got, comments := SummaryDescWords(tickets[0]), CommentWords(tickets[0]); got != 8 || comments != 0 {
+		t.Errorf("stored summary, description and comment words = %d, %d; want 8, 0", got, comments)
+	}
+	WordCounts(tickets...)
+	if got, comments := SummaryDescWords(tickets[0]), CommentWords(tickets[0]); got != 3 || comments != 2 {
+		t.Errorf("refreshed summary, description and comment words = %d, %d; want 3, 2", got, comments)
+	}
+}
+
+func TestTimestampAnomalies(t *testing.T) {
+	backwards := ticketWith("A-1", "Closed", transition{10, "Open", "Closed"})
+	backwards.Fields.Created = at(5, 0)
+	future := ticketWith("A-2", "Open")
+	future.Fields.Created = jira.Time(time.Now().Add(48 * time.Hour))
+	valid := ticketWith("A-3", "Closed", transition{10, "Open", "Closed"})
+
+	tickets := []jira.JiraIssue{backwards, future, valid}
+	TimesToClose(tickets...)
+	for i, want := range []float64{0, 0, 10} {
+		if got := tickets[i].TimeToClose; got != want {
+			t.Errorf("time-to-close of %s = %v, want %v", tickets[i].Key, got, want)
+		}
+	}
+	want := map[string]error{"A-1": ErrResolvedBeforeCreated, "A-2": ErrCreatedInFuture}
+	if got := TimestampAnomalies(tickets...); !reflect.DeepEqual(got, want) {
+		t.Errorf("TimestampAnomalies = %v, want %v", got, want)
+	}
+}
+
+// wordyTickets returns n tickets whose summaries, descriptions and comments hold a varying number of words.
+func wordyTickets(n int) []jira.JiraIssue {
+	tickets := make([]jira.JiraIssue, n)
+	for i := range tickets {
+		tickets[i].Key = fmt.Sprintf("P-%d", i)
+		tickets[i].Fields.Summary = strings.Repeat("save fails ", i%7+1)
+		tickets[i].Fields.Description = strings.Repeat("the editor crashes when saving `doc.txt` ", i%13)
+		tickets[i].Fields.Comments.Comments = []jira.Comment{{Body: strings.Repeat("same here ", i%5)}}
+	}
+	return tickets
+}
+
+func TestResolveInParallel(t *testing.T) {
+	var tickets []jira.JiraIssue
+	for i := 1; i <= 7; i++ {
+		tickets = append(tickets, ticketWith(fmt.Sprintf("P-%d", i), "Closed", transition{float64(i), "Open", "Closed"}))
+	}
+	tickets = append(tickets, ticketWith("P-8", "Open"))
+
+	want := ResolveTimesToClose(ResolutionOptions{}, tickets...)
+	for _, workers := range []int{1, 3, 8} {
+		if got := resolveInParallel(workers, ResolutionOptions{}, tickets...); got != want {
+			t.Errorf("resolveInParallel with %d workers = %+v, want %+v", workers, got, want)
+		}
+	}
+	if want.Resolved != 7 {
+		t.Errorf("resolved tickets = %d, want 7", want.Resolved)
+	}
+}
+
+func TestParallel(t *testing.T) {
+	sequential := wordyTickets(103)
+	WordCounts(sequential...)
+	for _, workers := range []int{0, 1, 4, 200} {
+		parallel := wordyTickets(103)
+		Parallel(workers, WordCounts)(parallel...)
+		if !reflect.DeepEqual(parallel, sequential) {
+			t.Errorf("word counts computed by %d workers differ from the sequential ones", workers)
+		}
+	}
+
+	var partitions []int
+	var mu sync.Mutex
+	Parallel(4, func(tickets ...jira.JiraIssue) {
+		mu.Lock()
+		partitions = append(partitions, len(tickets))
+		mu.Unlock()
+	})(wordyTickets(10)...)
+	sort.Ints(partitions)
+	if want := []int{1, 3, 3, 3}; !reflect.DeepEqual(partitions, want) {
+		t.Errorf("partition sizes = %v, want %v", partitions, want)
+	}
+}
+
+func BenchmarkWordCounts(b *testing.B) {
+	for _, workers := range []int{1, runtime.GOMAXPROCS(0)} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			tickets := wordyTickets(2000)
+			wordCounts := Parallel(workers, WordCounts)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				wordCounts(tickets...)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package analyze
+
+import (
+	"math"
+	"time"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// AttachmentTiming returns, for each attachment of a ticket, the number of hours between the creation of the
+// ticket and the upload of the attachment.
+func AttachmentTiming(ticket jira.JiraIssue) []float64 {
+	hours := make([]float64, len(ticket.Fields.Attachments))
+	for i, a := range ticket.Fields.Attachments {
+		hours[i] = calculateTimeDifference(a.Created, ticket.Fields.Created)
+	}
+	return hours
+}
+
+// LateAttachmentFraction returns the fraction of a ticket's attachments uploaded after the calendar day, in the
+// ticket's time zone, the ticket was created on, or 0 if the ticket has no attachments.
+func LateAttachmentFraction(ticket jira.JiraIssue) float64 {
+	if len(ticket.Fields.Attachments) == 0 {
+		return 0
+	}
+	created := time.Time(ticket.Fields.Created)
+	year, month, day := created.Date()
+	nextDay := time.Date(year, month, day+1, 0, 0, 0, 0, created.Location())
+	var late int
+	for _, a := range ticket.Fields.Attachments {
+		if !time.Time(a.Created).Before(nextDay) {
+			late++
+		}
+	}
+	return float64(late) / float64(len(ticket.Fields.Attachments))
+}
+
+// TimeToCloseByAttachmentType returns the times-to-close of closed tickets grouped by the types of their
+// attachments, counting each ticket once per type no matter how many attachments of that type it has.
+// Attachments whose type was not set by the attachments analysis are skipped.
+func TimeToCloseByAttachmentType(tickets ...jira.JiraIssue) map[jira.AttachmentType][]float64 {
+	result := make(map[jira.AttachmentType][]float64)
+	for _, t := range tickets {
+		if t.TimeToClose <= 0 || t.TimeToClose > jira.MaxTimeToCloseH {
+			continue
+		}
+		types := make(map[jira.AttachmentType]bool)
+		for _, a := range t.Fields.Attachments {
+			if a.Type != 0 && !types[a.Type] {
+				types[a.Type] = true
+				result[a.Type] = append(result[a.Type], t.TimeToClose)
+			}
+		}
+	}
+	return result
+}
+
+// MinEffectSamples defines the minimum number of closed tickets both with and without attachments an issue type
+// needs for AttachmentEffectByType to compute its effect.
+const MinEffectSamples = 2
+
+// EffectStats holds the mean times-to-close of the tickets with and without a property and how they differ.
+type EffectStats struct {
+	With        int
+	Without     int
+	WithMean    float64
+	WithoutMean float64
+	// Difference holds the number of hours by which the mean with the property exceeds the mean without it.
+	Difference float64
+	// PctDifference holds the difference as a percentage of the mean without the property, or NaN if that is 0.
+	PctDifference float64
+}
+
+// AttachmentEffectByType returns, indexed by issue type, how the mean time-to-close of closed tickets with
+// attachments differs from the one of tickets without any. Issue types lacking MinEffectSamples tickets in
+// either group are skipped.
+func AttachmentEffectByType(tickets ...jira.JiraIssue) map[string]EffectStats {
+	with := make(map[string][]float64)
+	without := make(map[string][]float64)
+	for _, t := range tickets {
+		if t.TimeToClose <= 0 || t.TimeToClose > jira.MaxTimeToCloseH {
+			continue
+		}
+		issueType := t.Fields.Type.Name
+		if len(t.Fields.Attachments) > 0 {
+			with[issueType] = append(with[issueType], t.TimeToClose)
+		} else {
+			without[issueType] = append(without[issueType], t.TimeToClose)
+		}
+	}
+	effects := make(map[string]EffectStats)
+	for issueType, withTimes := range with {
+		withoutTimes := without[issueType]
+		if len(withTimes) < MinEffectSamples || len(withoutTimes) < MinEffectSamples {
+			continue
+		}
+		effect := EffectStats{
+			With:        len(withTimes),
+			Without:     len(withoutTimes),
+			WithMean:    newStats(withTimes).Mean,
+			WithoutMean: newStats(withoutTimes).Mean,
+		}
+		effect.Difference = effect.WithMean - effect.WithoutMean
+		effect.PctDifference = math.NaN()
+		if effect.WithoutMean != 0 {
+			effect.PctDifference = effect.Difference / effect.WithoutMean * 100
+		}
+		effects[issueType] = effect
+	}
+	return effects
+}
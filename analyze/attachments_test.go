@@ -0,0 +1,85 @@
+package analyze
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestAttachmentTiming(t *testing.T) {
+	ticket := jira.JiraIssue{Key: "A-1"}
+	ticket.Fields.Created = at(1, 10)
+	ticket.Fields.Attachments = []jira.Attachment{
+		{Created: at(1, 10)},
+		{Created: at(1, 23)},
+		{Created: at(4, 10)},
+	}
+	if got, want := AttachmentTiming(ticket), []float64{0, 13, 72}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AttachmentTiming = %v, want %v", got, want)
+	}
+	// only the attachment uploaded days later falls after the creation day.
+	if got, want := LateAttachmentFraction(ticket), 1.0/3; got != want {
+		t.Errorf("LateAttachmentFraction = %v, want %v", got, want)
+	}
+
+	withoutAttachments := jira.JiraIssue{Key: "A-2"}
+	withoutAttachments.Fields.Created = at(1, 10)
+	if got := AttachmentTiming(withoutAttachments); len(got) != 0 {
+		t.Errorf("AttachmentTiming without attachments = %v, want none", got)
+	}
+	if got := LateAttachmentFraction(withoutAttachments); got != 0 {
+		t.Errorf("LateAttachmentFraction without attachments = %v, want 0", got)
+	}
+}
+
+func TestAttachmentEffectByType(t *testing.T) {
+	closed := func(issueType string, hours float64, attached bool) jira.JiraIssue {
+		ticket := jira.JiraIssue{TimeToClose: hours}
+		ticket.Fields.Type.Name = issueType
+		if attached {
+			ticket.Fields.Attachments = []jira.Attachment{{Filename: "screenshot.png"}}
+		}
+		return ticket
+	}
+	effects := AttachmentEffectByType(
+		closed("Bug", 10, true), closed("Bug", 20, true), closed("Bug", 30, false), closed("Bug", 50, false),
+		closed("Task", 8, true), closed("Task", 12, true), closed("Task", 4, false), closed("Task", 6, false),
+		// a single story with attachments is not enough to compute its effect, and open tickets are left out.
+		closed("Story", 5, true), closed("Story", 7, false), closed("Story", 9, false), closed("Story", 0, true),
+	)
+	want := map[string]EffectStats{
+		"Bug":  {With: 2, Without: 2, WithMean: 15, WithoutMean: 40, Difference: -25, PctDifference: -62.5},
+		"Task": {With: 2, Without: 2, WithMean: 10, WithoutMean: 5, Difference: 5, PctDifference: 100},
+	}
+	if !reflect.DeepEqual(effects, want) {
+		t.Errorf("AttachmentEffectByType = %+v, want %+v", effects, want)
+	}
+}
+
+func TestTimeToCloseByAttachmentType(t *testing.T) {
+	closed := func(hours float64, types ...jira.AttachmentType) jira.JiraIssue {
+		ticket := jira.JiraIssue{TimeToClose: hours}
+		for _, typ := range types {
+			ticket.Fields.Attachments = append(ticket.Fields.Attachments, jira.Attachment{Type: typ})
+		}
+		return ticket
+	}
+	got := TimeToCloseByAttachmentType(
+		// a ticket counts once per type, however many attachments of that type it holds.
+		closed(10, jira.ImageAttachment, jira.ImageAttachment, jira.CodeAttachment),
+		closed(20, jira.ImageAttachment),
+		closed(30, jira.VideoAttachment),
+		// open tickets and attachments of unknown type are left out.
+		closed(0, jira.ArchiveAttachment),
+		closed(40, jira.AttachmentType(0)),
+	)
+	want := map[jira.AttachmentType][]float64{
+		jira.ImageAttachment: {10, 20},
+		jira.CodeAttachment:  {10},
+		jira.VideoAttachment: {30},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TimeToCloseByAttachmentType = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,152 @@
+package analyze
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// holidayLayout defines the layout holidays are written in inside a work calendar file.
+const holidayLayout = "2006-01-02"
+
+// WorkCalendar defines the working days and hours resolution times are counted in when using business hours.
+type WorkCalendar struct {
+	Days      map[time.Weekday]bool
+	StartHour int
+	EndHour   int
+	// Holidays holds the non-working dates, formatted with holidayLayout.
+	Holidays map[string]bool
+	Location *time.Location
+}
+
+// DefaultWorkCalendar defines a calendar working from 9 to 17 UTC, Monday to Friday, without holidays.
+var DefaultWorkCalendar = WorkCalendar{
+	Days: map[time.Weekday]bool{
+		time.Monday: true, time.Tuesday: true, time.Wednesday: true, time.Thursday: true, time.Friday: true,
+	},
+	StartHour: 9,
+	EndHour:   17,
+	Holidays:  map[string]bool{},
+	Location:  time.UTC,
+}
+
+// LoadWorkCalendar reads a work calendar from a JSON file, e.g. {"days": ["Monday", "Tuesday"], "start_hour": 9,
+// "end_hour": 17, "holidays": ["2019-12-25"], "timezone": "Europe/London"}. Omitted settings keep the values of
+// DefaultWorkCalendar.
+func LoadWorkCalendar(path string) (WorkCalendar, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return WorkCalendar{}, fmt.Errorf("could not read work calendar file: %v", err)
+	}
+	var raw struct {
+		Days      []string `json:"days"`
+		StartHour *int     `json:"start_hour"`
+		EndHour   *int     `json:"end_hour"`
+		Holidays  []string `json:"holidays"`
+		Timezone  string   `json:"timezone"`
+	}
+	if err := json.Unmarshal(content, &raw); err != nil {
+		return WorkCalendar{}, fmt.Errorf("could not parse work calendar file %s: %v", path, err)
+	}
+	cal := DefaultWorkCalendar
+	cal.Holidays = make(map[string]bool)
+	if len(raw.Days) > 0 {
+		cal.Days = make(map[time.Weekday]bool)
+		for _, name := range raw.Days {
+			day, ok := weekdays[strings.ToLower(name)]
+			if !ok {
+				return WorkCalendar{}, fmt.Errorf("%s is not a valid day of the week", name)
+			}
+			cal.Days[day] = true
+		}
+	}
+	if raw.StartHour != nil {
+		cal.StartHour = *raw.StartHour
+	}
+	if raw.EndHour != nil {
+		cal.EndHour = *raw.EndHour
+	}
+	if cal.StartHour < 0 || cal.EndHour > 24 || cal.StartHour >= cal.EndHour {
+		return WorkCalendar{}, fmt.Errorf("invalid working hours %d-%d", cal.StartHour, cal.EndHour)
+	}
+	for _, holiday := range raw.Holidays {
+		date, err := time.Parse(holidayLayout, holiday)
+		if err != nil {
+			return WorkCalendar{}, fmt.Errorf("invalid holiday %s: %v", holiday, err)
+		}
+		cal.Holidays[date.Format(holidayLayout)] = true
+	}
+	if raw.Timezone != "" {
+		if cal.Location, err = time.LoadLocation(raw.Timezone); err != nil {
+			return WorkCalendar{}, fmt.Errorf("invalid timezone %s: %v", raw.Timezone, err)
+		}
+	}
+	return cal, nil
+}
+
+// weekdays maps lowercase day names to days of the week.
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// BusinessHoursBetween returns the number of working hours of the calendar between start and end, or 0 if end
+// does not come after start.
+func BusinessHoursBetween(start, end time.Time, cal WorkCalendar) float64 {
+	if !end.After(start) {
+		return 0
+	}
+	loc := cal.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	start, end = start.In(loc), end.In(loc)
+	var hours float64
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	for day := first; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if !cal.Days[day.Weekday()] || cal.Holidays[day.Format(holidayLayout)] {
+			continue
+		}
+		from := day.Add(time.Duration(cal.StartHour) * time.Hour)
+		to := day.Add(time.Duration(cal.EndHour) * time.Hour)
+		if start.After(from) {
+			from = start
+		}
+		if end.Before(to) {
+			to = end
+		}
+		if to.After(from) {
+			hours += to.Sub(from).Hours()
+		}
+	}
+	return hours
+}
+
+// Hours modes selecting how resolution times are counted.
+const (
+	CalendarHours = "calendar"
+	BusinessHours = "business"
+)
+
+// CalendarFor returns the work calendar resolution times are counted in for an hours mode: nil for calendar
+// hours and, for business hours, the calendar loaded from path or DefaultWorkCalendar if path is empty.
+func CalendarFor(mode, path string) (*WorkCalendar, error) {
+	switch mode {
+	case CalendarHours:
+		return nil, nil
+	case BusinessHours:
+		cal := DefaultWorkCalendar
+		if path != "" {
+			var err error
+			if cal, err = LoadWorkCalendar(path); err != nil {
+				return nil, err
+			}
+		}
+		return &cal, nil
+	default:
+		return nil, fmt.Errorf("%s is not a valid hours mode; valid modes are %s and %s", mode, CalendarHours,
+			BusinessHours)
+	}
+}
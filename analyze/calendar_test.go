@@ -0,0 +1,57 @@
+package analyze
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestBusinessHoursResolution(t *testing.T) {
+	// created on Friday at 15:00 and closed on Monday at 11:00, spanning a weekend.
+	weekend := ticketWith("H-1", "Closed", transition{4*24 + 11, "Open", "Closed"})
+	weekend.Fields.Created = at(2, 15)
+	// created and closed on Tuesday within working hours.
+	weekday := ticketWith("H-2", "Closed", transition{5*24 + 12, "Open", "Closed"})
+	weekday.Fields.Created = at(6, 10)
+
+	timesToClose := func(mode, calendarPath string) []float64 {
+		t.Helper()
+		cal, err := CalendarFor(mode, calendarPath)
+		if err != nil {
+			t.Fatalf("could not get the %s hours calendar: %v", mode, err)
+		}
+		tickets := []jira.JiraIssue{weekend, weekday}
+		TimesToCloseWith(ResolutionOptions{Calendar: cal})(tickets...)
+		return []float64{tickets[0].TimeToClose, tickets[1].TimeToClose}
+	}
+
+	calendar := timesToClose(CalendarHours, "")
+	business := timesToClose(BusinessHours, "")
+	if calendar[0] != 68 || business[0] != 4 {
+		t.Errorf("weekend spanning ticket took %v calendar and %v business hours, want 68 and 4", calendar[0],
+			business[0])
+	}
+	if calendar[1] != 2 || business[1] != 2 {
+		t.Errorf("weekday ticket took %v calendar and %v business hours, want 2 both ways", calendar[1], business[1])
+	}
+
+	dir, err := ioutil.TempDir("", "calendar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "calendar.json")
+	if err := ioutil.WriteFile(path, []byte(`{"holidays": ["2018-03-05"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if withHoliday := timesToClose(BusinessHours, path); withHoliday[0] != 2 {
+		t.Errorf("weekend spanning ticket took %v business hours with Monday off, want 2", withHoliday[0])
+	}
+
+	if _, err := CalendarFor("lunar", ""); err == nil {
+		t.Error("expected an error for an unknown hours mode")
+	}
+}
@@ -0,0 +1,24 @@
+package analyze
+
+import (
+	"regexp"
+)
+
+// codeRegexes match, in order, Jira {code} and {noformat} blocks, Markdown fenced blocks, Jira {{monospaced}}
+// text and Markdown inline code.
+var codeRegexes = []*regexp.Regexp{
+	regexp.MustCompile(`(?s)\{code(:[^}]*)?\}.*?\{code\}`),
+	regexp.MustCompile(`(?s)\{noformat\}.*?\{noformat\}`),
+	regexp.MustCompile("(?s)```.*?```"),
+	regexp.MustCompile(`\{\{[^}]*\}\}`),
+	regexp.MustCompile("`[^`\n]*`"),
+}
+
+// StripCode returns s with all fenced, inline and Jira code blocks replaced by a whitespace, so that pasted code
+// does not inflate word counts.
+func StripCode(s string) string {
+	for _, regex := range codeRegexes {
+		s = regex.ReplaceAllString(s, " ")
+	}
+	return s
+}
@@ -0,0 +1,61 @@
+package analyze
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestStripCode(t *testing.T) {
+	for input, want := range map[string]string{
+		"before {code:java}\nint a = 1;\n{code} after": "before after",
+		"before {noformat}raw log{noformat} after":     "before after",
+		"before ```\nfoo()\n``` after":                 "before after",
+		"run {{make test}} and `go vet` now":           "run and now",
+		"no code at all":                               "no code at all",
+	} {
+		if got := strings.Join(strings.Fields(StripCode(input)), " "); got != want {
+			t.Errorf("StripCode(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestCodelessWordCounts(t *testing.T) {
+	ticket := jira.JiraIssue{Key: "CODE-1"}
+	ticket.Fields.Summary = "crash in parser"
+	ticket.Fields.Description = "Calls `parse(x)` then fails:\n{code:java}\nint a = 1;\nthrow new Error();\n{code}\n" +
+		"see {{Parser.run}}"
+	ticket.Fields.Comments.Comments = []jira.Comment{{ID: "1", Body: "```\nfoo bar\n``` done"}}
+	tickets := []jira.JiraIssue{ticket}
+
+	WordCounts(tickets...)
+	if got := tickets[0]; got.SummaryDescWordsCount != 18 || got.CommentWordsCount != 5 {
+		t.Errorf("raw summary, description and comment words = %d, %d; want 18, 5", got.SummaryDescWordsCount,
+			got.CommentWordsCount)
+	}
+	if got := tickets[0]; got.CodelessSummaryDescWordsCount != 7 || got.CodelessCommentWordsCount != 1 {
+		t.Errorf("code-stripped summary, description and comment words = %d, %d; want 7, 1",
+			got.CodelessSummaryDescWordsCount, got.CodelessCommentWordsCount)
+	}
+	if got := CodelessSummaryDescWords(ticket); got != 7 {
+		t.Errorf("code-stripped summary and description words computed on demand = %d, want 7", got)
+	}
+}
+
+func TestCommentWordsAcrossComments(t *testing.T) {
+	ticket := jira.JiraIssue{Key: "CODE-2"}
+	ticket.Fields.Comments.Comments = []jira.Comment{{ID: "1", Body: "same here"}, {ID: "2", Body: "me too"},
+		{ID: "3", Body: "fixed now"}}
+	tickets := []jira.JiraIssue{ticket}
+
+	// the last word of a comment is not merged with the first word of the next one.
+	WordCounts(tickets...)
+	if got := tickets[0]; got.CommentWordsCount != 6 || got.CodelessCommentWordsCount != 6 {
+		t.Errorf("raw and code-stripped comment words = %d, %d; want 6, 6", got.CommentWordsCount,
+			got.CodelessCommentWordsCount)
+	}
+	if got := CommentWords(ticket); got != 6 {
+		t.Errorf("comment words computed on demand = %d, want 6", got)
+	}
+}
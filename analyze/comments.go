@@ -0,0 +1,152 @@
+package analyze
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+const (
+	// EssayCommentWords defines the default number of words above which a single comment counts as essay-length.
+	EssayCommentWords = 300
+	// PostResolutionChatterComments defines the default number of comments posted after its resolution from which
+	// a ticket counts as having significant post-resolution chatter.
+	PostResolutionChatterComments = 3
+	// LongSilenceHours defines the default number of hours without any new comment after which an open ticket
+	// counts as forgotten.
+	LongSilenceHours = 7 * 24
+)
+
+// CommentLengthStats returns the statistics of the number of words of each comment of a ticket, or zero
+// statistics if the ticket has no comments.
+func CommentLengthStats(ticket jira.JiraIssue) Stats {
+	lengths := CommentLengths(ticket)
+	if len(lengths) == 0 {
+		return Stats{}
+	}
+	return newStats(lengths)
+}
+
+// DedupeConsecutiveComments returns the comments of a ticket, in order, without those repeating the body of
+// the comment right before them (e.g. posted multiple times by looping integrations), along with the number of
+// removed duplicates. Bodies are compared ignoring leading and trailing whitespace.
+func DedupeConsecutiveComments(ticket jira.JiraIssue) ([]jira.Comment, int) {
+	comments := ticket.Fields.Comments.Comments
+	deduped := make([]jira.Comment, 0, len(comments))
+	for i, comment := range comments {
+		if i > 0 && strings.TrimSpace(comment.Body) == strings.TrimSpace(comments[i-1].Body) {
+			continue
+		}
+		deduped = append(deduped, comment)
+	}
+	return deduped, len(comments) - len(deduped)
+}
+
+// uniqueComments returns the comments of a ticket without consecutive duplicates.
+func uniqueComments(ticket jira.JiraIssue) []jira.Comment {
+	comments, _ := DedupeConsecutiveComments(ticket)
+	return comments
+}
+
+// CommentLengths returns the number of words of each comment of a ticket, in order, skipping consecutive
+// duplicates.
+func CommentLengths(ticket jira.JiraIssue) []float64 {
+	comments := uniqueComments(ticket)
+	lengths := make([]float64, len(comments))
+	for i, comment := range comments {
+		lengths[i] = float64(calculateNumberOfWords(comment.Body))
+	}
+	return lengths
+}
+
+// HasEssayComment returns whether any comment of a ticket holds more than threshold words.
+func HasEssayComment(ticket jira.JiraIssue, threshold int) bool {
+	for _, length := range CommentLengths(ticket) {
+		if length > float64(threshold) {
+			return true
+		}
+	}
+	return false
+}
+
+// PostResolutionComments returns the number of comments of a ticket created after it was first resolved, which
+// may hint at an incomplete fix. Tickets never resolved have none.
+func PostResolutionComments(ticket jira.JiraIssue) int {
+	resolvedAt, ok := resolutionTime(ticket)
+	if !ok {
+		return 0
+	}
+	var count int
+	for _, comment := range uniqueComments(ticket) {
+		if time.Time(comment.Created).After(resolvedAt) {
+			count++
+		}
+	}
+	return count
+}
+
+// PostResolutionChatter returns the tickets with at least minComments comments created after their resolution
+// along with their number, most commented first and ties broken by key.
+func PostResolutionChatter(minComments int, tickets ...jira.JiraIssue) []RankedTicket {
+	var ranked []RankedTicket
+	for _, ticket := range tickets {
+		if n := PostResolutionComments(ticket); n > 0 && n >= minComments {
+			ranked = append(ranked, RankedTicket{Key: ticket.Key, Value: float64(n)})
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Value != ranked[j].Value {
+			return ranked[i].Value > ranked[j].Value
+		}
+		return ranked[i].Key < ranked[j].Key
+	})
+	return ranked
+}
+
+// MaxCommentGap returns the longest number of hours elapsed between two consecutive comments of a ticket, or
+// between its creation and its first comment. Tickets without comments have no gap.
+func MaxCommentGap(ticket jira.JiraIssue) float64 {
+	comments := ticket.Fields.Comments.Comments
+	if len(comments) == 0 {
+		return 0
+	}
+	times := make([]time.Time, len(comments))
+	for i, comment := range comments {
+		times[i] = time.Time(comment.Created)
+	}
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].Before(times[j])
+	})
+	var longest float64
+	previous := time.Time(ticket.Fields.Created)
+	for _, t := range times {
+		if gap := t.Sub(previous).Hours(); gap > longest {
+			longest = gap
+		}
+		previous = t
+	}
+	return longest
+}
+
+// SilentOpenTickets returns the open tickets whose MaxCommentGap exceeds thresholdHours along with their gap,
+// longest first and ties broken by key.
+func SilentOpenTickets(thresholdHours float64, tickets ...jira.JiraIssue) []RankedTicket {
+	var ranked []RankedTicket
+	for _, ticket := range tickets {
+		if isResolved(ticket) {
+			continue
+		}
+		if gap := MaxCommentGap(ticket); gap > thresholdHours {
+			ranked = append(ranked, RankedTicket{Key: ticket.Key, Value: gap})
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Value != ranked[j].Value {
+			return ranked[i].Value > ranked[j].Value
+		}
+		return ranked[i].Key < ranked[j].Key
+	})
+	return ranked
+}
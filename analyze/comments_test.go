@@ -0,0 +1,118 @@
+package analyze
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestCommentLengthStats(t *testing.T) {
+	ticket := jira.JiraIssue{Key: "CL-1"}
+	ticket.Fields.Comments.Comments = []jira.Comment{
+		{ID: "1", Body: "same here"},
+		{ID: "2", Body: "any update on this?"},
+		{ID: "3", Body: strings.Repeat("word ", 12)},
+	}
+
+	if got, want := CommentLengthStats(ticket), (Stats{Count: 3, Mean: 6, Median: 4, Min: 2, Max: 12}); got != want {
+		t.Errorf("CommentLengthStats = %+v, want %+v", got, want)
+	}
+	if !HasEssayComment(ticket, 10) {
+		t.Error("a 12 words comment is not flagged above a 10 words threshold")
+	}
+	if HasEssayComment(ticket, 12) {
+		t.Error("a 12 words comment is flagged at a 12 words threshold")
+	}
+	if got := CommentLengthStats(jira.JiraIssue{}); got != (Stats{}) {
+		t.Errorf("CommentLengthStats without comments = %+v, want zero statistics", got)
+	}
+}
+
+func TestDedupeConsecutiveComments(t *testing.T) {
+	ticket := jira.JiraIssue{Key: "DC-1"}
+	for _, body := range []string{"build failed", "build failed ", "build failed", "retrying", "build failed"} {
+		ticket.Fields.Comments.Comments = append(ticket.Fields.Comments.Comments, jira.Comment{Body: body})
+	}
+
+	// only adjacent copies are removed, so the failure reported again after the retry is kept.
+	comments, removed := DedupeConsecutiveComments(ticket)
+	var bodies []string
+	for _, c := range comments {
+		bodies = append(bodies, c.Body)
+	}
+	if want := []string{"build failed", "retrying", "build failed"}; !reflect.DeepEqual(bodies, want) || removed != 2 {
+		t.Errorf("DedupeConsecutiveComments = %q, %d; want %q, 2", bodies, removed, want)
+	}
+	if got := CommentLengths(ticket); !reflect.DeepEqual(got, []float64{2, 1, 2}) {
+		t.Errorf("CommentLengths = %v, want [2 1 2]", got)
+	}
+
+	distinct := jira.JiraIssue{Key: "DC-2"}
+	distinct.Fields.Comments.Comments = []jira.Comment{{Body: "first"}, {Body: "second"}}
+	if comments, removed := DedupeConsecutiveComments(distinct); len(comments) != 2 || removed != 0 {
+		t.Errorf("DedupeConsecutiveComments of distinct comments = %v, %d; want both kept", comments, removed)
+	}
+}
+
+func TestPostResolutionComments(t *testing.T) {
+	commented := func(ticket jira.JiraIssue, hours ...float64) jira.JiraIssue {
+		for i, h := range hours {
+			ticket.Fields.Comments.Comments = append(ticket.Fields.Comments.Comments,
+				jira.Comment{Body: fmt.Sprintf("comment %d", i), Created: at(1, h)})
+		}
+		return ticket
+	}
+	// resolved after 10 hours, with one comment before and two after the resolution.
+	fixed := commented(ticketWith("PC-1", "Closed", transition{10, "Open", "Closed"}), 5, 12, 15)
+	// a consecutive duplicate of the last comment is not counted.
+	fixed.Fields.Comments.Comments = append(fixed.Fields.Comments.Comments,
+		jira.Comment{Body: "comment 2", Created: at(1, 16)})
+	chatty := commented(ticketWith("PC-2", "Closed", transition{2, "Open", "Closed"}), 3, 4, 5)
+	open := commented(ticketWith("PC-3", "Open"), 3, 4, 5)
+
+	for _, tc := range []struct {
+		ticket jira.JiraIssue
+		want   int
+	}{{fixed, 2}, {chatty, 3}, {open, 0}} {
+		if got := PostResolutionComments(tc.ticket); got != tc.want {
+			t.Errorf("PostResolutionComments of %s = %d, want %d", tc.ticket.Key, got, tc.want)
+		}
+	}
+	want := []RankedTicket{{Key: "PC-2", Value: 3}, {Key: "PC-1", Value: 2}}
+	if got := PostResolutionChatter(2, open, fixed, chatty); !reflect.DeepEqual(got, want) {
+		t.Errorf("PostResolutionChatter = %+v, want %+v", got, want)
+	}
+}
+
+func TestMaxCommentGap(t *testing.T) {
+	commented := func(ticket jira.JiraIssue, hours ...float64) jira.JiraIssue {
+		for i, h := range hours {
+			ticket.Fields.Comments.Comments = append(ticket.Fields.Comments.Comments,
+				jira.Comment{Body: fmt.Sprintf("comment %d", i), Created: at(1, h)})
+		}
+		return ticket
+	}
+	// the thread goes silent for 185 hours between the comments after 5 and 190 hours, posted out of order.
+	silent := commented(ticketWith("G-1", "Open"), 2, 190, 5, 200)
+	late := commented(ticketWith("G-2", "In Progress"), 220)
+	busy := commented(ticketWith("G-3", "Open"), 10, 20, 30)
+	forgotten := commented(ticketWith("G-4", "Closed", transition{300, "Open", "Closed"}), 250)
+	uncommented := ticketWith("G-5", "Open")
+
+	for _, tc := range []struct {
+		ticket jira.JiraIssue
+		want   float64
+	}{{silent, 185}, {late, 220}, {busy, 10}, {forgotten, 250}, {uncommented, 0}} {
+		if got := MaxCommentGap(tc.ticket); got != tc.want {
+			t.Errorf("MaxCommentGap of %s = %v, want %v", tc.ticket.Key, got, tc.want)
+		}
+	}
+	want := []RankedTicket{{Key: "G-2", Value: 220}, {Key: "G-1", Value: 185}}
+	got := SilentOpenTickets(LongSilenceHours, busy, silent, forgotten, uncommented, late)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SilentOpenTickets = %+v, want %+v", got, want)
+	}
+}
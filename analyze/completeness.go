@@ -0,0 +1,45 @@
+package analyze
+
+import (
+	"strings"
+	"time"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// completenessChecks holds, indexed by field name, whether a ticket holds a value for each of the key fields
+// reported by Completeness.
+var completenessChecks = map[string]func(jira.JiraIssue) bool{
+	"description":      func(t jira.JiraIssue) bool { return strings.TrimSpace(t.Fields.Description) != "" },
+	"comments":         func(t jira.JiraIssue) bool { return len(t.Fields.Comments.Comments) > 0 },
+	"resolved":         isResolved,
+	"resolution":       func(t jira.JiraIssue) bool { return t.Fields.Resolution.Name != "" },
+	"attachments":      func(t jira.JiraIssue) bool { return len(t.Fields.Attachments) > 0 },
+	"environment":      func(t jira.JiraIssue) bool { return strings.TrimSpace(t.Fields.Environment) != "" },
+	"assignee":         func(t jira.JiraIssue) bool { return t.Fields.Assignee.Name != "" },
+	"reporter":         func(t jira.JiraIssue) bool { return t.Fields.Reporter.Name != "" },
+	"created":          func(t jira.JiraIssue) bool { return !time.Time(t.Fields.Created).IsZero() },
+	"changelog":        func(t jira.JiraIssue) bool { return len(t.Changelog.Histories) > 0 },
+	"fix_versions":     func(t jira.JiraIssue) bool { return len(t.Fields.FixVersions) > 0 },
+	"affects_versions": func(t jira.JiraIssue) bool { return len(t.Fields.AffectsVersions) > 0 },
+}
+
+// Completeness returns, for each key field, the fraction of tickets holding a value for it, so that the quality
+// of a dataset can be judged before analyzing it. All fractions are 0 if there are no tickets.
+func Completeness(tickets ...jira.JiraIssue) map[string]float64 {
+	fractions := make(map[string]float64, len(completenessChecks))
+	for name, present := range completenessChecks {
+		var count int
+		for _, t := range tickets {
+			if present(t) {
+				count++
+			}
+		}
+		if len(tickets) > 0 {
+			fractions[name] = float64(count) / float64(len(tickets))
+		} else {
+			fractions[name] = 0
+		}
+	}
+	return fractions
+}
@@ -0,0 +1,47 @@
+package analyze
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestCompleteness(t *testing.T) {
+	detailed := ticketWith("C-1", "Closed", transition{5, "Open", "Closed"})
+	detailed.Fields.Description = "crashes on save"
+	detailed.Fields.Resolution.Name = "Fixed"
+	detailed.Fields.Attachments = []jira.Attachment{{Filename: "crash.png"}}
+	detailed.Fields.Assignee.Name = "alice"
+	// a blank description does not count as one.
+	commented := ticketWith("C-2", "Open")
+	commented.Fields.Description = "  "
+	commented.Fields.Comments.Comments = []jira.Comment{{Body: "same here"}}
+	assigned := ticketWith("C-3", "Open")
+	assigned.Fields.Assignee.Name = "bob"
+
+	got := Completeness(detailed, commented, assigned, jira.JiraIssue{Key: "C-4"})
+	want := map[string]float64{
+		"description":      0.25,
+		"comments":         0.25,
+		"resolved":         0.25,
+		"resolution":       0.25,
+		"attachments":      0.25,
+		"environment":      0,
+		"assignee":         0.5,
+		"reporter":         0,
+		"created":          0.75,
+		"changelog":        0.25,
+		"fix_versions":     0,
+		"affects_versions": 0,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Completeness = %v, want %v", got, want)
+	}
+
+	for field, fraction := range Completeness() {
+		if fraction != 0 {
+			t.Errorf("%s completeness of no tickets = %v, want 0", field, fraction)
+		}
+	}
+}
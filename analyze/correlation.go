@@ -0,0 +1,122 @@
+package analyze
+
+import (
+	"errors"
+	"math"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// GrammarSentimentCorrelation returns the Pearson correlation coefficient between the grammar and sentiment
+// scores of the tickets having both, along with the number of such tickets.
+func GrammarSentimentCorrelation(tickets ...jira.JiraIssue) (float64, int, error) {
+	var grammar, sentiment []float64
+	for _, t := range tickets {
+		if t.GrammarCorrectness.HasScore && t.Sentiment.HasScore {
+			grammar = append(grammar, float64(t.GrammarCorrectness.Score))
+			sentiment = append(sentiment, t.Sentiment.Score)
+		}
+	}
+	r, err := pearson(grammar, sentiment)
+	return r, len(grammar), err
+}
+
+// correlationFeatures holds the numeric features compared by CorrelationMatrix; a NaN value marks a feature
+// missing for a ticket.
+var correlationFeatures = []struct {
+	label string
+	value func(jira.JiraIssue) float64
+}{
+	{"time_to_close", func(t jira.JiraIssue) float64 {
+		if t.TimeToClose <= 0 {
+			return math.NaN()
+		}
+		return t.TimeToClose
+	}},
+	{"summary_desc_words", func(t jira.JiraIssue) float64 { return float64(SummaryDescWords(t)) }},
+	{"comment_words", func(t jira.JiraIssue) float64 { return float64(CommentWords(t)) }},
+	{"attachment_count", func(t jira.JiraIssue) float64 { return float64(len(t.Fields.Attachments)) }},
+	{"sentiment", func(t jira.JiraIssue) float64 {
+		if !t.Sentiment.HasScore {
+			return math.NaN()
+		}
+		return t.Sentiment.Score
+	}},
+	{"grammar_errors", func(t jira.JiraIssue) float64 {
+		if !t.GrammarCorrectness.HasScore {
+			return math.NaN()
+		}
+		return float64(t.GrammarCorrectness.Score)
+	}},
+	{"reopen_count", func(t jira.JiraIssue) float64 { return float64(ReopenCount(t)) }},
+	{"reassignments", func(t jira.JiraIssue) float64 { return float64(ReassignmentCount(t)) }},
+	{"links", func(t jira.JiraIssue) float64 { return float64(CountLinks(t)) }},
+}
+
+// CorrelationMatrix returns the labels of the numeric ticket features and the matrix of their pairwise Pearson
+// correlations, index-aligned with the labels. Each pair only uses the tickets having both features (pairwise
+// complete observations); pairs which cannot be correlated, e.g. because of too few observations or zero
+// variance, are set to NaN.
+func CorrelationMatrix(tickets ...jira.JiraIssue) ([]string, [][]float64) {
+	labels := make([]string, len(correlationFeatures))
+	values := make([][]float64, len(correlationFeatures))
+	for i, feature := range correlationFeatures {
+		labels[i] = feature.label
+		values[i] = make([]float64, len(tickets))
+		for j, ticket := range tickets {
+			values[i][j] = feature.value(ticket)
+		}
+	}
+	matrix := make([][]float64, len(labels))
+	for i := range matrix {
+		matrix[i] = make([]float64, len(labels))
+	}
+	for i := range labels {
+		for j := i; j < len(labels); j++ {
+			var xs, ys []float64
+			for k := range tickets {
+				if !math.IsNaN(values[i][k]) && !math.IsNaN(values[j][k]) {
+					xs = append(xs, values[i][k])
+					ys = append(ys, values[j][k])
+				}
+			}
+			r, err := pearson(xs, ys)
+			switch {
+			case err != nil:
+				r = math.NaN()
+			case i == j:
+				r = 1
+			}
+			matrix[i][j], matrix[j][i] = r, r
+		}
+	}
+	return labels, matrix
+}
+
+// pearson computes the Pearson correlation coefficient of two equally sized samples.
+func pearson(xs, ys []float64) (float64, error) {
+	if len(xs) != len(ys) {
+		return 0, errors.New("samples have different sizes")
+	}
+	if len(xs) < 2 {
+		return 0, errors.New("sample is too small")
+	}
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(len(xs))
+	meanY /= float64(len(ys))
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0, errors.New("sample has zero variance")
+	}
+	return cov / math.Sqrt(varX*varY), nil
+}
@@ -0,0 +1,81 @@
+package analyze
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// scoredTicket returns a ticket holding the given grammar errors and sentiment scores.
+func scoredTicket(grammar int, sentiment float64) jira.JiraIssue {
+	var ticket jira.JiraIssue
+	ticket.GrammarCorrectness = jira.GrammarCorrectness{Score: grammar, HasScore: true}
+	ticket.Sentiment = jira.Sentiment{Score: sentiment, HasScore: true}
+	return ticket
+}
+
+func TestGrammarSentimentCorrelation(t *testing.T) {
+	grammarOnly := jira.JiraIssue{GrammarCorrectness: jira.GrammarCorrectness{Score: 9, HasScore: true}}
+	for _, tc := range []struct {
+		tickets []jira.JiraIssue
+		want    float64
+		n       int
+	}{
+		{[]jira.JiraIssue{scoredTicket(0, 0.6), scoredTicket(1, 0.4), grammarOnly, scoredTicket(2, 0.2),
+			scoredTicket(3, 0)}, -1, 4},
+		{[]jira.JiraIssue{scoredTicket(1, 1), scoredTicket(2, 3), scoredTicket(3, 2)}, 0.5, 3},
+	} {
+		r, n, err := GrammarSentimentCorrelation(tc.tickets...)
+		if err != nil {
+			t.Fatalf("could not compute correlation: %v", err)
+		}
+		if math.Abs(r-tc.want) > 1e-9 || n != tc.n {
+			t.Errorf("correlation = %v over %d tickets, want %v over %d", r, n, tc.want, tc.n)
+		}
+	}
+
+	if _, n, err := GrammarSentimentCorrelation(scoredTicket(1, 0.5), grammarOnly); err == nil || n != 1 {
+		t.Errorf("correlation of a single paired ticket: n = %d, err = %v; want n = 1 and an error", n, err)
+	}
+}
+
+func TestCorrelationMatrix(t *testing.T) {
+	var tickets []jira.JiraIssue
+	for i, sentiment := range []float64{0.5, 0, -0.5} {
+		ticket := jira.JiraIssue{TimeToClose: float64(10 * (i + 1))}
+		ticket.Fields.Attachments = make([]jira.Attachment, i+1)
+		ticket.Sentiment = jira.Sentiment{Score: sentiment, HasScore: true}
+		tickets = append(tickets, ticket)
+	}
+	unscored := jira.JiraIssue{TimeToClose: 40}
+	unscored.Fields.Attachments = make([]jira.Attachment, 4)
+	open := jira.JiraIssue{}
+	open.Fields.Attachments = make([]jira.Attachment, 9)
+	tickets = append(tickets, unscored, open)
+
+	labels, matrix := CorrelationMatrix(tickets...)
+	index := make(map[string]int)
+	for i, label := range labels {
+		index[label] = i
+	}
+	ttc, attachments, sentiment, reopens := index["time_to_close"], index["attachment_count"],
+		index["sentiment"], index["reopen_count"]
+	for _, i := range []int{ttc, attachments, sentiment} {
+		if matrix[i][i] != 1 {
+			t.Errorf("correlation of %s with itself = %v, want 1", labels[i], matrix[i][i])
+		}
+	}
+	// the open ticket has no time-to-close and only takes part in the pairs it has both features of.
+	for _, pair := range []struct {
+		i, j int
+		want float64
+	}{{ttc, attachments, 1}, {ttc, sentiment, -1}, {attachments, sentiment, -1}} {
+		if got := matrix[pair.i][pair.j]; math.Abs(got-pair.want) > 1e-9 || matrix[pair.j][pair.i] != got {
+			t.Errorf("correlation of %s and %s = %v, want %v", labels[pair.i], labels[pair.j], got, pair.want)
+		}
+	}
+	if !math.IsNaN(matrix[reopens][ttc]) {
+		t.Errorf("correlation of a feature without variance = %v, want NaN", matrix[reopens][ttc])
+	}
+}
@@ -0,0 +1,103 @@
+package analyze
+
+import (
+	"unicode/utf8"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// cp1252Bytes maps the characters Windows-1252 places in the 0x80-0x9F range back to their byte values, as
+// UTF-8 text decoded as Windows-1252 often ends up with these instead of the Latin-1 control characters.
+var cp1252Bytes = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88, '‰': 0x89,
+	'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95,
+	'–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// latin1Byte returns the single byte a character was decoded from if the text was read as Latin-1 or
+// Windows-1252.
+func latin1Byte(r rune) (byte, bool) {
+	if r < 0x100 {
+		return byte(r), true
+	}
+	b, ok := cp1252Bytes[r]
+	return b, ok
+}
+
+// DetectMojibake returns whether a text contains UTF-8 sequences mis-decoded as Latin-1 or Windows-1252,
+// e.g. "Ã©" instead of "é" or "â€™" instead of "’".
+func DetectMojibake(s string) bool {
+	runes := []rune(s)
+	for i, r := range runes {
+		lead, ok := latin1Byte(r)
+		if !ok {
+			continue
+		}
+		var continuations int
+		switch {
+		case lead >= 0xC2 && lead <= 0xDF:
+			continuations = 1
+		case lead >= 0xE0 && lead <= 0xEF:
+			continuations = 2
+		case lead >= 0xF0 && lead <= 0xF4:
+			continuations = 3
+		default:
+			continue
+		}
+		if i+continuations >= len(runes) {
+			continue
+		}
+		seq := []byte{lead}
+		for _, c := range runes[i+1 : i+1+continuations] {
+			b, ok := latin1Byte(c)
+			if !ok || b < 0x80 || b > 0xBF {
+				break
+			}
+			seq = append(seq, b)
+		}
+		if len(seq) == continuations+1 && utf8.Valid(seq) {
+			return true
+		}
+	}
+	return false
+}
+
+// RepairMojibake re-decodes as UTF-8 a text mis-decoded as Latin-1 or Windows-1252, returning whether the
+// repair succeeded. Texts which cannot be repaired, e.g. because they mix mojibake with characters outside
+// of Windows-1252, are returned unchanged.
+func RepairMojibake(s string) (string, bool) {
+	if !DetectMojibake(s) {
+		return s, false
+	}
+	buf := make([]byte, 0, len(s))
+	for _, r := range s {
+		b, ok := latin1Byte(r)
+		if !ok {
+			return s, false
+		}
+		buf = append(buf, b)
+	}
+	if !utf8.Valid(buf) {
+		return s, false
+	}
+	return string(buf), true
+}
+
+// MojibakeTickets returns the keys of the tickets whose summary, description or any of the comments contain
+// mis-decoded text.
+func MojibakeTickets(tickets ...jira.JiraIssue) []string {
+	var keys []string
+	for _, ticket := range tickets {
+		texts := []string{ticket.Fields.Summary, ticket.Fields.Description}
+		for _, comment := range ticket.Fields.Comments.Comments {
+			texts = append(texts, comment.Body)
+		}
+		for _, text := range texts {
+			if DetectMojibake(text) {
+				keys = append(keys, ticket.Key)
+				break
+			}
+		}
+	}
+	return keys
+}
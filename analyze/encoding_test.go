@@ -0,0 +1,46 @@
+package analyze
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestMojibake(t *testing.T) {
+	for _, tc := range []struct {
+		text     string
+		mojibake bool
+		repaired string
+	}{
+		{"the cafÃ© crashes", true, "the café crashes"},
+		{"it doesnâ€™t load", true, "it doesn’t load"},
+		{"the café crashes", false, "the café crashes"},
+		{"plain ASCII text", false, "plain ASCII text"},
+		{"trailing Ã", false, "trailing Ã"},
+		// mojibake mixed with characters outside of Windows-1252 cannot be repaired.
+		{"cafÃ© ☕", true, "cafÃ© ☕"},
+	} {
+		if got := DetectMojibake(tc.text); got != tc.mojibake {
+			t.Errorf("DetectMojibake(%q) = %v, want %v", tc.text, got, tc.mojibake)
+		}
+		repaired, ok := RepairMojibake(tc.text)
+		if repaired != tc.repaired || ok != (tc.repaired != tc.text) {
+			t.Errorf("RepairMojibake(%q) = (%q, %v), want %q", tc.text, repaired, ok, tc.repaired)
+		}
+	}
+}
+
+func TestMojibakeTickets(t *testing.T) {
+	clean := jira.JiraIssue{Key: "E-1"}
+	clean.Fields.Summary = "résumé upload fails"
+	inComment := jira.JiraIssue{Key: "E-2"}
+	inComment.Fields.Comments.Comments = []jira.Comment{{Body: "still broken"}, {Body: "rÃ©sumÃ© again"}}
+	inDescription := jira.JiraIssue{Key: "E-3"}
+	inDescription.Fields.Description = "rÃ©sumÃ©"
+
+	got := MojibakeTickets(clean, inComment, inDescription)
+	if want := []string{"E-2", "E-3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MojibakeTickets = %v, want %v", got, want)
+	}
+}
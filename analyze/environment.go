@@ -0,0 +1,78 @@
+package analyze
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// osRegex matches an operating system name optionally followed by its version.
+var osRegex = regexp.MustCompile(`(?i)\b(windows|mac ?os ?x|mac ?os|os ?x|ubuntu|debian|centos|red ?hat|rhel|` +
+	`fedora|suse|linux|android|ios|freebsd|solaris)\b[ /-]*(v?\d+(?:\.\d+)*\b|xp\b|vista\b|server \d+\b)?`)
+
+// osNames maps the lowercased operating system names matched by osRegex to their canonical form.
+var osNames = map[string]string{
+	"windows":  "Windows",
+	"macosx":   "macOS",
+	"mac osx":  "macOS",
+	"macos x":  "macOS",
+	"mac os x": "macOS",
+	"macos":    "macOS",
+	"mac os":   "macOS",
+	"osx":      "macOS",
+	"os x":     "macOS",
+	"ubuntu":   "Ubuntu",
+	"debian":   "Debian",
+	"centos":   "CentOS",
+	"redhat":   "Red Hat",
+	"red hat":  "Red Hat",
+	"rhel":     "Red Hat",
+	"fedora":   "Fedora",
+	"suse":     "SUSE",
+	"linux":    "Linux",
+	"android":  "Android",
+	"ios":      "iOS",
+	"freebsd":  "FreeBSD",
+	"solaris":  "Solaris",
+}
+
+// EnvironmentInfo holds the operating system information extracted from a ticket's environment field.
+type EnvironmentInfo struct {
+	OS        string
+	OSVersion string
+}
+
+// ParseEnvironment heuristically extracts the first operating system and its version mentioned inside
+// a ticket's environment field (e.g. "Windows 10", "macOS 13.2").
+func ParseEnvironment(s string) EnvironmentInfo {
+	match := osRegex.FindStringSubmatch(s)
+	if match == nil {
+		return EnvironmentInfo{}
+	}
+	name, ok := osNames[strings.ToLower(match[1])]
+	if !ok {
+		name = match[1]
+	}
+	return EnvironmentInfo{
+		OS:        name,
+		OSVersion: strings.TrimLeft(match[2], "vV"),
+	}
+}
+
+// TimeToResolveByOS groups the times-to-close of closed tickets by the operating system found inside
+// their environment field; tickets without a recognizable operating system are grouped under "Unknown".
+func TimeToResolveByOS(tickets ...jira.JiraIssue) map[string][]float64 {
+	result := make(map[string][]float64)
+	for _, ticket := range tickets {
+		if ticket.TimeToClose <= 0 {
+			continue
+		}
+		name := ParseEnvironment(ticket.Fields.Environment).OS
+		if name == "" {
+			name = "Unknown"
+		}
+		result[name] = append(result[name], ticket.TimeToClose)
+	}
+	return result
+}
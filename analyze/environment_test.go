@@ -0,0 +1,39 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestParseEnvironment(t *testing.T) {
+	for env, want := range map[string]EnvironmentInfo{
+		"Windows 10 Pro, Chrome 112":  {OS: "Windows", OSVersion: "10"},
+		"Windows XP SP3":              {OS: "Windows", OSVersion: "XP"},
+		"macOS 13.2 (Ventura)":        {OS: "macOS", OSVersion: "13.2"},
+		"Mac OS X 10.15.7":            {OS: "macOS", OSVersion: "10.15.7"},
+		"server: Ubuntu 22.04 LTS":    {OS: "Ubuntu", OSVersion: "22.04"},
+		"iOS v16.1 on iPhone 12":      {OS: "iOS", OSVersion: "16.1"},
+		"Firefox on linux":            {OS: "Linux"},
+		"RHEL/8.6":                    {OS: "Red Hat", OSVersion: "8.6"},
+		"staging cluster, build 4711": {},
+		"":                            {},
+	} {
+		if got := ParseEnvironment(env); got != want {
+			t.Errorf("ParseEnvironment(%q) = %+v, want %+v", env, got, want)
+		}
+	}
+}
+
+func TestTimeToResolveByOS(t *testing.T) {
+	tickets := []jira.JiraIssue{
+		{Key: "E-1", TimeToClose: 10, Fields: jira.Fields{Environment: "Windows 10"}},
+		{Key: "E-2", TimeToClose: 20, Fields: jira.Fields{Environment: "windows 11"}},
+		{Key: "E-3", TimeToClose: 30},
+		{Key: "E-4", TimeToClose: 0, Fields: jira.Fields{Environment: "macOS 13"}},
+	}
+	got := TimeToResolveByOS(tickets...)
+	if len(got) != 2 || len(got["Windows"]) != 2 || len(got["Unknown"]) != 1 {
+		t.Errorf("TimeToResolveByOS = %v, want 2 Windows and 1 Unknown times", got)
+	}
+}
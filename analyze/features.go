@@ -0,0 +1,66 @@
+package analyze
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// featureColumns holds the names of the columns returned by FeatureVectors, with the label last.
+var featureColumns = []string{
+	"summary_desc_words",
+	"comment_words",
+	"attachment_count",
+	"has_stack_trace",
+	"has_steps_to_reproduce",
+	"sentiment",
+	"grammar_errors",
+	"priority",
+	"time_to_close",
+}
+
+// FeatureVectors returns the column names and one row of numeric features per ticket, suitable for training
+// models outside of ticketguru. Booleans are encoded as 0/1, the priority by its numeric Jira ID and the last
+// column holds the time-to-close label. Missing values (unscored sentiment or grammar, unknown priority,
+// unresolved ticket) are consistently encoded as NaN.
+func FeatureVectors(tickets ...jira.JiraIssue) ([]string, [][]float64) {
+	columns := make([]string, len(featureColumns))
+	copy(columns, featureColumns)
+	rows := make([][]float64, len(tickets))
+	for i, ticket := range tickets {
+		sentiment, grammar, priority, ttc := math.NaN(), math.NaN(), math.NaN(), math.NaN()
+		if ticket.Sentiment.HasScore {
+			sentiment = ticket.Sentiment.Score
+		}
+		if ticket.GrammarCorrectness.HasScore {
+			grammar = float64(ticket.GrammarCorrectness.Score)
+		}
+		if id, err := strconv.Atoi(ticket.Fields.Priority.ID); err == nil {
+			priority = float64(id)
+		}
+		if ticket.TimeToClose > 0 {
+			ttc = ticket.TimeToClose
+		}
+		rows[i] = []float64{
+			float64(SummaryDescWords(ticket)),
+			float64(CommentWords(ticket)),
+			float64(len(ticket.Fields.Attachments)),
+			boolToFloat(ticket.HasStackTrace),
+			boolToFloat(ticket.HasStepsToReproduce),
+			sentiment,
+			grammar,
+			priority,
+			ttc,
+		}
+	}
+	return columns, rows
+}
+
+// boolToFloat encodes a boolean as 1 if true and 0 otherwise.
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
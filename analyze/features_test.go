@@ -0,0 +1,42 @@
+package analyze
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestFeatureVectors(t *testing.T) {
+	scored := jira.JiraIssue{Key: "F-1", TimeToClose: 12, HasStackTrace: true}
+	scored.Fields.Summary = "crash on save"
+	scored.Fields.Description = "the editor crashes"
+	scored.Fields.Comments.Comments = []jira.Comment{{ID: "1", Body: "cannot reproduce"}}
+	scored.Fields.Attachments = []jira.Attachment{{Type: jira.ImageAttachment}, {Type: jira.TextAttachment}}
+	scored.Fields.Priority.ID = "2"
+	scored.Sentiment = jira.Sentiment{Score: -0.5, HasScore: true}
+	scored.GrammarCorrectness = jira.GrammarCorrectness{Score: 3, HasScore: true}
+	unscored := jira.JiraIssue{Key: "F-2", HasStepsToReproduce: true}
+	unscored.Fields.Summary = "typo"
+
+	columns, rows := FeatureVectors(scored, unscored)
+	wantColumns := []string{"summary_desc_words", "comment_words", "attachment_count", "has_stack_trace",
+		"has_steps_to_reproduce", "sentiment", "grammar_errors", "priority", "time_to_close"}
+	if !reflect.DeepEqual(columns, wantColumns) {
+		t.Fatalf("columns = %v, want %v", columns, wantColumns)
+	}
+	if want := []float64{6, 2, 2, 1, 0, -0.5, 3, 2, 12}; !reflect.DeepEqual(rows[0], want) {
+		t.Errorf("features of a scored ticket = %v, want %v", rows[0], want)
+	}
+	for i, want := range []float64{1, 0, 0, 0, 1} {
+		if rows[1][i] != want {
+			t.Errorf("feature %s of an unscored ticket = %v, want %v", columns[i], rows[1][i], want)
+		}
+	}
+	for _, i := range []int{5, 6, 7, 8} {
+		if !math.IsNaN(rows[1][i]) {
+			t.Errorf("missing feature %s encoded as %v, want NaN", columns[i], rows[1][i])
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package analyze
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// MetricPoint holds the metrics of the tickets resolved during a time bucket, indexed by name.
+type MetricPoint struct {
+	Time   time.Time
+	Fields map[string]float64
+}
+
+// GrafanaSeries holds a time series in the shape served by Grafana JSON API datasources, each datapoint being
+// a value followed by its Unix time in milliseconds.
+type GrafanaSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// MetricTimeSeries groups the resolved tickets into time buckets by their resolution time and returns, for each
+// bucket holding any of them in chronological order, the number of tickets resolved during it along with the
+// Aggregate time-to-close and coverage metrics of those tickets. The latter are left out of buckets without any
+// ticket counted by Aggregate.
+func MetricTimeSeries(bucket time.Duration, tickets ...jira.JiraIssue) []MetricPoint {
+	if bucket <= 0 {
+		return nil
+	}
+	buckets := make(map[time.Time][]jira.JiraIssue)
+	for _, ticket := range tickets {
+		if resolvedAt, ok := resolutionTime(ticket); ok {
+			start := resolvedAt.Truncate(bucket)
+			buckets[start] = append(buckets[start], ticket)
+		}
+	}
+	points := make([]MetricPoint, 0, len(buckets))
+	for start, resolved := range buckets {
+		agg := Aggregate(resolved...)
+		fields := map[string]float64{
+			"resolved": float64(len(resolved)),
+			"closed":   float64(agg.Closed),
+		}
+		if agg.Closed > 0 {
+			fields["mean_time_to_close"] = agg.MeanTimeToClose
+			fields["attachments_coverage"] = agg.AttachmentsCoverage
+			fields["steps_to_reproduce_coverage"] = agg.StepsToReproduceCoverage
+			fields["stack_traces_coverage"] = agg.StackTracesCoverage
+			fields["sentiment_coverage"] = agg.SentimentCoverage
+			fields["grammar_coverage"] = agg.GrammarCoverage
+		}
+		points = append(points, MetricPoint{Time: start, Fields: fields})
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Time.Before(points[j].Time)
+	})
+	return points
+}
+
+// WriteLineProtocol writes the points as InfluxDB line protocol under the given measurement, one line per point
+// with its fields sorted by name and a nanosecond timestamp, for Grafana InfluxDB datasources.
+func WriteLineProtocol(w io.Writer, measurement string, points []MetricPoint) error {
+	measurement = strings.NewReplacer(",", `\,`, " ", `\ `).Replace(measurement)
+	for _, point := range points {
+		names := make([]string, 0, len(point.Fields))
+		for name := range point.Fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fields := make([]string, len(names))
+		for i, name := range names {
+			fields[i] = fmt.Sprintf("%s=%g", name, point.Fields[name])
+		}
+		if _, err := fmt.Fprintf(w, "%s %s %d\n", measurement, strings.Join(fields, ","),
+			point.Time.UnixNano()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteGrafanaJSON writes the points as a JSON array of GrafanaSeries, one per metric sorted by name.
+func WriteGrafanaJSON(w io.Writer, points []MetricPoint) error {
+	datapoints := make(map[string][][2]float64)
+	for _, point := range points {
+		ms := float64(point.Time.UnixNano() / int64(time.Millisecond))
+		for name, value := range point.Fields {
+			datapoints[name] = append(datapoints[name], [2]float64{value, ms})
+		}
+	}
+	series := make([]GrafanaSeries, 0, len(datapoints))
+	for name, values := range datapoints {
+		series = append(series, GrafanaSeries{Target: name, Datapoints: values})
+	}
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Target < series[j].Target
+	})
+	return json.NewEncoder(w).Encode(series)
+}
@@ -0,0 +1,66 @@
+package analyze
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestMetricTimeSeries(t *testing.T) {
+	resolved := func(key string, hours float64) jira.JiraIssue {
+		ticket := ticketWith(key, "Closed", transition{hours, "Open", "Closed"})
+		ticket.TimeToClose = hours
+		return ticket
+	}
+	documented := resolved("G-1", 10)
+	documented.HasStepsToReproduce = true
+	// a low priority ticket only counts as resolved, since Aggregate leaves it out.
+	low := resolved("G-3", 29)
+	low.Fields.Priority.ID = "5"
+
+	points := MetricTimeSeries(24*time.Hour, low, documented, resolved("G-2", 20), ticketWith("G-4", "Open"))
+	want := []MetricPoint{
+		{Time: time.Time(at(1, 0)), Fields: map[string]float64{
+			"resolved": 2, "closed": 2, "mean_time_to_close": 15, "attachments_coverage": 0,
+			"steps_to_reproduce_coverage": 0.5, "stack_traces_coverage": 0, "sentiment_coverage": 0,
+			"grammar_coverage": 0,
+		}},
+		{Time: time.Time(at(2, 0)), Fields: map[string]float64{"resolved": 1, "closed": 0}},
+	}
+	if !reflect.DeepEqual(points, want) {
+		t.Fatalf("MetricTimeSeries = %+v, want %+v", points, want)
+	}
+
+	var lines bytes.Buffer
+	if err := WriteLineProtocol(&lines, "ticket metrics", points); err != nil {
+		t.Fatalf("could not write line protocol: %v", err)
+	}
+	wantLines := `ticket\ metrics attachments_coverage=0,closed=2,grammar_coverage=0,mean_time_to_close=15,resolved=2,` +
+		"sentiment_coverage=0,stack_traces_coverage=0,steps_to_reproduce_coverage=0.5 1519862400000000000\n" +
+		`ticket\ metrics closed=0,resolved=1 1519948800000000000` + "\n"
+	if lines.String() != wantLines {
+		t.Errorf("line protocol = %q, want %q", lines.String(), wantLines)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteGrafanaJSON(&buf, points); err != nil {
+		t.Fatalf("could not write Grafana JSON: %v", err)
+	}
+	var series []GrafanaSeries
+	if err := json.Unmarshal(buf.Bytes(), &series); err != nil {
+		t.Fatalf("could not decode Grafana JSON: %v", err)
+	}
+	if len(series) != 8 || series[1].Target != "closed" || series[3].Target != "mean_time_to_close" {
+		t.Fatalf("Grafana series = %+v, want the 8 metrics sorted by name", series)
+	}
+	if want := [][2]float64{{2, 1519862400000}, {0, 1519948800000}}; !reflect.DeepEqual(series[1].Datapoints, want) {
+		t.Errorf("closed datapoints = %v, want %v", series[1].Datapoints, want)
+	}
+	if want := [][2]float64{{15, 1519862400000}}; !reflect.DeepEqual(series[3].Datapoints, want) {
+		t.Errorf("mean time-to-close datapoints = %v, want %v", series[3].Datapoints, want)
+	}
+}
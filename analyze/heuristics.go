@@ -0,0 +1,77 @@
+package analyze
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// Heuristic defines a named regular expression evaluated against a ticket's summary, description and comments.
+type Heuristic struct {
+	Name  string
+	Regex *regexp.Regexp
+}
+
+// LoadHeuristics reads custom heuristics from a JSON file mapping heuristic names to regular expressions, e.g.
+// {"affects_production": "(?i)affects production"}. All invalid regular expressions are reported at once.
+func LoadHeuristics(path string) ([]Heuristic, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read heuristics file: %v", err)
+	}
+	var exprs map[string]string
+	if err := json.Unmarshal(content, &exprs); err != nil {
+		return nil, fmt.Errorf("could not parse heuristics file %s: %v", path, err)
+	}
+	names := make([]string, 0, len(exprs))
+	for name := range exprs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	heuristics := make([]Heuristic, 0, len(names))
+	var problems []string
+	for _, name := range names {
+		regex, err := regexp.Compile(exprs[name])
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		heuristics = append(heuristics, Heuristic{Name: name, Regex: regex})
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid heuristics in %s:\n  %s", path, strings.Join(problems, "\n  "))
+	}
+	return heuristics, nil
+}
+
+// CustomHeuristics returns an analysis setting, for each heuristic, a flag under its name inside the CustomFlags
+// of a ticket stating whether its summary, description or any of its comments match the heuristic's regex.
+func CustomHeuristics(heuristics []Heuristic) TicketAnalysis {
+	return func(tickets ...jira.JiraIssue) {
+		for i := range tickets {
+			if !isTicketHighPriority(tickets[i]) {
+				continue
+			}
+			texts := []string{tickets[i].Fields.Summary, tickets[i].Fields.Description}
+			for _, comment := range tickets[i].Fields.Comments.Comments {
+				texts = append(texts, comment.Body)
+			}
+			flags := make(map[string]bool, len(heuristics))
+			for _, h := range heuristics {
+				flags[h.Name] = false
+				for _, text := range texts {
+					if h.Regex.MatchString(text) {
+						flags[h.Name] = true
+						break
+					}
+				}
+			}
+			tickets[i].CustomFlags = flags
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package analyze
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// writeHeuristics writes content to a heuristics file inside a new temporary directory and returns its path
+// along with a function removing it.
+func writeHeuristics(t *testing.T, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "heuristics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "heuristics.json")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestCustomHeuristics(t *testing.T) {
+	path, remove := writeHeuristics(t, `{"affects_production": "(?i)affects production",
+		"customer_reported": "(?i)reported by (a )?customer"}`)
+	defer remove()
+	heuristics, err := LoadHeuristics(path)
+	if err != nil {
+		t.Fatalf("could not load heuristics: %v", err)
+	}
+	if len(heuristics) != 2 || heuristics[0].Name != "affects_production" || heuristics[1].Name != "customer_reported" {
+		t.Fatalf("loaded heuristics %v", heuristics)
+	}
+
+	production := jira.JiraIssue{Key: "H-1"}
+	production.Fields.Priority.ID = "1"
+	production.Fields.Description = "This Affects Production since last night"
+	reported := jira.JiraIssue{Key: "H-2"}
+	reported.Fields.Priority.ID = "2"
+	reported.Fields.Comments.Comments = []jira.Comment{{Body: "Reported by a customer on the forum"}}
+	tickets := []jira.JiraIssue{production, reported}
+	CustomHeuristics(heuristics)(tickets...)
+
+	for i, want := range []map[string]bool{
+		{"affects_production": true, "customer_reported": false},
+		{"affects_production": false, "customer_reported": true},
+	} {
+		if !reflect.DeepEqual(tickets[i].CustomFlags, want) {
+			t.Errorf("flags of %s = %v, want %v", tickets[i].Key, tickets[i].CustomFlags, want)
+		}
+	}
+}
+
+func TestLoadHeuristicsReportsInvalidRegexes(t *testing.T) {
+	path, remove := writeHeuristics(t, `{"broken": "(unclosed", "also_broken": "[z-a]", "fine": "ok"}`)
+	defer remove()
+	_, err := LoadHeuristics(path)
+	if err == nil || !strings.Contains(err.Error(), "broken") || !strings.Contains(err.Error(), "also_broken") {
+		t.Errorf("LoadHeuristics error = %v, want both invalid heuristics reported", err)
+	}
+}
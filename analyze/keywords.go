@@ -0,0 +1,173 @@
+package analyze
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+const (
+	// DistinctiveKeywords defines the number of highest TF-IDF keywords of a ticket compared by KeywordClusters.
+	DistinctiveKeywords = 10
+	// minKeywordLength defines the minimum number of characters of a word considered a keyword.
+	minKeywordLength = 3
+)
+
+// TFIDF returns, indexed by ticket key, the TF-IDF weight of every word of each ticket's summary and description
+// across all given tickets. Words found in every ticket weigh 0.
+func TFIDF(tickets ...jira.JiraIssue) map[string]map[string]float64 {
+	counts := make(map[string]map[string]int, len(tickets))
+	documents := make(map[string]int)
+	for _, ticket := range tickets {
+		terms := make(map[string]int)
+		for _, word := range keywordTokens(ticket.Fields.Summary + " " + ticket.Fields.Description) {
+			terms[word]++
+		}
+		counts[ticket.Key] = terms
+		for word := range terms {
+			documents[word]++
+		}
+	}
+	weights := make(map[string]map[string]float64, len(counts))
+	for key, terms := range counts {
+		var total int
+		for _, n := range terms {
+			total += n
+		}
+		weights[key] = make(map[string]float64, len(terms))
+		for word, n := range terms {
+			idf := math.Log(float64(len(counts)) / float64(documents[word]))
+			weights[key][word] = float64(n) / float64(total) * idf
+		}
+	}
+	return weights
+}
+
+// KeywordClusters groups the keys of the tickets sharing at least minShared of their DistinctiveKeywords highest
+// TF-IDF keywords, directly or through other tickets of the cluster. Each cluster is labelled with the minShared
+// keywords most of its tickets have in common; tickets not sharing keywords with any other one are left out.
+func KeywordClusters(minShared int, tickets ...jira.JiraIssue) map[string][]string {
+	if minShared <= 0 {
+		minShared = 1
+	}
+	keywords := make(map[string]map[string]bool)
+	var keys []string
+	for key, weights := range TFIDF(tickets...) {
+		keywords[key] = topKeywords(weights, DistinctiveKeywords)
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parent := make(map[string]string, len(keys))
+	var find func(key string) string
+	find = func(key string) string {
+		if parent[key] != key {
+			parent[key] = find(parent[key])
+		}
+		return parent[key]
+	}
+	for _, key := range keys {
+		parent[key] = key
+	}
+	for i := range keys {
+		for j := i + 1; j < len(keys); j++ {
+			var shared int
+			for word := range keywords[keys[i]] {
+				if keywords[keys[j]][word] {
+					shared++
+				}
+			}
+			if shared >= minShared {
+				parent[find(keys[j])] = find(keys[i])
+			}
+		}
+	}
+
+	members := make(map[string][]string)
+	for _, key := range keys {
+		root := find(key)
+		members[root] = append(members[root], key)
+	}
+	var roots []string
+	for root, cluster := range members {
+		if len(cluster) > 1 {
+			roots = append(roots, root)
+		}
+	}
+	sort.Strings(roots)
+	clusters := make(map[string][]string, len(roots))
+	for _, root := range roots {
+		label := clusterLabel(minShared, keywords, members[root])
+		if _, taken := clusters[label]; taken {
+			label = fmt.Sprintf("%s (%s)", label, root)
+		}
+		clusters[label] = members[root]
+	}
+	return clusters
+}
+
+// clusterLabel joins the n keywords found among the keywords of most of the cluster's tickets.
+func clusterLabel(n int, keywords map[string]map[string]bool, cluster []string) string {
+	frequency := make(map[string]int)
+	for _, key := range cluster {
+		for word := range keywords[key] {
+			frequency[word]++
+		}
+	}
+	words := make([]string, 0, len(frequency))
+	for word := range frequency {
+		words = append(words, word)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if frequency[words[i]] != frequency[words[j]] {
+			return frequency[words[i]] > frequency[words[j]]
+		}
+		return words[i] < words[j]
+	})
+	if len(words) > n {
+		words = words[:n]
+	}
+	return strings.Join(words, "+")
+}
+
+// topKeywords returns the set of the n words with the highest positive weights, ties broken alphabetically.
+func topKeywords(weights map[string]float64, n int) map[string]bool {
+	var words []string
+	for word, weight := range weights {
+		if weight > 0 {
+			words = append(words, word)
+		}
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if weights[words[i]] != weights[words[j]] {
+			return weights[words[i]] > weights[words[j]]
+		}
+		return words[i] < words[j]
+	})
+	if len(words) > n {
+		words = words[:n]
+	}
+	top := make(map[string]bool, len(words))
+	for _, word := range words {
+		top[word] = true
+	}
+	return top
+}
+
+// keywordTokens returns the lowercased words of s holding at least minKeywordLength characters and at least
+// one letter.
+func keywordTokens(s string) []string {
+	var tokens []string
+	for _, token := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(token)) >= minKeywordLength && strings.IndexFunc(token, unicode.IsLetter) >= 0 {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,30 @@
+package analyze
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestKeywordClusters(t *testing.T) {
+	summarized := func(key, summary string) jira.JiraIssue {
+		ticket := jira.JiraIssue{Key: key}
+		ticket.Fields.Summary = summary
+		return ticket
+	}
+	tickets := []jira.JiraIssue{
+		summarized("K-1", "Payment gateway timeout"),
+		summarized("K-2", "payment gateway rejects card"),
+		summarized("K-3", "Dark mode colors"),
+		summarized("K-4", "Login button misaligned"),
+	}
+
+	want := map[string][]string{"gateway+payment": {"K-1", "K-2"}}
+	if got := KeywordClusters(2, tickets...); !reflect.DeepEqual(got, want) {
+		t.Errorf("KeywordClusters = %v, want %v", got, want)
+	}
+	if got := KeywordClusters(3, tickets...); len(got) != 0 {
+		t.Errorf("KeywordClusters sharing 3 keywords = %v, want none", got)
+	}
+}
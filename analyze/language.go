@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"github.com/nclandrei/ticketguru/jira"
 	"io/ioutil"
+	"log"
 	"net"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
+	"unicode"
 
 	language "cloud.google.com/go/language/apiv1"
 	languagepb "google.golang.org/genproto/googleapis/cloud/language/v1"
@@ -27,10 +29,116 @@ type Scorer interface {
 	Scores(...jira.JiraIssue) error
 }
 
+// Translator defines a service translating text into English so that English-only scorers can score it.
+type Translator interface {
+	Translate(text string) (string, error)
+}
+
+// ResponseRecorder defines a storage keeping the raw responses scorers get from external APIs for auditing.
+type ResponseRecorder interface {
+	PutResponse(ticketKey, scorer string, raw []byte) error
+}
+
+// Names the raw responses of the scorers are recorded under.
+const (
+	grammarScorerName   = "grammar"
+	sentimentScorerName = "sentiment"
+)
+
+// TextSelector defines the text of a ticket a scorer scores.
+type TextSelector func(jira.JiraIssue) string
+
+// textFields holds the ticket fields text selectors can be built from, indexed by name.
+var textFields = map[string]TextSelector{
+	"summary":     func(t jira.JiraIssue) string { return t.Fields.Summary },
+	"description": func(t jira.JiraIssue) string { return t.Fields.Description },
+	"environment": func(t jira.JiraIssue) string { return t.Fields.Environment },
+	"comments": func(t jira.JiraIssue) string {
+		bodies := make([]string, 0, len(t.Fields.Comments.Comments))
+		for _, comment := range uniqueComments(t) {
+			bodies = append(bodies, comment.Body)
+		}
+		return strings.Join(bodies, "\n")
+	},
+}
+
+// ParseTextSelector returns a selector joining, in order, the text of the + separated fields, e.g.
+// summary+description; available fields are summary, description, environment and comments.
+func ParseTextSelector(fields string) (TextSelector, error) {
+	var selectors []TextSelector
+	for _, name := range strings.Split(fields, "+") {
+		selector, ok := textFields[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("%s is not a valid text field; available fields are summary, description, "+
+				"environment and comments", name)
+		}
+		selectors = append(selectors, selector)
+	}
+	return func(ticket jira.JiraIssue) string {
+		texts := make([]string, len(selectors))
+		for i, selector := range selectors {
+			texts[i] = selector(ticket)
+		}
+		return strings.Join(texts, "\n")
+	}, nil
+}
+
+// WithoutJiraSyntax returns a selector stripping the Jira markup, e.g. mentions and wiki links, off the text
+// selected by selector.
+func WithoutJiraSyntax(selector TextSelector) TextSelector {
+	return func(ticket jira.JiraIssue) string {
+		return jira.StripJiraSyntax(selector(ticket))
+	}
+}
+
+// translate returns the text translated by translator or the text itself if there is no translator.
+func translate(translator Translator, text string) (string, error) {
+	if translator == nil {
+		return text, nil
+	}
+	translated, err := translator.Translate(text)
+	if err != nil {
+		return "", fmt.Errorf("could not translate text: %v", err)
+	}
+	return translated, nil
+}
+
+// truncationIndicator marks text truncated before being scored.
+const truncationIndicator = " [...]"
+
+// truncate returns text cut at the last word boundary so that, along with the truncation indicator, it holds
+// at most maxChars characters. Text within the cap, or any text if maxChars is not positive, is returned unchanged.
+func truncate(text string, maxChars int) string {
+	runes := []rune(text)
+	if maxChars <= 0 || len(runes) <= maxChars {
+		return text
+	}
+	limit := maxChars - len([]rune(truncationIndicator))
+	if limit <= 0 {
+		return string(runes[:maxChars])
+	}
+	cut := limit
+	for cut > 0 && !unicode.IsSpace(runes[cut]) {
+		cut--
+	}
+	if cut == 0 {
+		cut = limit
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace) + truncationIndicator
+}
+
 // BingClient defines a new Bing Spell Check client.
 type BingClient struct {
 	*http.Client
-	key string
+	ctx             context.Context
+	key             string
+	translator      Translator
+	maxChars        int
+	allowlist       map[string]bool
+	skipIdentifiers bool
+	selector        TextSelector
+	recorder        ResponseRecorder
+	sentences       bool
 }
 
 // BingResponse holds responses retrieved from Bing Spell Check API.
@@ -46,8 +154,8 @@ type BingFlaggedToken struct {
 	Type   string `json:"type"`
 }
 
-// NewBingClient returns a new Bing Spell Check API client.
-func NewBingClient(key string) *BingClient {
+// NewBingClient returns a new Bing Spell Check API client whose requests are cancelled once ctx is done.
+func NewBingClient(ctx context.Context, key string) *BingClient {
 	transport := &http.Transport{
 		Dial: (&net.Dialer{
 			Timeout:   60 * time.Second,
@@ -61,31 +169,164 @@ func NewBingClient(key string) *BingClient {
 	}
 	return &BingClient{
 		Client: client,
+		ctx:    ctx,
 		key:    key,
 	}
 }
 
+// SetTranslator makes the client translate text into English before scoring it; a nil translator disables translation.
+func (client *BingClient) SetTranslator(translator Translator) {
+	client.translator = translator
+}
+
+// SetMaxChars caps the number of characters of the text sent for scoring, truncating longer text at a word
+// boundary; a non-positive cap disables truncation.
+func (client *BingClient) SetMaxChars(maxChars int) {
+	client.maxChars = maxChars
+}
+
+// SetTextSelector makes the client score the text selected from each ticket instead of its summary and
+// description; a nil selector restores the default.
+func (client *BingClient) SetTextSelector(selector TextSelector) {
+	client.selector = selector
+}
+
+// SetSentenceBoundaries makes the client end every line of the text it scores, and hence every field and
+// comment, as a sentence before joining them, so that the grammar API does not score them as merged sentences.
+func (client *BingClient) SetSentenceBoundaries(sentences bool) {
+	client.sentences = sentences
+}
+
+// text returns the text of a ticket the client scores, with newlines replaced by spaces.
+func (client *BingClient) text(ticket jira.JiraIssue) string {
+	if client.sentences {
+		if client.selector != nil {
+			return joinSentences(client.selector(ticket))
+		}
+		return joinSentences(ticket.Fields.Summary, ticket.Fields.Description)
+	}
+	if client.selector != nil {
+		return concatAndRemoveNewlines(client.selector(ticket))
+	}
+	return concatAndRemoveNewlines(ticket.Fields.Summary, ticket.Fields.Description)
+}
+
+// joinSentences joins the non-blank lines of texts with spaces, ending each of them with a period unless it
+// already ends with terminal punctuation, whose repetitions (e.g. !!!) are collapsed into a single mark.
+func joinSentences(texts ...string) string {
+	var sentences []string
+	for _, text := range texts {
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				sentences = append(sentences, endSentence(line))
+			}
+		}
+	}
+	return strings.Join(sentences, " ")
+}
+
+// endSentence returns the sentence s ending with a single terminal punctuation mark, placed before any closing
+// quotes or brackets; lines ending with a colon or semicolon are left as they are.
+func endSentence(s string) string {
+	body := strings.TrimRight(s, `"')]`)
+	closing := s[len(body):]
+	marks := strings.TrimRight(body, ".!?")
+	switch {
+	case len(marks) < len(body):
+		return marks + body[len(marks):len(marks)+1] + closing
+	case strings.HasSuffix(body, ":") || strings.HasSuffix(body, ";"):
+		return s
+	default:
+		return body + "." + closing
+	}
+}
+
+// SetResponseRecorder makes the client record the raw response of every request it scores a ticket with; a nil
+// recorder disables recording.
+func (client *BingClient) SetResponseRecorder(recorder ResponseRecorder) {
+	client.recorder = recorder
+}
+
+// SetAllowlist makes the client not count the flagged tokens matching any of the given terms, e.g. product names
+// or usernames, case insensitively, as grammar errors.
+func (client *BingClient) SetAllowlist(terms []string) {
+	client.allowlist = make(map[string]bool, len(terms))
+	for _, term := range terms {
+		client.allowlist[strings.ToLower(strings.TrimSpace(term))] = true
+	}
+}
+
+// SetSkipIdentifiers makes the client not count the flagged tokens looking like code identifiers, i.e. camelCase
+// tokens or tokens holding digits or underscores, as grammar errors.
+func (client *BingClient) SetSkipIdentifiers(skip bool) {
+	client.skipIdentifiers = skip
+}
+
+// LoadAllowlist reads the terms of a grammar allowlist from a file holding one term per line, ignoring blank
+// lines and lines starting with #.
+func LoadAllowlist(path string) ([]string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read allowlist file: %v", err)
+	}
+	var terms []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		terms = append(terms, line)
+	}
+	return terms, nil
+}
+
+// grammarErrors returns the number of flagged tokens counting as grammar errors, i.e. those neither allowlisted
+// nor, if identifiers are skipped, looking like code identifiers.
+func (client *BingClient) grammarErrors(tokens []BingFlaggedToken) int {
+	var count int
+	for _, token := range tokens {
+		if client.allowlist[strings.ToLower(token.Token)] {
+			continue
+		}
+		if client.skipIdentifiers && isCodeIdentifier(token.Token) {
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+// isCodeIdentifier returns whether a token holds digits or underscores or is written in camelCase.
+func isCodeIdentifier(token string) bool {
+	var prev rune
+	for _, r := range token {
+		if unicode.IsDigit(r) || r == '_' {
+			return true
+		}
+		if unicode.IsUpper(r) && unicode.IsLower(prev) {
+			return true
+		}
+		prev = r
+	}
+	return false
+}
+
 // Scores returns the grammar correctness scores for all issues given as input parameters.
 func (client *BingClient) Scores(issues ...jira.JiraIssue) error {
 	errCh := make(chan error, len(issues))
-	var rateLimit int
-	if bingRateLimit > len(issues) {
-		rateLimit = len(issues)
-	} else {
-		rateLimit = bingRateLimit
-	}
-	for i := 0; i < len(issues); i += rateLimit {
-		for j := range issues[i:(i + rateLimit)] {
-			go func(i, j int) {
-				if issues[i+j].GrammarCorrectness.HasScore {
+	dispatched, ctxErr := inWindows(client.ctx, len(issues), bingRateLimit, time.Second, func(low, high int) {
+		for i := low; i < high; i++ {
+			go func(i int) {
+				if issues[i].GrammarCorrectness.HasScore {
 					errCh <- nil
 					return
 				}
-				strToAnalyze, err := concatAndRemoveNewlines(issues[i+j].Fields.Summary, issues[i+j].Fields.Description)
+				strToAnalyze, err := translate(client.translator, client.text(issues[i]))
 				if err != nil {
 					errCh <- err
 					return
 				}
+				strToAnalyze = truncate(strToAnalyze, client.maxChars)
 				values := url.Values{}
 				values.Set("Text", strToAnalyze)
 				req, err := http.NewRequest(
@@ -97,6 +338,7 @@ func (client *BingClient) Scores(issues ...jira.JiraIssue) error {
 					errCh <- err
 					return
 				}
+				req = req.WithContext(client.ctx)
 				req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 				req.Header.Add("Ocp-Apim-Subscription-Key", client.key)
 				resp, err := client.Do(req)
@@ -110,37 +352,72 @@ func (client *BingClient) Scores(issues ...jira.JiraIssue) error {
 					errCh <- err
 					return
 				}
+				if client.recorder != nil {
+					if err := client.recorder.PutResponse(issues[i].Key, grammarScorerName, body); err != nil {
+						log.Printf("could not record grammar response of %s: %v\n", issues[i].Key, err)
+					}
+				}
 				bingResponse := &BingResponse{}
 				err = json.Unmarshal(body, bingResponse)
 				if err != nil {
 					errCh <- err
 					return
 				}
-				issues[i+j].GrammarCorrectness.Score = len(bingResponse.FlaggedTokens)
-				issues[i+j].GrammarCorrectness.HasScore = true
+				issues[i].GrammarCorrectness.Score = client.grammarErrors(bingResponse.FlaggedTokens)
+				issues[i].GrammarCorrectness.HasScore = true
 				errCh <- nil
-			}(i, j)
+			}(i)
 		}
-		time.Sleep(1 * time.Second)
-	}
+	})
 	var strBuilder strings.Builder
-	for i := 0; i < len(issues); i++ {
+	for i := 0; i < dispatched; i++ {
 		if err := <-errCh; err != nil {
 			strBuilder.WriteString("error while retrieving grammar scores: ")
 			strBuilder.WriteString(err.Error())
 			strBuilder.WriteRune('\n')
 		}
 	}
+	if ctxErr != nil {
+		return ctxErr
+	}
 	if strBuilder.Len() > 0 {
 		return fmt.Errorf(strBuilder.String())
 	}
 	return nil
 }
 
+// inWindows dispatches the requests scoring n issues in consecutive windows of at most size issues, calling
+// dispatch with the bounds of each window and waiting interval after it so as to respect the API's rate limit.
+// It stops dispatching once ctx is done, returning how many issues were dispatched along with ctx.Err().
+func inWindows(ctx context.Context, n, size int, interval time.Duration, dispatch func(low, high int)) (int, error) {
+	for low := 0; low < n; low += size {
+		if err := ctx.Err(); err != nil {
+			return low, err
+		}
+		high := low + size
+		if high > n {
+			high = n
+		}
+		dispatch(low, high)
+		select {
+		case <-ctx.Done():
+			if high < n {
+				return high, ctx.Err()
+			}
+		case <-time.After(interval):
+		}
+	}
+	return n, nil
+}
+
 // SentimentClient defines a GCP Language Client
 type SentimentClient struct {
 	*language.Client
-	ctx context.Context
+	ctx        context.Context
+	translator Translator
+	maxChars   int
+	selector   TextSelector
+	recorder   ResponseRecorder
 }
 
 // NewSentimentClient returns a new language clients alogn with its context
@@ -155,61 +432,131 @@ func NewSentimentClient(ctx context.Context) (*SentimentClient, error) {
 	}, nil
 }
 
+// SetTranslator makes the client translate text into English before scoring it; a nil translator disables translation.
+func (client *SentimentClient) SetTranslator(translator Translator) {
+	client.translator = translator
+}
+
+// SetMaxChars caps the number of characters of the text sent for scoring, truncating longer text at a word
+// boundary; a non-positive cap disables truncation.
+func (client *SentimentClient) SetMaxChars(maxChars int) {
+	client.maxChars = maxChars
+}
+
+// SetTextSelector makes the client score the text selected from each ticket instead of its comments; a nil
+// selector restores the default.
+func (client *SentimentClient) SetTextSelector(selector TextSelector) {
+	client.selector = selector
+}
+
+// SetResponseRecorder makes the client record the raw response of every request it scores a ticket with; a nil
+// recorder disables recording.
+func (client *SentimentClient) SetResponseRecorder(recorder ResponseRecorder) {
+	client.recorder = recorder
+}
+
+// text returns the text of a ticket the client scores.
+func (client *SentimentClient) text(ticket jira.JiraIssue) string {
+	if client.selector != nil {
+		return client.selector(ticket)
+	}
+	return concatComments(ticket)
+}
+
+// score returns the sentiment score of a text, truncated to the maximum number of characters, from GCP.
+func (client *SentimentClient) score(text string) (float64, error) {
+	sentiment, err := client.analyzeSentiment(text)
+	if err != nil {
+		return 0, err
+	}
+	return float64(sentiment.DocumentSentiment.Score), nil
+}
+
+// analyzeSentiment returns the GCP sentiment analysis of a text truncated to the maximum number of characters.
+func (client *SentimentClient) analyzeSentiment(text string) (*languagepb.AnalyzeSentimentResponse, error) {
+	return client.AnalyzeSentiment(client.ctx, &languagepb.AnalyzeSentimentRequest{
+		Document: &languagepb.Document{
+			Source: &languagepb.Document_Content{
+				Content: truncate(text, client.maxChars),
+			},
+			Type: languagepb.Document_PLAIN_TEXT,
+		},
+		EncodingType: languagepb.EncodingType_UTF8,
+	})
+}
+
+// CommentScores returns the sentiment score of each comment of a ticket, in order, after querying GCP.
+func (client *SentimentClient) CommentScores(ticket jira.JiraIssue) ([]float64, error) {
+	scores := make([]float64, len(ticket.Fields.Comments.Comments))
+	for i, comment := range ticket.Fields.Comments.Comments {
+		text, err := translate(client.translator, comment.Body)
+		if err != nil {
+			return nil, err
+		}
+		if scores[i], err = client.score(text); err != nil {
+			return nil, fmt.Errorf("could not score comment %s: %v", comment.ID, err)
+		}
+	}
+	return scores, nil
+}
+
 // Scores calculates the sentiment score for an issue's comments after querying GCP.
 func (client *SentimentClient) Scores(issues ...jira.JiraIssue) error {
 	errCh := make(chan error, len(issues))
-	var rateLimit int
-	if gcpRateLimit > len(issues) {
-		rateLimit = len(issues)
-	} else {
-		rateLimit = gcpRateLimit
-	}
-	highBound := rateLimit
-	for i := 0; i < len(issues); i += rateLimit {
-		if i+highBound > len(issues) {
-			highBound = len(issues) % rateLimit
-		}
-		for j := range issues[i:(i + highBound)] {
-			go func(i, j int) {
-				if issues[i+j].Sentiment.HasScore {
+	dispatched, ctxErr := inWindows(client.ctx, len(issues), gcpRateLimit, time.Minute, func(low, high int) {
+		for i := low; i < high; i++ {
+			go func(i int) {
+				if issues[i].Sentiment.HasScore {
 					errCh <- nil
 					return
 				}
-				concatComm := concatComments(issues[i+j])
-				sentiment, err := client.AnalyzeSentiment(client.ctx, &languagepb.AnalyzeSentimentRequest{
-					Document: &languagepb.Document{
-						Source: &languagepb.Document_Content{
-							Content: concatComm,
-						},
-						Type: languagepb.Document_PLAIN_TEXT,
-					},
-					EncodingType: languagepb.EncodingType_UTF8,
-				})
+				text, err := translate(client.translator, client.text(issues[i]))
 				if err != nil {
 					errCh <- err
 					return
 				}
-				issues[i+j].Sentiment.HasScore = true
-				issues[i+j].Sentiment.Score = float64(sentiment.DocumentSentiment.Score)
+				sentiment, err := client.analyzeSentiment(text)
+				if err != nil {
+					errCh <- err
+					return
+				}
+				if client.recorder != nil {
+					client.record(issues[i].Key, sentiment)
+				}
+				issues[i].Sentiment.HasScore = true
+				issues[i].Sentiment.Score = float64(sentiment.DocumentSentiment.Score)
 				errCh <- nil
-			}(i, j)
+			}(i)
 		}
-		time.Sleep(1 * time.Minute)
-	}
+	})
 	var strBuilder strings.Builder
-	for i := 0; i < len(issues); i++ {
+	for i := 0; i < dispatched; i++ {
 		if err := <-errCh; err != nil {
 			strBuilder.WriteString("error while retrieving sentiment scores: ")
 			strBuilder.WriteString(err.Error())
 			strBuilder.WriteRune('\n')
 		}
 	}
+	if ctxErr != nil {
+		return ctxErr
+	}
 	if strBuilder.Len() > 0 {
 		return fmt.Errorf(strBuilder.String())
 	}
 	return nil
 }
 
+// record stores the JSON encoding of a sentiment analysis of a ticket with the client's recorder.
+func (client *SentimentClient) record(ticketKey string, sentiment *languagepb.AnalyzeSentimentResponse) {
+	raw, err := json.Marshal(sentiment)
+	if err == nil {
+		err = client.recorder.PutResponse(ticketKey, sentimentScorerName, raw)
+	}
+	if err != nil {
+		log.Printf("could not record sentiment response of %s: %v\n", ticketKey, err)
+	}
+}
+
 // MultipleScores takes multiple issues and scorers and returns a map for each scorer to its corresponding scores.
 func MultipleScores(issues []jira.JiraIssue, scorers ...Scorer) error {
 	errCh := make(chan error, len(scorers))
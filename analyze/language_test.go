@@ -0,0 +1,276 @@
+package analyze
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// fakeTranslator translates every text into English by looking it up, ignoring surrounding spaces, and fails
+// for unknown texts.
+type fakeTranslator map[string]string
+
+func (t fakeTranslator) Translate(text string) (string, error) {
+	translated, ok := t[strings.TrimSpace(text)]
+	if !ok {
+		return "", errors.New("unknown text")
+	}
+	return translated, nil
+}
+
+// roundTripperFunc answers HTTP requests by calling itself.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestBingClientScoresTranslatedText(t *testing.T) {
+	var mu sync.Mutex
+	var scored []string
+	client := NewBingClient(context.Background(), "key")
+	client.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, err
+		}
+		mu.Lock()
+		scored = append(scored, values.Get("Text"))
+		mu.Unlock()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"flaggedTokens": [{"token": "pas"}]}`)),
+		}, nil
+	})
+	client.SetTranslator(fakeTranslator{"Rien ne marche": "Nothing works"})
+
+	ticket := jira.JiraIssue{Key: "T-1"}
+	ticket.Fields.Summary = "Rien ne marche"
+	tickets := []jira.JiraIssue{ticket}
+	if err := client.Scores(tickets...); err != nil {
+		t.Fatalf("could not score tickets: %v", err)
+	}
+	if len(scored) != 1 || scored[0] != "Nothing works" {
+		t.Errorf("scored text %q, want the translation %q", scored, "Nothing works")
+	}
+	if !tickets[0].GrammarCorrectness.HasScore || tickets[0].GrammarCorrectness.Score != 1 {
+		t.Errorf("grammar correctness = %+v, want one error", tickets[0].GrammarCorrectness)
+	}
+
+	client.SetTranslator(fakeTranslator{})
+	untranslatable := []jira.JiraIssue{ticket}
+	if err := client.Scores(untranslatable...); err == nil {
+		t.Error("expected an error for text the translator cannot translate")
+	}
+	if untranslatable[0].GrammarCorrectness.HasScore {
+		t.Error("a ticket was scored despite its text not being translated")
+	}
+}
+
+func TestBingClientStopsOnceContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := NewBingClient(ctx, "key")
+	client.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if err := req.Context().Err(); err != nil {
+			return nil, err
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{}`))}, nil
+	})
+
+	tickets := []jira.JiraIssue{{Key: "T-1"}}
+	if err := client.Scores(tickets...); err == nil || !strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Errorf("Scores once the context is done returned %v, want it cancelled", err)
+	}
+	if tickets[0].GrammarCorrectness.HasScore {
+		t.Error("a ticket was scored after the context was done")
+	}
+}
+
+func TestBingClientScoresOnlyItsBatch(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	client := NewBingClient(context.Background(), "key")
+	client.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{}`))}, nil
+	})
+
+	// the batch's capacity runs into the next batch, as with the batches of cmd/analyze.
+	tickets := make([]jira.JiraIssue, 200)
+	batch := tickets[:150]
+	if err := client.Scores(batch...); err != nil {
+		t.Fatalf("could not score tickets: %v", err)
+	}
+	if requests != 150 {
+		t.Errorf("sent %d requests, want one per ticket of the batch", requests)
+	}
+	for i := range tickets {
+		if scored := tickets[i].GrammarCorrectness.HasScore; scored != (i < 150) {
+			t.Errorf("ticket %d scored = %v, want only the batch's tickets scored", i, scored)
+			break
+		}
+	}
+}
+
+func TestSentimentClientStopsWaitingAtDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	client := &SentimentClient{ctx: ctx}
+	// the first window is already scored, so that the client only waits for the rate limit before the second one.
+	tickets := make([]jira.JiraIssue, gcpRateLimit+1)
+	for i := 0; i < gcpRateLimit; i++ {
+		tickets[i].Sentiment.HasScore = true
+	}
+
+	start := time.Now()
+	if err := client.Scores(tickets...); err != context.DeadlineExceeded {
+		t.Errorf("Scores past the deadline returned %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Scores returned after %v, want it to stop waiting at the deadline", elapsed)
+	}
+	if tickets[gcpRateLimit].Sentiment.HasScore {
+		t.Error("a ticket was scored after the deadline")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	for _, tc := range []struct {
+		text     string
+		maxChars int
+		want     string
+	}{
+		{"the quick brown fox jumps", 0, "the quick brown fox jumps"},
+		{"the quick", 9, "the quick"},
+		{"the quick", 20, "the quick"},
+		{"the quick brown fox jumps", 16, "the quick [...]"},
+		{"supercalifragilistic", 12, "superc [...]"},
+	} {
+		if got := truncate(tc.text, tc.maxChars); got != tc.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tc.text, tc.maxChars, got, tc.want)
+		}
+	}
+}
+
+func TestBingClientSkipsAllowlistedTermsAndIdentifiers(t *testing.T) {
+	client := NewBingClient(context.Background(), "key")
+	client.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body: ioutil.NopCloser(strings.NewReader(`{"flaggedTokens": [{"token": "Jira"}, {"token": "userId"}, ` +
+				`{"token": "max_retries"}, {"token": "v2"}, {"token": "teh"}]}`)),
+		}, nil
+	})
+	score := func() int {
+		t.Helper()
+		ticket := jira.JiraIssue{Key: "G-1"}
+		ticket.Fields.Summary = "teh Jira sync sends userId and max_retries to v2"
+		tickets := []jira.JiraIssue{ticket}
+		if err := client.Scores(tickets...); err != nil {
+			t.Fatalf("could not score tickets: %v", err)
+		}
+		return tickets[0].GrammarCorrectness.Score
+	}
+
+	if got := score(); got != 5 {
+		t.Errorf("grammar errors without allowlist = %d, want all 5 flagged tokens", got)
+	}
+	client.SetAllowlist([]string{" jira "})
+	client.SetSkipIdentifiers(true)
+	if got := score(); got != 1 {
+		t.Errorf("grammar errors = %d, want only the misspelling to count", got)
+	}
+
+	for token, want := range map[string]bool{
+		"userId": true, "max_retries": true, "v2": true, "Jira": false, "HTTP": false, "teh": false,
+	} {
+		if got := isCodeIdentifier(token); got != want {
+			t.Errorf("isCodeIdentifier(%q) = %v, want %v", token, got, want)
+		}
+	}
+}
+
+func TestTextSelectors(t *testing.T) {
+	ticket := jira.JiraIssue{Key: "S-1"}
+	ticket.Fields.Summary = "Save fails"
+	ticket.Fields.Description = "crashes\non save"
+	ticket.Fields.Comments.Comments = []jira.Comment{{Body: "same here"}, {Body: "fixed?"}}
+	selector := func(fields string) TextSelector {
+		t.Helper()
+		s, err := ParseTextSelector(fields)
+		if err != nil {
+			t.Fatalf("could not parse %q: %v", fields, err)
+		}
+		return s
+	}
+
+	grammar := NewBingClient(context.Background(), "key")
+	if got := grammar.text(ticket); got != "Save fails crashes on save " {
+		t.Errorf("default grammar text = %q, want the summary and description", got)
+	}
+	grammar.SetTextSelector(selector("description"))
+	if got := grammar.text(ticket); got != "crashes on save " {
+		t.Errorf("grammar text = %q, want only the description", got)
+	}
+
+	sentiment := &SentimentClient{}
+	if got := sentiment.text(ticket); got != "same here\nfixed?" {
+		t.Errorf("default sentiment text = %q, want the comments", got)
+	}
+	sentiment.SetTextSelector(selector("summary + description"))
+	if got := sentiment.text(ticket); got != "Save fails\ncrashes\non save" {
+		t.Errorf("sentiment text = %q, want only the summary and description", got)
+	}
+	sentiment.SetTextSelector(selector("comments"))
+	if got := sentiment.text(ticket); got != "same here\nfixed?" {
+		t.Errorf("sentiment text = %q, want only the comments", got)
+	}
+
+	if _, err := ParseTextSelector("summary+title"); err == nil {
+		t.Error("expected an error for an unknown text field")
+	}
+}
+
+func TestJoinSentences(t *testing.T) {
+	for _, tc := range []struct {
+		texts []string
+		want  string
+	}{
+		{[]string{"Save fails", "It crashes!!!\n\nSteps:"}, "Save fails. It crashes! Steps:"},
+		{[]string{`He said "done"`, "Really?!"}, `He said "done." Really?`},
+		// correctly punctuated text is left unchanged.
+		{[]string{"Save fails.", "Does it work? Yes (it does)."}, "Save fails. Does it work? Yes (it does)."},
+	} {
+		if got := joinSentences(tc.texts...); got != tc.want {
+			t.Errorf("joinSentences(%q) = %q, want %q", tc.texts, got, tc.want)
+		}
+	}
+
+	ticket := jira.JiraIssue{Key: "J-1"}
+	ticket.Fields.Comments.Comments = []jira.Comment{{Body: "same here"}, {Body: "still broken"}}
+	client := NewBingClient(context.Background(), "key")
+	comments, err := ParseTextSelector("comments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetTextSelector(comments)
+	client.SetSentenceBoundaries(true)
+	if got := client.text(ticket); got != "same here. still broken." {
+		t.Errorf("grammar text = %q, want the comments as separate sentences", got)
+	}
+}
@@ -0,0 +1,205 @@
+package analyze
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// OutlierBounds holds the range outside of which values are considered outliers.
+type OutlierBounds struct {
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// Contains returns whether a value lies within the bounds.
+func (b OutlierBounds) Contains(v float64) bool {
+	return v >= b.Lower && v <= b.Upper
+}
+
+// IQRBounds computes the Tukey outlier bounds of a set of values, i.e. 1.5 interquartile ranges below the
+// first quartile and above the third one.
+func IQRBounds(values []float64) OutlierBounds {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	q1, q3 := quantile(sorted, 0.25), quantile(sorted, 0.75)
+	iqr := q3 - q1
+	return OutlierBounds{
+		Lower: q1 - 1.5*iqr,
+		Upper: q3 + 1.5*iqr,
+	}
+}
+
+// TimeToCloseBounds computes the IQR outlier bounds of the times-to-close of all closed tickets.
+func TimeToCloseBounds(tickets ...jira.JiraIssue) OutlierBounds {
+	var times []float64
+	for _, t := range tickets {
+		if t.TimeToClose > 0 {
+			times = append(times, t.TimeToClose)
+		}
+	}
+	return IQRBounds(times)
+}
+
+// ExcludeTimeToCloseOutliers returns the tickets which are either not closed or whose time-to-close lies
+// within the given bounds.
+func ExcludeTimeToCloseOutliers(bounds OutlierBounds, tickets ...jira.JiraIssue) []jira.JiraIssue {
+	var result []jira.JiraIssue
+	for _, t := range tickets {
+		if t.TimeToClose <= 0 || bounds.Contains(t.TimeToClose) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// quantile returns the q-th quantile of sorted values using linear interpolation between closest ranks.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	pos := q * float64(len(sorted)-1)
+	low := int(math.Floor(pos))
+	high := int(math.Ceil(pos))
+	return sorted[low] + (sorted[high]-sorted[low])*(pos-float64(low))
+}
+
+// GroupKey defines a function returning the group a ticket is compared within, e.g. its priority.
+type GroupKey func(jira.JiraIssue) string
+
+// ByPriority groups tickets by the name of their priority.
+func ByPriority(t jira.JiraIssue) string {
+	return t.Fields.Priority.Name
+}
+
+// ByIssueType groups tickets by the name of their issue type.
+func ByIssueType(t jira.JiraIssue) string {
+	return t.Fields.Type.Name
+}
+
+// ResolutionZScores returns, indexed by ticket key, the z-score of each closed ticket's time-to-close relative to
+// the closed tickets of the same priority.
+func ResolutionZScores(tickets ...jira.JiraIssue) map[string]float64 {
+	return ResolutionZScoresBy(ByPriority, tickets...)
+}
+
+// ResolutionZScoresBy returns, indexed by ticket key, the z-score of each closed ticket's time-to-close relative
+// to the closed tickets of the same group. Groups with fewer than two tickets or without any variation in their
+// times-to-close are skipped.
+func ResolutionZScoresBy(group GroupKey, tickets ...jira.JiraIssue) map[string]float64 {
+	groups := make(map[string][]jira.JiraIssue)
+	for _, ticket := range tickets {
+		if ticket.TimeToClose > 0 {
+			groups[group(ticket)] = append(groups[group(ticket)], ticket)
+		}
+	}
+	scores := make(map[string]float64)
+	for _, members := range groups {
+		if len(members) < 2 {
+			continue
+		}
+		var mean float64
+		for _, t := range members {
+			mean += t.TimeToClose
+		}
+		mean /= float64(len(members))
+		var variance float64
+		for _, t := range members {
+			variance += math.Pow(t.TimeToClose-mean, 2)
+		}
+		stdDev := math.Sqrt(variance / float64(len(members)-1))
+		if stdDev == 0 {
+			continue
+		}
+		for _, t := range members {
+			scores[t.Key] = (t.TimeToClose - mean) / stdDev
+		}
+	}
+	return scores
+}
+
+const (
+	// SlowUrgentMismatch flags an urgent ticket whose time-to-close lies in the slowest quartile.
+	SlowUrgentMismatch = "slow urgent"
+	// FastMinorMismatch flags a minor ticket whose time-to-close lies in the fastest quartile.
+	FastMinorMismatch = "fast minor"
+)
+
+// Mismatch describes a closed ticket whose time-to-close is inconsistent with its priority.
+type Mismatch struct {
+	Key         string
+	Priority    string
+	TimeToClose float64
+	Reason      string
+}
+
+// PriorityResolutionMismatches returns, slowest first, the urgent tickets whose time-to-close lies above the third
+// quartile of all closed tickets and the minor ones whose time-to-close lies below the first quartile, for triage
+// review. Urgent tickets are the high priority ones, as told by jira.IsHighPriority, and minor tickets the other
+// ones with a priority.
+func PriorityResolutionMismatches(tickets ...jira.JiraIssue) []Mismatch {
+	var times []float64
+	for _, t := range tickets {
+		if t.TimeToClose > 0 {
+			times = append(times, t.TimeToClose)
+		}
+	}
+	if len(times) == 0 {
+		return nil
+	}
+	sort.Float64s(times)
+	q1, q3 := quantile(times, 0.25), quantile(times, 0.75)
+	var mismatches []Mismatch
+	for _, t := range tickets {
+		if t.TimeToClose <= 0 {
+			continue
+		}
+		var reason string
+		urgent := jira.IsHighPriority(t)
+		switch {
+		case urgent && t.TimeToClose > q3:
+			reason = SlowUrgentMismatch
+		case !urgent && t.Fields.Priority.ID != "" && t.TimeToClose < q1:
+			reason = FastMinorMismatch
+		default:
+			continue
+		}
+		mismatches = append(mismatches, Mismatch{
+			Key:         t.Key,
+			Priority:    t.Fields.Priority.Name,
+			TimeToClose: t.TimeToClose,
+			Reason:      reason,
+		})
+	}
+	sort.Slice(mismatches, func(i, j int) bool {
+		if mismatches[i].TimeToClose != mismatches[j].TimeToClose {
+			return mismatches[i].TimeToClose > mismatches[j].TimeToClose
+		}
+		return mismatches[i].Key < mismatches[j].Key
+	})
+	return mismatches
+}
+
+// TrimmedMean returns the mean of values once the trimPct fraction of the lowest and the trimPct fraction of the
+// highest of them are discarded, rounding the number discarded from each end down. trimPct must lie in [0, 0.5).
+func TrimmedMean(values []float64, trimPct float64) (float64, error) {
+	if trimPct < 0 || trimPct >= 0.5 {
+		return 0, fmt.Errorf("trim fraction must lie in [0, 0.5), got %v", trimPct)
+	}
+	if len(values) == 0 {
+		return 0, errors.New("cannot compute the trimmed mean of no values")
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	trim := int(float64(len(sorted)) * trimPct)
+	var total float64
+	for _, v := range sorted[trim : len(sorted)-trim] {
+		total += v
+	}
+	return total / float64(len(sorted)-2*trim), nil
+}
@@ -0,0 +1,91 @@
+package analyze
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestResolutionZScores(t *testing.T) {
+	newTicket := func(key, priority, issueType string, hours float64) jira.JiraIssue {
+		ticket := jira.JiraIssue{Key: key, TimeToClose: hours}
+		ticket.Fields.Priority.Name = priority
+		ticket.Fields.Type.Name = issueType
+		return ticket
+	}
+	tickets := []jira.JiraIssue{
+		newTicket("Z-1", "Blocker", "Bug", 10),
+		newTicket("Z-2", "Blocker", "Bug", 20),
+		newTicket("Z-3", "Blocker", "Task", 30),
+		newTicket("Z-4", "Blocker", "Task", 0),
+		// a group of a single ticket and a group without variation are skipped.
+		newTicket("Z-5", "Major", "Bug", 100),
+		newTicket("Z-6", "Minor", "Task", 5),
+		newTicket("Z-7", "Minor", "Task", 5),
+	}
+
+	want := map[string]float64{"Z-1": -1, "Z-2": 0, "Z-3": 1}
+	if got := ResolutionZScores(tickets...); !reflect.DeepEqual(got, want) {
+		t.Errorf("ResolutionZScores = %v, want %v", got, want)
+	}
+	byType := ResolutionZScoresBy(ByIssueType, tickets...)
+	if len(byType) != 6 || byType["Z-1"] >= 0 || byType["Z-5"] <= 0 || byType["Z-3"] <= 0 {
+		t.Errorf("ResolutionZScoresBy issue type = %v", byType)
+	}
+}
+
+func TestPriorityResolutionMismatches(t *testing.T) {
+	closed := func(key, id, priority string, hours float64) jira.JiraIssue {
+		ticket := jira.JiraIssue{Key: key, TimeToClose: hours}
+		ticket.Fields.Priority.ID, ticket.Fields.Priority.Name = id, priority
+		return ticket
+	}
+	// the times-to-close of the closed tickets have 20 hours as first quartile and 60 hours as third one.
+	got := PriorityResolutionMismatches(
+		closed("PM-1", "2", "Critical", 500),
+		closed("PM-2", "1", "Blocker", 10),
+		closed("PM-3", "5", "Trivial", 2),
+		// priority 4 counts as high priority, as everywhere else.
+		closed("PM-4", "4", "Minor", 70),
+		closed("PM-5", "2", "Critical", 60),
+		closed("PM-6", "3", "Major", 20),
+		closed("PM-7", "3", "Major", 30),
+		closed("PM-8", "3", "Major", 40),
+		closed("PM-9", "3", "Major", 50),
+		closed("PM-10", "1", "Blocker", 0),
+	)
+	want := []Mismatch{
+		{Key: "PM-1", Priority: "Critical", TimeToClose: 500, Reason: SlowUrgentMismatch},
+		{Key: "PM-4", Priority: "Minor", TimeToClose: 70, Reason: SlowUrgentMismatch},
+		{Key: "PM-3", Priority: "Trivial", TimeToClose: 2, Reason: FastMinorMismatch},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PriorityResolutionMismatches = %+v, want %+v", got, want)
+	}
+	if got := PriorityResolutionMismatches(closed("PM-11", "1", "Blocker", 0)); got != nil {
+		t.Errorf("PriorityResolutionMismatches without closed tickets = %+v, want none", got)
+	}
+}
+
+func TestTrimmedMean(t *testing.T) {
+	// a single slow outlier drags the raw mean far above the typical time-to-close.
+	values := []float64{1000, 3, 1, 2, 4, 5, 6, 7, 8, 9}
+	for trimPct, want := range map[float64]float64{0: 104.5, 0.1: 5.5, 0.15: 5.5, 0.2: 5.5} {
+		got, err := TrimmedMean(values, trimPct)
+		if err != nil || got != want {
+			t.Errorf("TrimmedMean(%v) = (%v, %v), want %v", trimPct, got, err, want)
+		}
+	}
+	if values[0] != 1000 {
+		t.Error("TrimmedMean sorted the given values in place")
+	}
+	for _, trimPct := range []float64{-0.1, 0.5} {
+		if _, err := TrimmedMean(values, trimPct); err == nil {
+			t.Errorf("expected an error for a trim fraction of %v", trimPct)
+		}
+	}
+	if _, err := TrimmedMean(nil, 0.1); err == nil {
+		t.Error("expected an error for no values")
+	}
+}
@@ -0,0 +1,141 @@
+package analyze
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// Step defines a named stage of a Pipeline, either a scorer calling an external service or a local analysis.
+type Step struct {
+	Name     string
+	Scorer   Scorer
+	Analysis TicketAnalysis
+}
+
+// run applies the step to the tickets.
+func (s Step) run(tickets ...jira.JiraIssue) error {
+	if s.Scorer != nil {
+		return s.Scorer.Scores(tickets...)
+	}
+	if s.Analysis != nil {
+		s.Analysis(tickets...)
+	}
+	return nil
+}
+
+// StepResult holds how long a step of a Pipeline run took and the error it failed with, if any.
+type StepResult struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// StepErrors holds the errors of all the failed steps of a Pipeline run.
+type StepErrors []error
+
+// Error returns the descriptions of all the step errors.
+func (e StepErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d pipeline steps failed:\n  %s", len(e), strings.Join(msgs, "\n  "))
+}
+
+// PipelineConfig defines, in order, the names of the steps a pipeline runs, e.g. {"steps": ["spam", "sentiment"]}.
+type PipelineConfig struct {
+	Steps []string `json:"steps"`
+}
+
+// LoadPipelineConfig reads a pipeline configuration from a JSON file.
+func LoadPipelineConfig(path string) (PipelineConfig, error) {
+	var cfg PipelineConfig
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("could not read pipeline file: %v", err)
+	}
+	if err := json.Unmarshal(content, &cfg); err != nil {
+		return cfg, fmt.Errorf("could not parse pipeline file %s: %v", path, err)
+	}
+	if len(cfg.Steps) == 0 {
+		return cfg, fmt.Errorf("pipeline file %s does not define any step", path)
+	}
+	return cfg, nil
+}
+
+// Pipeline runs an ordered list of steps on the same tickets, each step seeing the fields written by the
+// previous ones.
+type Pipeline struct {
+	steps []Step
+}
+
+// NewPipeline returns a pipeline running, in order, the steps named by cfg among the available ones. Unknown
+// and repeated step names are reported at once.
+func NewPipeline(cfg PipelineConfig, available map[string]Step) (*Pipeline, error) {
+	var steps []Step
+	var problems []string
+	seen := make(map[string]bool, len(cfg.Steps))
+	for _, name := range cfg.Steps {
+		step, ok := available[name]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("%s is not an available step", name))
+		case seen[name]:
+			problems = append(problems, fmt.Sprintf("%s is listed more than once", name))
+		default:
+			step.Name = name
+			steps = append(steps, step)
+		}
+		seen[name] = true
+	}
+	if len(problems) > 0 {
+		names := make([]string, 0, len(available))
+		for name := range available {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("invalid pipeline:\n  %s\navailable steps are %s", strings.Join(problems, "\n  "),
+			strings.Join(names, ", "))
+	}
+	return &Pipeline{steps: steps}, nil
+}
+
+// Steps returns the names of the steps of the pipeline in the order they run.
+func (p *Pipeline) Steps() []string {
+	names := make([]string, len(p.steps))
+	for i, step := range p.steps {
+		names[i] = step.Name
+	}
+	return names
+}
+
+// Run runs the steps of the pipeline in order on the tickets, carrying on after failed steps, and returns the
+// result of each step run alongside the errors of the failed ones as StepErrors. Once ctx is done, the
+// remaining steps are skipped and ctx's error is returned among the others.
+func (p *Pipeline) Run(ctx context.Context, tickets ...jira.JiraIssue) ([]StepResult, error) {
+	var results []StepResult
+	var errs StepErrors
+	for _, step := range p.steps {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		start := time.Now()
+		err := step.run(tickets...)
+		results = append(results, StepResult{Name: step.Name, Duration: time.Since(start), Err: err})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", step.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return results, errs
+	}
+	return results, nil
+}
@@ -0,0 +1,80 @@
+package analyze
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// recordingScorer records the name of every step run into a shared log and fails with err, if set.
+type recordingScorer struct {
+	name string
+	log  *[]string
+	err  error
+}
+
+func (s recordingScorer) Scores(tickets ...jira.JiraIssue) error {
+	*s.log = append(*s.log, s.name)
+	return s.err
+}
+
+func TestPipeline(t *testing.T) {
+	var log []string
+	available := map[string]Step{
+		"spam":      {Scorer: recordingScorer{"spam", &log, nil}},
+		"sentiment": {Scorer: recordingScorer{"sentiment", &log, errors.New("quota exceeded")}},
+		"attachments": {Analysis: func(tickets ...jira.JiraIssue) {
+			log = append(log, "attachments")
+		}},
+	}
+
+	dir, err := ioutil.TempDir("", "pipeline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "pipeline.json")
+	if err := ioutil.WriteFile(path, []byte(`{"steps": ["attachments", "sentiment", "spam"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadPipelineConfig(path)
+	if err != nil {
+		t.Fatalf("could not load pipeline config: %v", err)
+	}
+	pipeline, err := NewPipeline(cfg, available)
+	if err != nil {
+		t.Fatalf("could not build pipeline: %v", err)
+	}
+
+	// a failed step does not stop the following ones.
+	results, err := pipeline.Run(context.Background(), jira.JiraIssue{Key: "PL-1"})
+	if want := []string{"attachments", "sentiment", "spam"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("steps ran in order %v, want %v", log, want)
+	}
+	if errs, ok := err.(StepErrors); !ok || len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "sentiment:") {
+		t.Errorf("Run returned %v, want the error of the sentiment step", err)
+	}
+	if len(results) != 3 || results[1].Name != "sentiment" || results[1].Err == nil || results[2].Err != nil {
+		t.Errorf("step results = %+v, want one per step with only sentiment failing", results)
+	}
+
+	log = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if results, err := pipeline.Run(ctx); len(results) != 0 || len(log) != 0 || err == nil {
+		t.Errorf("Run with a done context ran %v and returned %v, want no step run and an error", log, err)
+	}
+
+	if _, err := NewPipeline(PipelineConfig{Steps: []string{"spam", "grammar", "spam"}}, available); err == nil ||
+		!strings.Contains(err.Error(), "grammar is not an available step") ||
+		!strings.Contains(err.Error(), "spam is listed more than once") {
+		t.Errorf("NewPipeline returned %v, want both the unknown and the repeated step reported", err)
+	}
+}
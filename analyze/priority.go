@@ -0,0 +1,111 @@
+package analyze
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// PriorityWeights maps priority IDs or names to the weight the tickets of that priority count with when
+// sampling tickets or averaging their quality, so that schemes of any number of tiers can be weighed.
+type PriorityWeights map[string]float64
+
+// ParsePriorityWeights parses comma separated priority ID or name to weight pairs, e.g. 1=4,2=2 or
+// Blocker=5,Critical=3.
+func ParsePriorityWeights(s string) (PriorityWeights, error) {
+	weights := make(PriorityWeights)
+	if strings.TrimSpace(s) == "" {
+		return weights, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid priority weight %q; expected priority=weight", pair)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight for priority %s: %v", parts[0], err)
+		}
+		weights[strings.TrimSpace(parts[0])] = weight
+	}
+	return weights, nil
+}
+
+// LoadPriorityWeights reads priority weights from a JSON file mapping priority IDs or names to weights, e.g.
+// {"Blocker": 5, "Critical": 4, "Major": 3, "Minor": 2, "Trivial": 1}.
+func LoadPriorityWeights(path string) (PriorityWeights, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read priority weights file: %v", err)
+	}
+	var weights PriorityWeights
+	if err := json.Unmarshal(content, &weights); err != nil {
+		return nil, fmt.Errorf("could not parse priority weights file %s: %v", path, err)
+	}
+	if weights == nil {
+		weights = make(PriorityWeights)
+	}
+	return weights, nil
+}
+
+// Weight returns the weight of the ticket's priority, looked up by ID and then by name, and whether it is mapped.
+func (w PriorityWeights) Weight(ticket jira.JiraIssue) (float64, bool) {
+	if weight, ok := w[ticket.Fields.Priority.ID]; ok && ticket.Fields.Priority.ID != "" {
+		return weight, true
+	}
+	if weight, ok := w[ticket.Fields.Priority.Name]; ok && ticket.Fields.Priority.Name != "" {
+		return weight, true
+	}
+	return 0, false
+}
+
+// Unmapped returns, sorted, the priorities of the tickets missing from the weights, formatted as "name (ID)".
+// Tickets without a priority are skipped.
+func (w PriorityWeights) Unmapped(tickets ...jira.JiraIssue) []string {
+	seen := make(map[string]bool)
+	var unmapped []string
+	for _, ticket := range tickets {
+		priority := ticket.Fields.Priority
+		if priority.ID == "" && priority.Name == "" {
+			continue
+		}
+		if _, ok := w.Weight(ticket); ok {
+			continue
+		}
+		label := fmt.Sprintf("%s (%s)", priority.Name, priority.ID)
+		if !seen[label] {
+			seen[label] = true
+			unmapped = append(unmapped, label)
+		}
+	}
+	sort.Strings(unmapped)
+	return unmapped
+}
+
+// PriorityWeightedQuality returns the mean TicketQualityScore of the tickets, each weighing as much as its
+// priority. Unmapped priorities weigh 1 and non-positive weights leave tickets out; the mean is NaN if no ticket
+// weighs anything.
+func PriorityWeightedQuality(weights PriorityWeights, tickets ...jira.JiraIssue) float64 {
+	var total, weightSum float64
+	for _, ticket := range tickets {
+		weight, ok := weights.Weight(ticket)
+		if !ok {
+			weight = 1
+		}
+		if weight <= 0 {
+			continue
+		}
+		total += weight * TicketQualityScore(ticket)
+		weightSum += weight
+	}
+	if weightSum == 0 {
+		return math.NaN()
+	}
+	return total / weightSum
+}
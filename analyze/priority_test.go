@@ -0,0 +1,60 @@
+package analyze
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestPriorityWeights(t *testing.T) {
+	weights, err := ParsePriorityWeights("Blocker=5, Critical=4,Major=3,Minor=2,Trivial=1,3=2.5")
+	if err != nil {
+		t.Fatalf("could not parse priority weights: %v", err)
+	}
+	withPriority := func(id, name string) jira.JiraIssue {
+		var ticket jira.JiraIssue
+		ticket.Fields.Priority.ID = id
+		ticket.Fields.Priority.Name = name
+		return ticket
+	}
+	blocker := withPriority("1", "Blocker")
+	blocker.HasStepsToReproduce = true
+	highest := withPriority("10", "Highest")
+	highest.HasStackTrace = true
+	tickets := []jira.JiraIssue{blocker, withPriority("5", "Trivial"), highest, withPriority("10", "Highest"),
+		withPriority("", "")}
+
+	for _, tc := range []struct {
+		ticket jira.JiraIssue
+		weight float64
+		ok     bool
+	}{
+		{blocker, 5, true},
+		{tickets[1], 1, true},
+		// a weight given to the ID takes precedence over the one given to the name.
+		{withPriority("3", "Major"), 2.5, true},
+		{highest, 0, false},
+	} {
+		if weight, ok := weights.Weight(tc.ticket); weight != tc.weight || ok != tc.ok {
+			t.Errorf("Weight of %+v = (%v, %v), want (%v, %v)", tc.ticket.Fields.Priority, weight, ok, tc.weight,
+				tc.ok)
+		}
+	}
+	if got, want := weights.Unmapped(tickets...), []string{"Highest (10)"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmapped = %v, want %v", got, want)
+	}
+
+	// the blocker counts five times, each unmapped ticket once and the one without a priority once too.
+	if got, want := PriorityWeightedQuality(weights, tickets...), (5*0.3+0.2)/9; math.Abs(got-want) > 1e-9 {
+		t.Errorf("PriorityWeightedQuality = %v, want %v", got, want)
+	}
+	if got := PriorityWeightedQuality(PriorityWeights{"Blocker": 0}, blocker); !math.IsNaN(got) {
+		t.Errorf("PriorityWeightedQuality of tickets weighing nothing = %v, want NaN", got)
+	}
+
+	if _, err := ParsePriorityWeights("Blocker=5,Critical"); err == nil {
+		t.Error("expected an error parsing a priority without a weight")
+	}
+}
@@ -0,0 +1,108 @@
+package analyze
+
+import (
+	"errors"
+	"math"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// ProviderScorer runs a sentiment scorer as one of several providers of the same field, storing its scores under
+// its name inside the sentiment providers of each ticket rather than as the ticket's sentiment score.
+type ProviderScorer struct {
+	name   string
+	scorer Scorer
+}
+
+// NewProviderScorer returns a scorer storing the sentiment scores computed by scorer under name.
+func NewProviderScorer(name string, scorer Scorer) *ProviderScorer {
+	return &ProviderScorer{name: name, scorer: scorer}
+}
+
+// Scores scores the tickets not yet scored by the provider and stores the scores under the provider's name.
+func (p *ProviderScorer) Scores(tickets ...jira.JiraIssue) error {
+	var pending []int
+	var copies []jira.JiraIssue
+	for i, ticket := range tickets {
+		if _, ok := ticket.Sentiment.Providers[p.name]; ok {
+			continue
+		}
+		ticket.Sentiment = jira.Sentiment{}
+		pending = append(pending, i)
+		copies = append(copies, ticket)
+	}
+	if len(copies) == 0 {
+		return nil
+	}
+	err := p.scorer.Scores(copies...)
+	for j, i := range pending {
+		if !copies[j].Sentiment.HasScore {
+			continue
+		}
+		if tickets[i].Sentiment.Providers == nil {
+			tickets[i].Sentiment.Providers = make(map[string]float64)
+		}
+		tickets[i].Sentiment.Providers[p.name] = copies[j].Sentiment.Score
+	}
+	return err
+}
+
+// Agreement holds how the sentiment scores of two providers agree over the tickets scored by both.
+type Agreement struct {
+	Tickets int
+	// Correlation holds the Pearson correlation coefficient of the scores of both providers.
+	Correlation float64
+	// MeanAbsDifference holds the mean absolute difference between the scores of both providers.
+	MeanAbsDifference float64
+	// SignAgreement holds the fraction of tickets both providers deem positive, negative or neutral alike.
+	SignAgreement float64
+}
+
+// SentimentAgreement compares the sentiment scores stored by providers a and b over the tickets scored by both.
+// The correlation is NaN, alongside the returned error, if it cannot be computed, e.g. if fewer than two
+// tickets were scored by both.
+func SentimentAgreement(a, b string, tickets ...jira.JiraIssue) (Agreement, error) {
+	var xs, ys []float64
+	for _, t := range tickets {
+		x, okA := t.Sentiment.Providers[a]
+		y, okB := t.Sentiment.Providers[b]
+		if okA && okB {
+			xs = append(xs, x)
+			ys = append(ys, y)
+		}
+	}
+	agreement := Agreement{Tickets: len(xs)}
+	if len(xs) == 0 {
+		agreement.Correlation = math.NaN()
+		agreement.MeanAbsDifference = math.NaN()
+		agreement.SignAgreement = math.NaN()
+		return agreement, errors.New("no ticket was scored by both providers")
+	}
+	var diff float64
+	var agreeing int
+	for i := range xs {
+		diff += math.Abs(xs[i] - ys[i])
+		if sign(xs[i]) == sign(ys[i]) {
+			agreeing++
+		}
+	}
+	agreement.MeanAbsDifference = diff / float64(len(xs))
+	agreement.SignAgreement = float64(agreeing) / float64(len(xs))
+	r, err := pearson(xs, ys)
+	if err != nil {
+		r = math.NaN()
+	}
+	agreement.Correlation = r
+	return agreement, err
+}
+
+// sign returns -1, 0 or 1 depending on the sign of v.
+func sign(v float64) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+	return 0
+}
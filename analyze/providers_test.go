@@ -0,0 +1,59 @@
+package analyze
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// fakeSentiment scores the tickets whose key it knows with the given sentiment, counting the tickets it is given.
+type fakeSentiment struct {
+	scores map[string]float64
+	scored int
+}
+
+func (f *fakeSentiment) Scores(tickets ...jira.JiraIssue) error {
+	for i := range tickets {
+		f.scored++
+		if score, ok := f.scores[tickets[i].Key]; ok {
+			tickets[i].Sentiment = jira.Sentiment{Score: score, HasScore: true}
+		}
+	}
+	return nil
+}
+
+func TestSentimentAgreement(t *testing.T) {
+	local := &fakeSentiment{scores: map[string]float64{"S-1": 0.5, "S-2": -0.5, "S-3": -0.1, "S-4": 0.2}}
+	cloud := &fakeSentiment{scores: map[string]float64{"S-1": 0.7, "S-2": -0.3, "S-3": 0.1}}
+	tickets := []jira.JiraIssue{{Key: "S-1"}, {Key: "S-2"}, {Key: "S-3"}, {Key: "S-4"}}
+	tickets[0].Sentiment = jira.Sentiment{Score: 0.9, HasScore: true}
+
+	for _, scorer := range []Scorer{NewProviderScorer("local", local), NewProviderScorer("cloud", cloud)} {
+		if err := scorer.Scores(tickets...); err != nil {
+			t.Fatalf("could not score tickets: %v", err)
+		}
+	}
+	if tickets[0].Sentiment.Score != 0.9 || tickets[0].Sentiment.Providers["local"] != 0.5 {
+		t.Errorf("sentiment = %+v, want the provider scores stored next to the ticket's score", tickets[0].Sentiment)
+	}
+	// tickets already scored by a provider are not scored again.
+	if err := NewProviderScorer("local", local).Scores(tickets...); err != nil || local.scored != len(tickets) {
+		t.Errorf("local provider scored %d tickets, want only the first run's %d", local.scored, len(tickets))
+	}
+
+	agreement, err := SentimentAgreement("local", "cloud", tickets...)
+	if err != nil {
+		t.Fatalf("could not compute agreement: %v", err)
+	}
+	// the cloud provider scores every ticket 0.2 higher, turning the slightly negative ticket positive.
+	if agreement.Tickets != 3 || math.Abs(agreement.Correlation-1) > 1e-9 ||
+		math.Abs(agreement.MeanAbsDifference-0.2) > 1e-9 || math.Abs(agreement.SignAgreement-2.0/3) > 1e-9 {
+		t.Errorf("SentimentAgreement = %+v, want 3 tickets correlating fully, 0.2 apart, 2/3 agreeing", agreement)
+	}
+
+	if agreement, err := SentimentAgreement("local", "unknown", tickets...); err == nil ||
+		!math.IsNaN(agreement.Correlation) {
+		t.Errorf("SentimentAgreement with an unknown provider = (%+v, %v), want an error", agreement, err)
+	}
+}
@@ -0,0 +1,117 @@
+package analyze
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+const (
+	// qualityMinDescWords defines the number of description words above which a description counts as detailed.
+	qualityMinDescWords = 20
+	// qualityMaxGrammarErrs defines the number of grammar errors below which a ticket counts as well written.
+	qualityMaxGrammarErrs = 5
+)
+
+// TicketQualityScore returns a score between 0 and 1 describing how well a ticket is reported, based on the
+// presence of steps to reproduce, stack traces, attachments, a detailed description and few grammar errors.
+// It relies on the fields computed by the StepsToReproduce and StackTraces analyses and the grammar scorer.
+func TicketQualityScore(ticket jira.JiraIssue) float64 {
+	var score float64
+	if ticket.HasStepsToReproduce {
+		score += 0.3
+	}
+	if ticket.HasStackTrace {
+		score += 0.2
+	}
+	if len(ticket.Fields.Attachments) > 0 {
+		score += 0.2
+	}
+	if DescriptionWords(ticket) >= qualityMinDescWords {
+		score += 0.2
+	}
+	if ticket.GrammarCorrectness.HasScore && ticket.GrammarCorrectness.Score < qualityMaxGrammarErrs {
+		score += 0.1
+	}
+	return score
+}
+
+// DefaultUrgencyAgeScale defines the age by which the urgency of an open ticket doubles.
+const DefaultUrgencyAgeScale = 30 * 24 * time.Hour
+
+// QualityUrgency returns how much attention a ticket deserves given its quality: its lack of quality, i.e.
+// 1 - TicketQualityScore, which for open tickets grows linearly with their age at now, doubling every ageScale.
+// A non-positive ageScale disables age weighting.
+func QualityUrgency(ticket jira.JiraIssue, now time.Time, ageScale time.Duration) float64 {
+	urgency := 1 - TicketQualityScore(ticket)
+	if isResolved(ticket) || ageScale <= 0 {
+		return urgency
+	}
+	age := now.Sub(time.Time(ticket.Fields.Created))
+	if age < 0 {
+		age = 0
+	}
+	return urgency * (1 + age.Hours()/ageScale.Hours())
+}
+
+// DefaultFreshnessHalfLife defines the time since the last activity on a ticket after which its freshness halves.
+const DefaultFreshnessHalfLife = 14 * 24 * time.Hour
+
+// Freshness returns a score decaying from 1, for a ticket active at now, towards 0, halving every halfLife since
+// its last comment or changelog entry, or since its creation if there is none. Resolved tickets score 0 and so
+// do all tickets if halfLife is not positive.
+func Freshness(ticket jira.JiraIssue, now time.Time, halfLife time.Duration) float64 {
+	if isResolved(ticket) || halfLife <= 0 {
+		return 0
+	}
+	idle := now.Sub(lastActivity(ticket))
+	if idle < 0 {
+		idle = 0
+	}
+	return math.Exp(-math.Ln2 * idle.Hours() / halfLife.Hours())
+}
+
+// StalestOpenTickets returns the n open tickets with the lowest freshness at now, from the stalest one, or all
+// of them if n is not positive.
+func StalestOpenTickets(n int, now time.Time, halfLife time.Duration, tickets ...jira.JiraIssue) []RankedTicket {
+	var ranked []RankedTicket
+	for _, ticket := range tickets {
+		if !isResolved(ticket) {
+			ranked = append(ranked, RankedTicket{Key: ticket.Key, Value: Freshness(ticket, now, halfLife)})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Value < ranked[j].Value
+	})
+	if n > 0 && n < len(ranked) {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
+
+// bugIssueType holds the name of the issue type of bug tickets.
+const bugIssueType = "Bug"
+
+// MissingEvidenceHighPriority returns the keys of the high priority bugs holding neither steps to reproduce
+// nor a stack trace inside their description or comments, nor any attachment.
+func MissingEvidenceHighPriority(tickets ...jira.JiraIssue) []string {
+	var keys []string
+	for _, ticket := range tickets {
+		if !isTicketHighPriority(ticket) || ticket.Fields.Type.Name != bugIssueType {
+			continue
+		}
+		if len(ticket.Fields.Attachments) > 0 {
+			continue
+		}
+		if _, ok := findSnippet(reportHeuristics["steps_to_reproduce"], ticket); ok {
+			continue
+		}
+		if _, ok := findSnippet(reportHeuristics["stack_trace"], ticket); ok {
+			continue
+		}
+		keys = append(keys, ticket.Key)
+	}
+	return keys
+}
@@ -0,0 +1,94 @@
+package analyze
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestMissingEvidenceHighPriority(t *testing.T) {
+	bug := func(key, priority, description string) jira.JiraIssue {
+		ticket := jira.JiraIssue{Key: key}
+		ticket.Fields.Type.Name = "Bug"
+		ticket.Fields.Priority.ID = priority
+		ticket.Fields.Description = description
+		return ticket
+	}
+	withSteps := bug("E-2", "1", "Steps:\n* open the app\n* press save")
+	withStackTrace := bug("E-3", "2", "it crashes")
+	withStackTrace.Fields.Comments.Comments = []jira.Comment{
+		{Body: "java.lang.NullPointerException: boom\n\tat com.acme.Editor.save(Editor.java:42)\n"},
+	}
+	withAttachment := bug("E-4", "1", "it crashes")
+	withAttachment.Fields.Attachments = []jira.Attachment{{Filename: "crash.png"}}
+	task := bug("E-6", "1", "it crashes")
+	task.Fields.Type.Name = "Task"
+
+	tickets := []jira.JiraIssue{
+		bug("E-1", "1", "it crashes"),
+		withSteps,
+		withStackTrace,
+		withAttachment,
+		bug("E-5", "5", "it crashes"),
+		task,
+	}
+	if got, want := MissingEvidenceHighPriority(tickets...), []string{"E-1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MissingEvidenceHighPriority = %v, want %v", got, want)
+	}
+}
+
+func TestQualityUrgency(t *testing.T) {
+	withSteps := func(key, status string, created jira.Time) jira.JiraIssue {
+		ticket := ticketWith(key, status)
+		ticket.Fields.Created = created
+		ticket.HasStepsToReproduce = true
+		return ticket
+	}
+	now := time.Time(at(31, 0))
+	// tickets scoring 0.3 lack 0.7 of quality, doubled for open tickets as old as the age scale.
+	for _, tc := range []struct {
+		ticket   jira.JiraIssue
+		ageScale time.Duration
+		want     float64
+	}{
+		{withSteps("U-1", "Open", at(31, 0)), DefaultUrgencyAgeScale, 0.7},
+		{withSteps("U-2", "Open", at(1, 0)), DefaultUrgencyAgeScale, 1.4},
+		{withSteps("U-3", "Closed", at(1, 0)), DefaultUrgencyAgeScale, 0.7},
+		{withSteps("U-4", "Open", at(1, 0)), 0, 0.7},
+	} {
+		if got := QualityUrgency(tc.ticket, now, tc.ageScale); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("QualityUrgency of %s = %v, want %v", tc.ticket.Key, got, tc.want)
+		}
+	}
+}
+
+func TestFreshness(t *testing.T) {
+	now := time.Time(at(15, 0))
+	// created two weeks ago and untouched since.
+	dormant := ticketWith("F-1", "Open")
+	// moved to In Progress a day ago.
+	recent := ticketWith("F-2", "In Progress", transition{13 * 24, "Open", "In Progress"})
+	resolved := ticketWith("F-3", "Closed", transition{14 * 24, "Open", "Closed"})
+
+	if got := Freshness(dormant, now, DefaultFreshnessHalfLife); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("freshness of a ticket idle for a half-life = %v, want 0.5", got)
+	}
+	if got := Freshness(recent, now, DefaultFreshnessHalfLife); got <= Freshness(dormant, now,
+		DefaultFreshnessHalfLife) || got >= 1 {
+		t.Errorf("freshness of a recently updated ticket = %v, want it between the dormant ticket's and 1", got)
+	}
+	if got := Freshness(resolved, now, DefaultFreshnessHalfLife); got != 0 {
+		t.Errorf("freshness of a resolved ticket = %v, want 0", got)
+	}
+
+	stalest := StalestOpenTickets(1, now, DefaultFreshnessHalfLife, recent, resolved, dormant)
+	if len(stalest) != 1 || stalest[0].Key != "F-1" {
+		t.Errorf("StalestOpenTickets = %+v, want only F-1", stalest)
+	}
+	if all := StalestOpenTickets(0, now, DefaultFreshnessHalfLife, recent, resolved, dormant); len(all) != 2 {
+		t.Errorf("StalestOpenTickets = %+v, want both open tickets", all)
+	}
+}
@@ -0,0 +1,74 @@
+package analyze
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// Predicate defines a threshold on one of the Metrics, e.g. sentiment < -0.5.
+type Predicate struct {
+	Metric   string
+	Operator string
+	Value    float64
+}
+
+// comparisons maps the operators predicates support to the comparisons they perform.
+var comparisons = map[string]func(a, b float64) bool{
+	"<":  func(a, b float64) bool { return a < b },
+	"<=": func(a, b float64) bool { return a <= b },
+	">":  func(a, b float64) bool { return a > b },
+	">=": func(a, b float64) bool { return a >= b },
+	"=":  func(a, b float64) bool { return a == b },
+	"!=": func(a, b float64) bool { return a != b },
+}
+
+// ParsePredicate parses a predicate written as metric, operator and value, e.g. "time_to_close>100".
+func ParsePredicate(s string) (Predicate, error) {
+	// two character operators are tried first so that e.g. <= is not parsed as < followed by =.
+	for _, op := range []string{"<=", ">=", "!=", "<", ">", "="} {
+		i := strings.Index(s, op)
+		if i < 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(s[i+len(op):]), 64)
+		if err != nil {
+			return Predicate{}, fmt.Errorf("invalid value in predicate %q: %v", s, err)
+		}
+		return Predicate{Metric: strings.TrimSpace(s[:i]), Operator: op, Value: value}, nil
+	}
+	return Predicate{}, fmt.Errorf("predicate %q holds no operator; valid operators are <, <=, >, >=, = and !=", s)
+}
+
+// Query returns the keys of the tickets matching all of the given predicates. Tickets without a value for the
+// metric of any predicate do not match.
+func Query(predicates []Predicate, tickets ...jira.JiraIssue) ([]string, error) {
+	metrics := make([]Metric, len(predicates))
+	for i, p := range predicates {
+		m, ok := Metrics[p.Metric]
+		if !ok {
+			return nil, unknownMetricError(p.Metric)
+		}
+		if _, ok := comparisons[p.Operator]; !ok {
+			return nil, fmt.Errorf("%s is not a valid operator", p.Operator)
+		}
+		metrics[i] = m
+	}
+	var keys []string
+	for _, ticket := range tickets {
+		matches := true
+		for i, p := range predicates {
+			value, ok := metrics[i](ticket)
+			if !ok || !comparisons[p.Operator](value, p.Value) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			keys = append(keys, ticket.Key)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,61 @@
+package analyze
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestParsePredicate(t *testing.T) {
+	for s, want := range map[string]Predicate{
+		"sentiment < -0.5":    {Metric: "sentiment", Operator: "<", Value: -0.5},
+		"time_to_close>=100":  {Metric: "time_to_close", Operator: ">=", Value: 100},
+		"reopens != 0":        {Metric: "reopens", Operator: "!=", Value: 0},
+		"comments=3":          {Metric: "comments", Operator: "=", Value: 3},
+		"grammar_errors <= 2": {Metric: "grammar_errors", Operator: "<=", Value: 2},
+	} {
+		if got, err := ParsePredicate(s); err != nil || got != want {
+			t.Errorf("ParsePredicate(%q) = (%+v, %v), want %+v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"sentiment", "sentiment < low"} {
+		if _, err := ParsePredicate(s); err == nil {
+			t.Errorf("expected an error parsing %q", s)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	scored := func(key string, sentiment, hours float64) jira.JiraIssue {
+		ticket := jira.JiraIssue{Key: key, TimeToClose: hours}
+		ticket.Sentiment = jira.Sentiment{Score: sentiment, HasScore: true}
+		return ticket
+	}
+	tickets := []jira.JiraIssue{
+		scored("Q-1", -0.8, 150),
+		scored("Q-2", -0.8, 50),
+		scored("Q-3", 0.2, 200),
+		// tickets without a sentiment score never match a sentiment predicate.
+		{Key: "Q-4", TimeToClose: 200},
+		scored("Q-5", -0.6, 101),
+	}
+	predicates := []Predicate{
+		{Metric: "sentiment", Operator: "<", Value: -0.5},
+		{Metric: "time_to_close", Operator: ">", Value: 100},
+	}
+	keys, err := Query(predicates, tickets...)
+	if err != nil {
+		t.Fatalf("could not query tickets: %v", err)
+	}
+	if want := []string{"Q-1", "Q-5"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("Query = %v, want %v", keys, want)
+	}
+
+	if _, err := Query([]Predicate{{Metric: "mood", Operator: "<", Value: 0}}, tickets...); err == nil {
+		t.Error("expected an error for an unknown metric")
+	}
+	if _, err := Query([]Predicate{{Metric: "sentiment", Operator: "~", Value: 0}}, tickets...); err == nil {
+		t.Error("expected an error for an unknown operator")
+	}
+}
@@ -0,0 +1,95 @@
+package analyze
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// Metric computes a numeric value for a ticket and returns whether the value is available for the ticket.
+type Metric func(jira.JiraIssue) (float64, bool)
+
+// Metrics maps the names of the metrics tickets can be ranked by to the functions computing them.
+var Metrics = map[string]Metric{
+	"time_to_close": func(t jira.JiraIssue) (float64, bool) {
+		return t.TimeToClose, t.TimeToClose > 0
+	},
+	"comments": func(t jira.JiraIssue) (float64, bool) {
+		return float64(len(t.Fields.Comments.Comments)), true
+	},
+	"comment_words": func(t jira.JiraIssue) (float64, bool) {
+		return float64(CommentWords(t)), true
+	},
+	"reassignments": func(t jira.JiraIssue) (float64, bool) {
+		return float64(ReassignmentCount(t)), true
+	},
+	"quality": func(t jira.JiraIssue) (float64, bool) {
+		return TicketQualityScore(t), true
+	},
+	"quality_urgency": func(t jira.JiraIssue) (float64, bool) {
+		return QualityUrgency(t, time.Now(), DefaultUrgencyAgeScale), true
+	},
+	"late_attachments": func(t jira.JiraIssue) (float64, bool) {
+		return LateAttachmentFraction(t), len(t.Fields.Attachments) > 0
+	},
+	"sentiment": func(t jira.JiraIssue) (float64, bool) {
+		return t.Sentiment.Score, t.Sentiment.HasScore
+	},
+	"grammar_errors": func(t jira.JiraIssue) (float64, bool) {
+		return float64(t.GrammarCorrectness.Score), t.GrammarCorrectness.HasScore
+	},
+	"lead_time": LeadTime,
+	"reopens": func(t jira.JiraIssue) (float64, bool) {
+		return float64(ReopenCount(t)), true
+	},
+	"post_resolution_comments": func(t jira.JiraIssue) (float64, bool) {
+		return float64(PostResolutionComments(t)), isResolved(t)
+	},
+	"max_comment_gap": func(t jira.JiraIssue) (float64, bool) {
+		return MaxCommentGap(t), len(t.Fields.Comments.Comments) > 0
+	},
+}
+
+// unknownMetricError returns the error reported for a metric missing from Metrics, listing the valid ones.
+func unknownMetricError(metric string) error {
+	var names []string
+	for name := range Metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("%s is not a valid metric; valid metrics are %s", metric, strings.Join(names, ", "))
+}
+
+// RankedTicket holds the key of a ticket along with the value of the metric it was ranked by.
+type RankedTicket struct {
+	Key   string
+	Value float64
+}
+
+// Rank sorts the tickets having a value for the given metric in descending order (ascending if ascending
+// is true) and returns the first n of them, or all of them if n is not positive.
+func Rank(metric string, n int, ascending bool, tickets ...jira.JiraIssue) ([]RankedTicket, error) {
+	m, ok := Metrics[metric]
+	if !ok {
+		return nil, unknownMetricError(metric)
+	}
+	var ranked []RankedTicket
+	for _, ticket := range tickets {
+		if value, ok := m(ticket); ok {
+			ranked = append(ranked, RankedTicket{Key: ticket.Key, Value: value})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ascending {
+			return ranked[i].Value < ranked[j].Value
+		}
+		return ranked[i].Value > ranked[j].Value
+	})
+	if n > 0 && n < len(ranked) {
+		ranked = ranked[:n]
+	}
+	return ranked, nil
+}
@@ -0,0 +1,39 @@
+package analyze
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestRank(t *testing.T) {
+	tickets := []jira.JiraIssue{
+		{Key: "K-1", TimeToClose: 30},
+		{Key: "K-2", TimeToClose: 0},
+		{Key: "K-3", TimeToClose: 50},
+		{Key: "K-4", TimeToClose: 10},
+		{Key: "K-5", TimeToClose: 20},
+	}
+
+	slowest, err := Rank("time_to_close", 2, false, tickets...)
+	if err != nil {
+		t.Fatalf("could not rank tickets: %v", err)
+	}
+	if want := []RankedTicket{{"K-3", 50}, {"K-1", 30}}; !reflect.DeepEqual(slowest, want) {
+		t.Errorf("slowest tickets = %v, want %v", slowest, want)
+	}
+
+	// the open ticket has no time-to-close and is left out.
+	fastest, err := Rank("time_to_close", 0, true, tickets...)
+	if err != nil {
+		t.Fatalf("could not rank tickets: %v", err)
+	}
+	if want := []RankedTicket{{"K-4", 10}, {"K-5", 20}, {"K-1", 30}, {"K-3", 50}}; !reflect.DeepEqual(fastest, want) {
+		t.Errorf("fastest tickets = %v, want %v", fastest, want)
+	}
+
+	if _, err := Rank("votes", 2, false, tickets...); err == nil {
+		t.Error("expected an error for an unknown metric")
+	}
+}
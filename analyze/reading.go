@@ -0,0 +1,26 @@
+package analyze
+
+import (
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// DefaultWordsPerMinute defines the reading speed assumed when estimating reading times.
+const DefaultWordsPerMinute = 200
+
+// ReadingTimeMinutes estimates the number of minutes it takes to read the summary, description and comments of
+// a ticket at wpm words per minute, or at DefaultWordsPerMinute if wpm is not positive.
+func ReadingTimeMinutes(ticket jira.JiraIssue, wpm int) float64 {
+	if wpm <= 0 {
+		wpm = DefaultWordsPerMinute
+	}
+	return float64(SummaryDescWords(ticket)+CommentWords(ticket)) / float64(wpm)
+}
+
+// ReadingTimes returns the estimated reading time, in minutes, of each ticket at wpm words per minute.
+func ReadingTimes(wpm int, tickets ...jira.JiraIssue) []float64 {
+	times := make([]float64, len(tickets))
+	for i, ticket := range tickets {
+		times[i] = ReadingTimeMinutes(ticket, wpm)
+	}
+	return times
+}
@@ -0,0 +1,35 @@
+package analyze
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestReadingTimeMinutes(t *testing.T) {
+	// 3 summary, 97 description and 300 comment words make 400 words.
+	ticket := jira.JiraIssue{Key: "R-1"}
+	ticket.Fields.Summary = "login page broken"
+	ticket.Fields.Description = strings.Repeat("word ", 97)
+	ticket.Fields.Comments.Comments = []jira.Comment{{Body: strings.Repeat("word ", 300)}}
+
+	for _, tc := range []struct {
+		wpm  int
+		want float64
+	}{
+		{0, 2},
+		{DefaultWordsPerMinute, 2},
+		{100, 4},
+	} {
+		if got := ReadingTimeMinutes(ticket, tc.wpm); got != tc.want {
+			t.Errorf("ReadingTimeMinutes at %d wpm = %v, want %v", tc.wpm, got, tc.want)
+		}
+	}
+
+	empty := jira.JiraIssue{Key: "R-2"}
+	if got, want := ReadingTimes(100, ticket, empty), []float64{4, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadingTimes = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,138 @@
+package analyze
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// maxSnippetLength defines the maximum number of characters of a matched text kept inside a report.
+const maxSnippetLength = 200
+
+// Report holds everything ticketguru computes locally for a single ticket.
+type Report struct {
+	Key      string
+	Summary  string
+	Priority string
+	Status   string
+	Created  time.Time
+
+	Resolved    bool
+	ResolvedAt  time.Time
+	TimeToClose float64
+	// Anomaly describes why the timestamps of the ticket cannot be trusted, if that is the case.
+	Anomaly string
+
+	SummaryWords     int
+	DescriptionWords int
+	CommentWords     int
+	Comments         int
+	Attachments      int
+	Links            int
+	Reassignments    int
+	QualityScore     float64
+	// PostResolutionComments holds the number of comments created after the ticket was resolved.
+	PostResolutionComments int
+
+	// Heuristics holds the snippet of text matched by each detected heuristic, indexed by heuristic name.
+	Heuristics map[string]string
+
+	Sentiment          *float64
+	GrammarCorrectness *int
+
+	Timeline []TimelineEntry
+	// HoursInStatus holds the number of hours spent in each status until the ticket was resolved or until now.
+	HoursInStatus map[string]float64
+}
+
+// TimelineEntry defines a status transition of a ticket.
+type TimelineEntry struct {
+	At   time.Time
+	From string
+	To   string
+}
+
+// reportHeuristics holds the regular expressions evaluated against a ticket's text inside a report.
+var reportHeuristics = map[string]*regexp.Regexp{
+	"steps_to_reproduce": regexp.MustCompile(stepsToReproduceExpr),
+	"stack_trace":        regexp.MustCompile(stackTraceExpr),
+}
+
+// BuildReport computes all local analyses for a single ticket, regardless of its priority, and returns them
+// alongside the scores already stored on the ticket.
+func BuildReport(ticket jira.JiraIssue) Report {
+	report := Report{
+		Key:              ticket.Key,
+		Summary:          ticket.Fields.Summary,
+		Priority:         ticket.Fields.Priority.Name,
+		Status:           ticket.Fields.Status.Name,
+		Created:          time.Time(ticket.Fields.Created),
+		SummaryWords:     calculateNumberOfWords(ticket.Fields.Summary),
+		DescriptionWords: calculateNumberOfWords(ticket.Fields.Description),
+		CommentWords:     calculateNumberOfWords(concatComments(ticket)),
+		Comments:         len(ticket.Fields.Comments.Comments),
+		Attachments:      len(ticket.Fields.Attachments),
+		Links:            CountLinks(ticket),
+		Reassignments:    ReassignmentCount(ticket),
+		Heuristics:       make(map[string]string),
+	}
+	var err error
+	report.TimeToClose, report.Resolved, err = timeToResolve(ticket, ResolutionOptions{})
+	if err != nil {
+		report.Anomaly = err.Error()
+	}
+	until := time.Now()
+	if report.Resolved {
+		report.ResolvedAt, _ = resolutionTime(ticket)
+		until = report.ResolvedAt
+	}
+	report.HoursInStatus = TimeInStatus(ticket, until)
+	report.PostResolutionComments = PostResolutionComments(ticket)
+
+	for name, regex := range reportHeuristics {
+		if snippet, ok := findSnippet(regex, ticket); ok {
+			report.Heuristics[name] = snippet
+		}
+	}
+	ticket.HasStepsToReproduce = report.Heuristics["steps_to_reproduce"] != ""
+	ticket.HasStackTrace = report.Heuristics["stack_trace"] != ""
+	report.QualityScore = TicketQualityScore(ticket)
+
+	if ticket.Sentiment.HasScore {
+		score := ticket.Sentiment.Score
+		report.Sentiment = &score
+	}
+	if ticket.GrammarCorrectness.HasScore {
+		score := ticket.GrammarCorrectness.Score
+		report.GrammarCorrectness = &score
+	}
+	report.Timeline = StatusTimeline(ticket)
+	return report
+}
+
+// StatusTimeline returns the status transitions of a ticket in chronological order.
+func StatusTimeline(ticket jira.JiraIssue) []TimelineEntry {
+	var timeline []TimelineEntry
+	for _, t := range statusTransitions(ticket) {
+		timeline = append(timeline, TimelineEntry{At: t.At, From: t.From, To: t.To})
+	}
+	return timeline
+}
+
+// findSnippet returns the first text matched by regex inside the description or any of the comments of a ticket.
+func findSnippet(regex *regexp.Regexp, ticket jira.JiraIssue) (string, bool) {
+	texts := []string{ticket.Fields.Description}
+	for _, comment := range ticket.Fields.Comments.Comments {
+		texts = append(texts, comment.Body)
+	}
+	for _, text := range texts {
+		if match := regex.FindString(text); match != "" {
+			if runes := []rune(match); len(runes) > maxSnippetLength {
+				match = string(runes[:maxSnippetLength]) + "..."
+			}
+			return match, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,43 @@
+package analyze
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestBuildReport(t *testing.T) {
+	ticket := ticketWith("REP-1", "Closed", transition{4, "Open", "In Progress"}, transition{10, "In Progress", "Closed"})
+	ticket.Fields.Summary = "save fails"
+	ticket.Fields.Description = "Steps:\n* open the app\n* press save"
+	ticket.Fields.Comments.Comments = []jira.Comment{{ID: "1", Body: "fixed in PROJ-7", Created: at(1, 9)}}
+	ticket.Sentiment = jira.Sentiment{Score: -0.25, HasScore: true}
+
+	report := BuildReport(ticket)
+	if !report.Resolved || report.TimeToClose != 10 || !report.ResolvedAt.Equal(time.Time(at(1, 10))) {
+		t.Errorf("report resolved = %v after %v hours at %v, want resolved after 10 hours", report.Resolved,
+			report.TimeToClose, report.ResolvedAt)
+	}
+	if report.SummaryWords != 2 || report.DescriptionWords != 8 || report.CommentWords != 3 || report.Links != 1 {
+		t.Errorf("report counts %d summary, %d description, %d comment words and %d links; want 2, 8, 3 and 1",
+			report.SummaryWords, report.DescriptionWords, report.CommentWords, report.Links)
+	}
+	if got := report.Heuristics["steps_to_reproduce"]; got != "\n* open the app\n* press save" {
+		t.Errorf("steps to reproduce snippet = %q", got)
+	}
+	if _, ok := report.Heuristics["stack_trace"]; ok {
+		t.Error("report detected a stack trace in a ticket without one")
+	}
+	if report.Sentiment == nil || *report.Sentiment != -0.25 || report.GrammarCorrectness != nil {
+		t.Errorf("report scores sentiment %v and grammar %v, want only the sentiment -0.25", report.Sentiment,
+			report.GrammarCorrectness)
+	}
+	if want := map[string]float64{"Open": 4, "In Progress": 6}; !reflect.DeepEqual(report.HoursInStatus, want) {
+		t.Errorf("hours in status = %v, want %v", report.HoursInStatus, want)
+	}
+	if len(report.Timeline) != 2 || report.Timeline[1].To != "Closed" {
+		t.Errorf("status timeline = %+v", report.Timeline)
+	}
+}
@@ -0,0 +1,129 @@
+package analyze
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+const (
+	// NewReporter represents the tier of reporters with a single ticket in the dataset.
+	NewReporter = "new"
+	// OccasionalReporter represents the tier of reporters with up to frequentReporterMin tickets in the dataset.
+	OccasionalReporter = "occasional"
+	// FrequentReporter represents the tier of reporters with more than frequentReporterMin tickets in the dataset.
+	FrequentReporter = "frequent"
+
+	frequentReporterMin = 5
+
+	// MinReporterTickets defines the default minimum number of tickets a reporter must have reported to be
+	// ranked by TopReportersByQuality.
+	MinReporterTickets = 3
+)
+
+// ReporterQuality holds the mean quality score of the tickets of a reporter.
+type ReporterQuality struct {
+	Reporter    string
+	Tickets     int
+	MeanQuality float64
+}
+
+// ReporterExperience returns the number of tickets each reporter has reported across the dataset.
+func ReporterExperience(tickets ...jira.JiraIssue) map[string]int {
+	result := make(map[string]int)
+	for _, ticket := range tickets {
+		if name := ticket.Fields.Reporter.Name; name != "" {
+			result[name]++
+		}
+	}
+	return result
+}
+
+// ReporterTier returns the experience tier of a reporter given the number of tickets they reported.
+func ReporterTier(ticketCount int) string {
+	switch {
+	case ticketCount <= 1:
+		return NewReporter
+	case ticketCount <= frequentReporterMin:
+		return OccasionalReporter
+	default:
+		return FrequentReporter
+	}
+}
+
+// TimeToResolveByReporterTier groups the times-to-close of closed tickets by the experience tier of their
+// reporter across the dataset; tickets without a reporter are skipped.
+func TimeToResolveByReporterTier(tickets ...jira.JiraIssue) map[string][]float64 {
+	experience := ReporterExperience(tickets...)
+	result := make(map[string][]float64)
+	for _, ticket := range tickets {
+		name := ticket.Fields.Reporter.Name
+		if ticket.TimeToClose <= 0 || name == "" {
+			continue
+		}
+		tier := ReporterTier(experience[name])
+		result[tier] = append(result[tier], ticket.TimeToClose)
+	}
+	return result
+}
+
+// TopReportersByQuality returns the n reporters with the highest mean TicketQualityScore among those who
+// reported at least minTickets tickets, best first, ties broken by ticket count and then name. A non-positive n
+// returns all of them; tickets without a reporter are skipped.
+func TopReportersByQuality(n, minTickets int, tickets ...jira.JiraIssue) []ReporterQuality {
+	totals := make(map[string]*ReporterQuality)
+	for _, ticket := range tickets {
+		name := ticket.Fields.Reporter.Name
+		if name == "" {
+			continue
+		}
+		r, ok := totals[name]
+		if !ok {
+			r = &ReporterQuality{Reporter: name}
+			totals[name] = r
+		}
+		r.Tickets++
+		r.MeanQuality += TicketQualityScore(ticket)
+	}
+	var ranked []ReporterQuality
+	for _, r := range totals {
+		if r.Tickets < minTickets {
+			continue
+		}
+		r.MeanQuality /= float64(r.Tickets)
+		ranked = append(ranked, *r)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].MeanQuality != ranked[j].MeanQuality {
+			return ranked[i].MeanQuality > ranked[j].MeanQuality
+		}
+		if ranked[i].Tickets != ranked[j].Tickets {
+			return ranked[i].Tickets > ranked[j].Tickets
+		}
+		return ranked[i].Reporter < ranked[j].Reporter
+	})
+	if n > 0 && len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
+
+// WriteReporterQualityCSV writes the ranked reporters as CSV, one row per reporter in rank order, preceded by
+// a header.
+func WriteReporterQualityCSV(w io.Writer, reporters []ReporterQuality) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"rank", "reporter", "tickets", "mean_quality"})
+	for i, r := range reporters {
+		writer.Write([]string{
+			strconv.Itoa(i + 1),
+			r.Reporter,
+			strconv.Itoa(r.Tickets),
+			strconv.FormatFloat(r.MeanQuality, 'f', 2, 64),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,76 @@
+package analyze
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestReporterExperience(t *testing.T) {
+	var tickets []jira.JiraIssue
+	report := func(reporter string, hours float64) {
+		ticket := jira.JiraIssue{TimeToClose: hours}
+		ticket.Fields.Reporter.Name = reporter
+		tickets = append(tickets, ticket)
+	}
+	report("ana", 10)
+	for i := 0; i < 3; i++ {
+		report("bob", 20)
+	}
+	for i := 0; i < 6; i++ {
+		report("eve", 0)
+	}
+	report("eve", 5)
+	report("", 40)
+
+	experience := ReporterExperience(tickets...)
+	if want := map[string]int{"ana": 1, "bob": 3, "eve": 7}; !reflect.DeepEqual(experience, want) {
+		t.Errorf("ReporterExperience = %v, want %v", experience, want)
+	}
+	for count, want := range map[int]string{1: NewReporter, 2: OccasionalReporter, 5: OccasionalReporter,
+		6: FrequentReporter} {
+		if got := ReporterTier(count); got != want {
+			t.Errorf("ReporterTier(%d) = %s, want %s", count, got, want)
+		}
+	}
+	want := map[string][]float64{NewReporter: {10}, OccasionalReporter: {20, 20, 20}, FrequentReporter: {5}}
+	if got := TimeToResolveByReporterTier(tickets...); !reflect.DeepEqual(got, want) {
+		t.Errorf("TimeToResolveByReporterTier = %v, want %v", got, want)
+	}
+}
+
+func TestTopReportersByQuality(t *testing.T) {
+	var tickets []jira.JiraIssue
+	report := func(reporter string, count int, steps, stackTrace bool) {
+		for i := 0; i < count; i++ {
+			ticket := jira.JiraIssue{HasStepsToReproduce: steps, HasStackTrace: stackTrace}
+			ticket.Fields.Reporter.Name = reporter
+			tickets = append(tickets, ticket)
+		}
+	}
+	report("dave", 4, false, false)
+	report("bob", 3, false, true)
+	report("alice", 3, true, true)
+	// carol's tickets are the best ones, but she reported too few of them to be ranked.
+	report("carol", 2, true, true)
+	report("", 3, true, true)
+
+	top := TopReportersByQuality(2, MinReporterTickets, tickets...)
+	if len(top) != 2 || top[0].Reporter != "alice" || top[1].Reporter != "bob" {
+		t.Fatalf("TopReportersByQuality = %+v, want alice then bob", top)
+	}
+	if top[0].Tickets != 3 || top[0].MeanQuality != 0.5 {
+		t.Errorf("alice's ranking = %+v, want 3 tickets of 0.5 quality", top[0])
+	}
+
+	var buf bytes.Buffer
+	if err := WriteReporterQualityCSV(&buf, TopReportersByQuality(0, MinReporterTickets, tickets...)); err != nil {
+		t.Fatalf("could not write ranking: %v", err)
+	}
+	want := "rank,reporter,tickets,mean_quality\n1,alice,3,0.50\n2,bob,3,0.20\n3,dave,4,0.00\n"
+	if buf.String() != want {
+		t.Errorf("ranking CSV = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,60 @@
+package analyze
+
+import (
+	"sort"
+	"time"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// TimeToFirstResponse returns the number of hours between the creation of a ticket and its first comment
+// written by someone other than its reporter, and whether the ticket received such a response at all.
+func TimeToFirstResponse(ticket jira.JiraIssue) (float64, bool) {
+	created := time.Time(ticket.Fields.Created)
+	var first time.Time
+	for _, comment := range ticket.Fields.Comments.Comments {
+		if comment.Author.Name != "" && comment.Author.Name == ticket.Fields.Reporter.Name {
+			continue
+		}
+		at := time.Time(comment.Created)
+		if at.Before(created) {
+			continue
+		}
+		if first.IsZero() || at.Before(first) {
+			first = at
+		}
+	}
+	if first.IsZero() {
+		return 0, false
+	}
+	return first.Sub(created).Hours(), true
+}
+
+// FirstResponseDistribution returns the time to first response, in hours, of each ticket which received a
+// response, along with the number of tickets which did not.
+func FirstResponseDistribution(tickets ...jira.JiraIssue) ([]float64, int) {
+	var hours []float64
+	var noResponse int
+	for _, ticket := range tickets {
+		h, ok := TimeToFirstResponse(ticket)
+		if !ok {
+			noResponse++
+			continue
+		}
+		hours = append(hours, h)
+	}
+	return hours, noResponse
+}
+
+// Percentiles returns the given percentiles, between 0 and 100, of vals using linear interpolation between
+// closest ranks, or NaN for each of them if vals is empty.
+func Percentiles(vals []float64, ps ...float64) []float64 {
+	sorted := make([]float64, len(vals))
+	copy(sorted, vals)
+	sort.Float64s(sorted)
+	result := make([]float64, len(ps))
+	for i, p := range ps {
+		result[i] = quantile(sorted, p/100)
+	}
+	return result
+}
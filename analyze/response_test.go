@@ -0,0 +1,40 @@
+package analyze
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestFirstResponseDistribution(t *testing.T) {
+	withComments := func(key string, comments ...jira.Comment) jira.JiraIssue {
+		ticket := jira.JiraIssue{Key: key}
+		ticket.Fields.Created = at(1, 0)
+		ticket.Fields.Reporter.Name = "alice"
+		ticket.Fields.Comments.Comments = comments
+		return ticket
+	}
+	comment := func(author string, hours float64) jira.Comment {
+		return jira.Comment{Author: jira.Author{Name: author}, Created: at(1, hours)}
+	}
+	tickets := []jira.JiraIssue{
+		// the reporter's own comments are not responses.
+		withComments("F-1", comment("alice", 1), comment("bob", 4)),
+		withComments("F-2", comment("carol", 10), comment("bob", 2)),
+		withComments("F-3", comment("alice", 3)),
+		withComments("F-4"),
+	}
+
+	hours, noResponse := FirstResponseDistribution(tickets...)
+	if want := []float64{4, 2}; !reflect.DeepEqual(hours, want) || noResponse != 2 {
+		t.Errorf("FirstResponseDistribution = %v, %d; want %v, 2", hours, noResponse, want)
+	}
+	if got, want := Percentiles(hours, 0, 50, 100), []float64{2, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Percentiles(%v) = %v, want %v", hours, got, want)
+	}
+	if got := Percentiles(nil, 50); !math.IsNaN(got[0]) {
+		t.Errorf("median without values = %v, want NaN", got[0])
+	}
+}
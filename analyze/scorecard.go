@@ -0,0 +1,106 @@
+package analyze
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// Scorecard summarizes the tickets resolved by a single assignee. Means without any contributing ticket are NaN.
+type Scorecard struct {
+	Resolved int
+	// MeanTimeToResolve holds the mean time-to-close, in hours, of the resolved tickets having one.
+	MeanTimeToResolve float64
+	// ReopenRate holds the fraction of the resolved tickets which were reopened at least once.
+	ReopenRate float64
+	// MeanFirstResponse holds the mean time to first response, in hours, of the resolved tickets having one.
+	MeanFirstResponse float64
+	MeanQuality       float64
+}
+
+// AssigneeScorecard returns, indexed by assignee name, the scorecard of the tickets each assignee resolved.
+// Unassigned and unresolved tickets are skipped.
+func AssigneeScorecard(tickets ...jira.JiraIssue) map[string]Scorecard {
+	type totals struct {
+		resolved, reopened, closed, responded int
+		timeToClose, firstResponse, quality   float64
+	}
+	perAssignee := make(map[string]*totals)
+	for _, ticket := range tickets {
+		assignee := ticket.Fields.Assignee.Name
+		if assignee == "" || !isResolved(ticket) {
+			continue
+		}
+		t, ok := perAssignee[assignee]
+		if !ok {
+			t = &totals{}
+			perAssignee[assignee] = t
+		}
+		t.resolved++
+		if ReopenCount(ticket) > 0 {
+			t.reopened++
+		}
+		if ticket.TimeToClose > 0 {
+			t.closed++
+			t.timeToClose += ticket.TimeToClose
+		}
+		if hours, ok := TimeToFirstResponse(ticket); ok {
+			t.responded++
+			t.firstResponse += hours
+		}
+		t.quality += TicketQualityScore(ticket)
+	}
+	mean := func(sum float64, count int) float64 {
+		if count == 0 {
+			return math.NaN()
+		}
+		return sum / float64(count)
+	}
+	cards := make(map[string]Scorecard, len(perAssignee))
+	for assignee, t := range perAssignee {
+		cards[assignee] = Scorecard{
+			Resolved:          t.resolved,
+			MeanTimeToResolve: mean(t.timeToClose, t.closed),
+			ReopenRate:        mean(float64(t.reopened), t.resolved),
+			MeanFirstResponse: mean(t.firstResponse, t.responded),
+			MeanQuality:       mean(t.quality, t.resolved),
+		}
+	}
+	return cards
+}
+
+// WriteScorecardsCSV writes the scorecards as CSV, one row per assignee sorted by name, preceded by a header.
+// NaN means are written as empty cells.
+func WriteScorecardsCSV(w io.Writer, cards map[string]Scorecard) error {
+	format := func(v float64) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	assignees := make([]string, 0, len(cards))
+	for assignee := range cards {
+		assignees = append(assignees, assignee)
+	}
+	sort.Strings(assignees)
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"assignee", "resolved", "mean_time_to_resolve_hours", "reopen_rate",
+		"mean_first_response_hours", "mean_quality"})
+	for _, assignee := range assignees {
+		card := cards[assignee]
+		writer.Write([]string{
+			assignee,
+			strconv.Itoa(card.Resolved),
+			format(card.MeanTimeToResolve),
+			format(card.ReopenRate),
+			format(card.MeanFirstResponse),
+			format(card.MeanQuality),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,54 @@
+package analyze
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestAssigneeScorecard(t *testing.T) {
+	resolvedBy := func(assignee string, ticket jira.JiraIssue, hours float64) jira.JiraIssue {
+		ticket.Fields.Assignee.Name = assignee
+		ticket.TimeToClose = hours
+		return ticket
+	}
+	responded := ticketWith("SC-1", "Closed", transition{10, "Open", "Closed"})
+	responded.Fields.Comments.Comments = []jira.Comment{{Author: jira.Author{Name: "bob"}, Created: at(1, 2)}}
+	responded.HasStepsToReproduce = true
+	reopened := ticketWith("SC-3", "Closed",
+		transition{20, "Open", "Closed"}, transition{40, "Closed", "Reopened"}, transition{100, "Reopened", "Closed"})
+
+	cards := AssigneeScorecard(
+		resolvedBy("alice", responded, 10),
+		resolvedBy("alice", ticketWith("SC-2", "Closed", transition{30, "Open", "Closed"}), 30),
+		resolvedBy("bob", reopened, 100),
+		// unresolved tickets are left out of the scorecards.
+		resolvedBy("alice", ticketWith("SC-4", "Open"), 0),
+	)
+	if len(cards) != 2 {
+		t.Fatalf("AssigneeScorecard = %+v, want scorecards for alice and bob", cards)
+	}
+	alice := cards["alice"]
+	if alice.Resolved != 2 || alice.MeanTimeToResolve != 20 || alice.ReopenRate != 0 || alice.MeanFirstResponse != 2 ||
+		math.Abs(alice.MeanQuality-0.15) > 1e-9 {
+		t.Errorf("alice's scorecard = %+v", alice)
+	}
+	bob := cards["bob"]
+	if bob.Resolved != 1 || bob.MeanTimeToResolve != 100 || bob.ReopenRate != 1 || !math.IsNaN(bob.MeanFirstResponse) ||
+		bob.MeanQuality != 0 {
+		t.Errorf("bob's scorecard = %+v", bob)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteScorecardsCSV(&buf, cards); err != nil {
+		t.Fatalf("could not write scorecards: %v", err)
+	}
+	want := "assignee,resolved,mean_time_to_resolve_hours,reopen_rate,mean_first_response_hours,mean_quality\n" +
+		"alice,2,20.00,0.00,2.00,0.15\n" +
+		"bob,1,100.00,1.00,,0.00\n"
+	if buf.String() != want {
+		t.Errorf("scorecards CSV = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,51 @@
+package analyze
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// NearDuplicateSimilarity defines the summary and description similarity above which the description of a
+// ticket is considered a copy of its summary.
+const NearDuplicateSimilarity = 0.8
+
+// SummaryDescriptionSimilarity returns the Jaccard similarity, between 0 and 1, of the case insensitive word
+// sets of a ticket's summary and description; a ticket without a description has a similarity of 0.
+func SummaryDescriptionSimilarity(ticket jira.JiraIssue) float64 {
+	summary := tokenSet(ticket.Fields.Summary)
+	description := tokenSet(ticket.Fields.Description)
+	if len(description) == 0 || len(summary) == 0 {
+		return 0
+	}
+	var common int
+	for token := range summary {
+		if description[token] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(summary)+len(description)-common)
+}
+
+// NearDuplicateDescriptions returns the keys of the tickets whose description is a near duplicate of their summary.
+func NearDuplicateDescriptions(tickets ...jira.JiraIssue) []string {
+	var keys []string
+	for _, ticket := range tickets {
+		if SummaryDescriptionSimilarity(ticket) >= NearDuplicateSimilarity {
+			keys = append(keys, ticket.Key)
+		}
+	}
+	return keys
+}
+
+// tokenSet returns the set of lowercased words, made of letters and digits only, found in s.
+func tokenSet(s string) map[string]bool {
+	tokens := make(map[string]bool)
+	for _, token := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		tokens[token] = true
+	}
+	return tokens
+}
@@ -0,0 +1,40 @@
+package analyze
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestSummaryDescriptionSimilarity(t *testing.T) {
+	newTicket := func(key, summary, description string) jira.JiraIssue {
+		ticket := jira.JiraIssue{Key: key}
+		ticket.Fields.Summary = summary
+		ticket.Fields.Description = description
+		return ticket
+	}
+	identical := newTicket("D-1", "Login fails on Safari", "login fails on safari.")
+	partial := newTicket("D-2", "Login fails on Safari", "The login page fails to load")
+	disjoint := newTicket("D-3", "Login fails on Safari", "Export times out")
+	empty := newTicket("D-4", "Login fails on Safari", "")
+
+	for _, tc := range []struct {
+		ticket jira.JiraIssue
+		want   float64
+	}{
+		{identical, 1},
+		// login and fails are shared out of 8 distinct words.
+		{partial, 2.0 / 8},
+		{disjoint, 0},
+		{empty, 0},
+	} {
+		if got := SummaryDescriptionSimilarity(tc.ticket); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("SummaryDescriptionSimilarity(%s) = %v, want %v", tc.ticket.Key, got, tc.want)
+		}
+	}
+	if got := NearDuplicateDescriptions(identical, partial, disjoint, empty); !reflect.DeepEqual(got, []string{"D-1"}) {
+		t.Errorf("NearDuplicateDescriptions = %v, want [D-1]", got)
+	}
+}
@@ -0,0 +1,114 @@
+package analyze
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+const (
+	// spamMinSummaryChars defines the number of characters below which a summary is too short to be real.
+	spamMinSummaryChars = 4
+	// spamMaxDescriptionWords defines the number of description words up to which a junk summary marks a
+	// ticket as spam; longer descriptions are assumed to carry a real report.
+	spamMaxDescriptionWords = 5
+)
+
+// spamKeywords holds the words test and junk tickets are typically made of, including the filler words
+// surrounding them, e.g. "this is a test ticket, please ignore".
+var spamKeywords = map[string]bool{
+	"test": true, "testing": true, "tst": true, "asdf": true, "qwerty": true, "foo": true, "bar": true,
+	"dummy": true, "ignore": true, "please": true, "lorem": true, "ipsum": true, "xxx": true, "todo": true,
+	"sample": true, "hello": true, "world": true, "ticket": true, "issue": true, "this": true, "is": true,
+	"a": true, "just": true, "new": true,
+}
+
+// keyboardMashes holds letter sequences produced by running a finger along a keyboard row.
+var keyboardMashes = []string{"asdf", "sdfg", "dfgh", "hjkl", "jkl;", "qwer", "wert", "zxcv", "uiop"}
+
+// SpamDetector defines a Scorer flagging test, placeholder and auto-generated junk tickets as spam.
+type SpamDetector struct{}
+
+// NewSpamDetector returns a new spam detector.
+func NewSpamDetector() *SpamDetector {
+	return &SpamDetector{}
+}
+
+// Scores sets the IsSpam flag of all tickets given as input parameters.
+func (d *SpamDetector) Scores(tickets ...jira.JiraIssue) error {
+	for i := range tickets {
+		tickets[i].IsSpam = IsSpam(tickets[i].Fields.Summary, tickets[i].Fields.Description)
+	}
+	return nil
+}
+
+// IsSpam returns whether a ticket summary and description look like a test or junk ticket: the summary is
+// either very short or only made of test keywords, keyboard mashes, repeated characters or words without any
+// vowel, and the description holds barely any words.
+func IsSpam(summary, description string) bool {
+	summary = strings.TrimSpace(summary)
+	if len([]rune(summary)) < spamMinSummaryChars {
+		return true
+	}
+	if calculateNumberOfWords(description) > spamMaxDescriptionWords {
+		return false
+	}
+	words := strings.FieldsFunc(strings.ToLower(summary), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return true
+	}
+	for _, word := range words {
+		if !isJunkWord(word) {
+			return false
+		}
+	}
+	return true
+}
+
+// isJunkWord returns whether a lowercase word is a test keyword, a keyboard mash, a repetition of a shorter
+// sequence (e.g. aaaa or asdfasdf) or a word without any vowel.
+func isJunkWord(word string) bool {
+	if spamKeywords[word] || isRepetition(word) {
+		return true
+	}
+	for _, mash := range keyboardMashes {
+		if strings.Contains(word, mash) {
+			return true
+		}
+	}
+	if len(word) > 3 && !strings.ContainsAny(word, "aeiouy") {
+		return true
+	}
+	return false
+}
+
+// isRepetition returns whether a word of at least three characters is made of a shorter sequence repeated.
+func isRepetition(word string) bool {
+	runes := []rune(word)
+	if len(runes) < 3 {
+		return false
+	}
+	for size := 1; size <= len(runes)/2; size++ {
+		if len(runes)%size != 0 {
+			continue
+		}
+		if strings.Repeat(string(runes[:size]), len(runes)/size) == word {
+			return true
+		}
+	}
+	return false
+}
+
+// ExcludeSpam returns the tickets which are not flagged as spam, alongside the number of excluded tickets.
+func ExcludeSpam(tickets ...jira.JiraIssue) ([]jira.JiraIssue, int) {
+	var result []jira.JiraIssue
+	for _, ticket := range tickets {
+		if !ticket.IsSpam {
+			result = append(result, ticket)
+		}
+	}
+	return result, len(tickets) - len(result)
+}
@@ -0,0 +1,47 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestIsSpam(t *testing.T) {
+	for _, tc := range []struct {
+		summary, description string
+		want                 bool
+	}{
+		{"asdfasdf test", "", true},
+		{"This is a test ticket, please ignore", "", true},
+		{"aaaa bbbb", "", true},
+		{"ab", "", true},
+		{"!!!!", "", true},
+		{"Login fails after password reset", "", false},
+		{"Crash in xyz module", "", false},
+		// a junk summary does not make spam out of a ticket with a real description.
+		{"test", "Saving a draft twice in a row loses the second version", false},
+	} {
+		if got := IsSpam(tc.summary, tc.description); got != tc.want {
+			t.Errorf("IsSpam(%q, %q) = %v, want %v", tc.summary, tc.description, got, tc.want)
+		}
+	}
+}
+
+func TestSpamDetector(t *testing.T) {
+	junk := jira.JiraIssue{Key: "S-1"}
+	junk.Fields.Summary = "asdfasdf test"
+	real := jira.JiraIssue{Key: "S-2"}
+	real.Fields.Summary = "Login fails after password reset"
+	tickets := []jira.JiraIssue{junk, real}
+
+	if err := NewSpamDetector().Scores(tickets...); err != nil {
+		t.Fatalf("could not score tickets: %v", err)
+	}
+	if !tickets[0].IsSpam || tickets[1].IsSpam {
+		t.Errorf("spam flags = %v and %v, want only the first ticket flagged", tickets[0].IsSpam, tickets[1].IsSpam)
+	}
+	kept, excluded := ExcludeSpam(tickets...)
+	if excluded != 1 || len(kept) != 1 || kept[0].Key != "S-2" {
+		t.Errorf("ExcludeSpam kept %v and excluded %d, want S-2 and 1", kept, excluded)
+	}
+}
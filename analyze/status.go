@@ -0,0 +1,282 @@
+package analyze
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// statusTransition defines a change of a ticket's status found inside its changelog.
+type statusTransition struct {
+	At   time.Time
+	From string
+	To   string
+}
+
+// statusTransitions returns the status transitions of a ticket in chronological order.
+func statusTransitions(ticket jira.JiraIssue) []statusTransition {
+	var transitions []statusTransition
+	for _, history := range ticket.Changelog.Histories {
+		for _, item := range history.Items {
+			if item.Field == "status" {
+				transitions = append(transitions, statusTransition{
+					At:   time.Time(history.Created),
+					From: item.FromString,
+					To:   item.ToString,
+				})
+			}
+		}
+	}
+	sort.SliceStable(transitions, func(i, j int) bool {
+		return transitions[i].At.Before(transitions[j].At)
+	})
+	return transitions
+}
+
+// TimeInStatus returns the number of hours a ticket spent in each of its statuses from its creation until the
+// given time, based on the status transitions of its changelog.
+func TimeInStatus(ticket jira.JiraIssue, until time.Time) map[string]float64 {
+	return timeInStatus(ticket, until, func(start, end time.Time) float64 {
+		return end.Sub(start).Hours()
+	})
+}
+
+// timeInStatus returns the time spent in each status until the given time, with the hours between two times
+// counted by hoursBetween.
+func timeInStatus(
+	ticket jira.JiraIssue,
+	until time.Time,
+	hoursBetween func(start, end time.Time) float64) map[string]float64 {
+
+	result := make(map[string]float64)
+	transitions := statusTransitions(ticket)
+	since := time.Time(ticket.Fields.Created)
+	current := ticket.Fields.Status.Name
+	if len(transitions) > 0 {
+		current = transitions[0].From
+	}
+	for _, t := range transitions {
+		if !t.At.Before(until) {
+			break
+		}
+		if t.At.After(since) {
+			result[current] += hoursBetween(since, t.At)
+			since = t.At
+		}
+		current = t.To
+	}
+	if until.After(since) {
+		result[current] += hoursBetween(since, until)
+	}
+	return result
+}
+
+// DominantStatus returns the status in which a ticket spent the largest fraction of its life, from its creation
+// until it was resolved or until now, alongside that fraction. ok is false if the ticket has no status history
+// or no time elapsed since its creation.
+func DominantStatus(ticket jira.JiraIssue) (status string, fraction float64, ok bool) {
+	if len(statusTransitions(ticket)) == 0 {
+		return "", 0, false
+	}
+	var total, longest float64
+	for s, hours := range TimeInStatus(ticket, lifetimeEnd(ticket)) {
+		total += hours
+		if hours > longest || (hours == longest && s < status) {
+			status, longest = s, hours
+		}
+	}
+	if total <= 0 {
+		return "", 0, false
+	}
+	return status, longest / total, true
+}
+
+// LeadTime returns the number of hours from the creation of a ticket until it first moved out of its initial
+// status, and false if it never did.
+func LeadTime(ticket jira.JiraIssue) (float64, bool) {
+	transitions := statusTransitions(ticket)
+	if len(transitions) == 0 {
+		return 0, false
+	}
+	initial := transitions[0].From
+	for _, t := range transitions {
+		if t.To != initial {
+			return t.At.Sub(time.Time(ticket.Fields.Created)).Hours(), true
+		}
+	}
+	return 0, false
+}
+
+// ActiveVsWaiting returns the number of hours a ticket spent, from its creation until it was resolved or until
+// now, in any of the waiting statuses (e.g. Waiting for Customer), compared case insensitively, and in all other
+// statuses.
+func ActiveVsWaiting(ticket jira.JiraIssue, waitingStatuses []string) (active, waiting float64) {
+	for status, hours := range TimeInStatus(ticket, lifetimeEnd(ticket)) {
+		isWaiting := false
+		for _, w := range waitingStatuses {
+			if strings.EqualFold(strings.TrimSpace(w), status) {
+				isWaiting = true
+				break
+			}
+		}
+		if isWaiting {
+			waiting += hours
+		} else {
+			active += hours
+		}
+	}
+	return active, waiting
+}
+
+// lifetimeEnd returns the time a ticket was resolved at or now if it is not resolved.
+func lifetimeEnd(ticket jira.JiraIssue) time.Time {
+	if resolvedAt, ok := resolutionTime(ticket); ok {
+		return resolvedAt
+	}
+	return time.Now()
+}
+
+// ReopenCount returns the number of times a ticket transitioned from a terminal status back to a non-terminal one.
+func ReopenCount(ticket jira.JiraIssue) int {
+	var count int
+	for _, t := range statusTransitions(ticket) {
+		if wasTerminal(ticket, t.From) && !wasTerminal(ticket, t.To) {
+			count++
+		}
+	}
+	return count
+}
+
+// FrequentlyReopened returns the keys of the tickets reopened at least threshold times, most reopened first and
+// ties broken by key, as red flags for a quality review.
+func FrequentlyReopened(threshold int, tickets ...jira.JiraIssue) []string {
+	counts := make(map[string]int)
+	var keys []string
+	for _, ticket := range tickets {
+		if n := ReopenCount(ticket); n >= threshold && n > 0 {
+			counts[ticket.Key] = n
+			keys = append(keys, ticket.Key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// ReopenDistribution returns, indexed by number of reopens, the number of tickets reopened that many times.
+func ReopenDistribution(tickets ...jira.JiraIssue) map[int]int {
+	result := make(map[int]int)
+	for _, ticket := range tickets {
+		result[ReopenCount(ticket)]++
+	}
+	return result
+}
+
+// ReopenRateByAssignee returns, indexed by assignee name, the fraction of each assignee's resolved tickets
+// which were reopened at least once. Unassigned tickets and assignees without resolved tickets are skipped.
+func ReopenRateByAssignee(tickets ...jira.JiraIssue) map[string]float64 {
+	resolved := make(map[string]int)
+	reopened := make(map[string]int)
+	for _, ticket := range tickets {
+		assignee := ticket.Fields.Assignee.Name
+		if assignee == "" || !isResolved(ticket) {
+			continue
+		}
+		resolved[assignee]++
+		if ReopenCount(ticket) > 0 {
+			reopened[assignee]++
+		}
+	}
+	rates := make(map[string]float64, len(resolved))
+	for assignee, count := range resolved {
+		rates[assignee] = float64(reopened[assignee]) / float64(count)
+	}
+	return rates
+}
+
+// ReopenRateByDescriptionLength returns, indexed by the lower bound of each bucket of bucketSize description
+// words, the fraction of the resolved tickets whose description word count falls into that bucket which were
+// reopened at least once. Buckets without resolved tickets are left out.
+func ReopenRateByDescriptionLength(bucketSize int, tickets ...jira.JiraIssue) (map[int]float64, error) {
+	if bucketSize <= 0 {
+		return nil, fmt.Errorf("description length bucket size must be positive, got %d", bucketSize)
+	}
+	resolved := make(map[int]int)
+	reopened := make(map[int]int)
+	for _, ticket := range tickets {
+		if !isResolved(ticket) {
+			continue
+		}
+		bucket := DescriptionWords(ticket) / bucketSize * bucketSize
+		resolved[bucket]++
+		if ReopenCount(ticket) > 0 {
+			reopened[bucket]++
+		}
+	}
+	rates := make(map[int]float64, len(resolved))
+	for bucket, count := range resolved {
+		rates[bucket] = float64(reopened[bucket]) / float64(count)
+	}
+	return rates, nil
+}
+
+// wasTerminal returns whether a status found inside the changelog of a ticket is a terminal one, either because
+// it is the ticket's current terminal status or because its name is a well known terminal status.
+func wasTerminal(ticket jira.JiraIssue, status string) bool {
+	return (isResolved(ticket) && isTerminalStatus(ticket, status)) || terminalStatuses[status]
+}
+
+// Stats holds descriptive statistics of a sample of durations, in hours.
+type Stats struct {
+	Count  int
+	Mean   float64
+	Median float64
+	Min    float64
+	Max    float64
+}
+
+// newStats computes the descriptive statistics of a non-empty sample.
+func newStats(vals []float64) Stats {
+	sorted := make([]float64, len(vals))
+	copy(sorted, vals)
+	sort.Float64s(sorted)
+	var total float64
+	for _, v := range sorted {
+		total += v
+	}
+	return Stats{
+		Count:  len(sorted),
+		Mean:   total / float64(len(sorted)),
+		Median: quantile(sorted, 0.5),
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+// TransitionDurations returns, for every "From→To" status transition found across the tickets' changelogs, the
+// statistics of the number of hours spent in the From status before transitioning to the To status. The time
+// spent in the first status is measured from the creation of the ticket.
+func TransitionDurations(tickets ...jira.JiraIssue) map[string]Stats {
+	durations := make(map[string][]float64)
+	for _, ticket := range tickets {
+		since := time.Time(ticket.Fields.Created)
+		for _, t := range statusTransitions(ticket) {
+			key := t.From + "→" + t.To
+			durations[key] = append(durations[key], math.Max(0, t.At.Sub(since).Hours()))
+			since = t.At
+		}
+	}
+	result := make(map[string]Stats, len(durations))
+	for key, vals := range durations {
+		result[key] = newStats(vals)
+	}
+	return result
+}
@@ -0,0 +1,153 @@
+package analyze
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestTransitionDurations(t *testing.T) {
+	reviewed := ticketWith("T-1", "Closed",
+		transition{2, "Open", "In Progress"},
+		transition{5, "In Progress", "Review"},
+		transition{6, "Review", "In Progress"},
+		transition{10, "In Progress", "Closed"},
+	)
+	direct := ticketWith("T-2", "Closed", transition{4, "Open", "In Progress"}, transition{12, "In Progress", "Closed"})
+
+	want := map[string]Stats{
+		"Open→In Progress":   {Count: 2, Mean: 3, Median: 3, Min: 2, Max: 4},
+		"In Progress→Review": {Count: 1, Mean: 3, Median: 3, Min: 3, Max: 3},
+		"Review→In Progress": {Count: 1, Mean: 1, Median: 1, Min: 1, Max: 1},
+		"In Progress→Closed": {Count: 2, Mean: 6, Median: 6, Min: 4, Max: 8},
+	}
+	if got := TransitionDurations(reviewed, direct, jira.JiraIssue{Key: "T-3"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("TransitionDurations = %v, want %v", got, want)
+	}
+}
+
+func TestReopenRateByAssignee(t *testing.T) {
+	assigned := func(assignee string, ticket jira.JiraIssue) jira.JiraIssue {
+		ticket.Fields.Assignee.Name = assignee
+		return ticket
+	}
+	reopened := []transition{{2, "Open", "Closed"}, {4, "Closed", "Reopened"}, {6, "Reopened", "Closed"}}
+	tickets := []jira.JiraIssue{
+		assigned("alice", ticketWith("R-1", "Closed", reopened...)),
+		assigned("alice", ticketWith("R-2", "Closed", transition{2, "Open", "Closed"})),
+		assigned("bob", ticketWith("R-3", "Closed", reopened...)),
+		// unresolved and unassigned tickets do not count towards any rate.
+		assigned("bob", ticketWith("R-4", "Reopened", transition{2, "Open", "Closed"}, transition{4, "Closed", "Reopened"})),
+		assigned("carol", ticketWith("R-5", "Open")),
+		ticketWith("R-6", "Closed", reopened...),
+	}
+	want := map[string]float64{"alice": 0.5, "bob": 1}
+	if got := ReopenRateByAssignee(tickets...); !reflect.DeepEqual(got, want) {
+		t.Errorf("ReopenRateByAssignee = %v, want %v", got, want)
+	}
+}
+
+func TestReopenRateByDescriptionLength(t *testing.T) {
+	described := func(words int, ticket jira.JiraIssue) jira.JiraIssue {
+		ticket.HasWordCounts = true
+		ticket.DescriptionWordsCount = words
+		return ticket
+	}
+	reopened := []transition{{2, "Open", "Closed"}, {4, "Closed", "Reopened"}, {6, "Reopened", "Closed"}}
+	closed := transition{2, "Open", "Closed"}
+	tickets := []jira.JiraIssue{
+		// terse tickets get reopened more often than the detailed ones.
+		described(3, ticketWith("L-1", "Closed", reopened...)),
+		described(9, ticketWith("L-2", "Closed", reopened...)),
+		described(0, ticketWith("L-3", "Closed", closed)),
+		described(10, ticketWith("L-4", "Closed", reopened...)),
+		described(19, ticketWith("L-5", "Closed", closed)),
+		described(45, ticketWith("L-6", "Closed", closed)),
+		// unresolved tickets do not count towards any rate.
+		described(4, ticketWith("L-7", "Open")),
+	}
+	got, err := ReopenRateByDescriptionLength(10, tickets...)
+	if err != nil {
+		t.Fatalf("could not compute reopen rates: %v", err)
+	}
+	if want := map[int]float64{0: 2.0 / 3, 10: 0.5, 40: 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReopenRateByDescriptionLength = %v, want %v", got, want)
+	}
+
+	if _, err := ReopenRateByDescriptionLength(0, tickets...); err == nil {
+		t.Error("expected an error for a non-positive bucket size")
+	}
+}
+
+func TestDominantStatus(t *testing.T) {
+	waiting := ticketWith("D-1", "Closed",
+		transition{2, "Open", "In Progress"},
+		transition{5, "In Progress", "Waiting for Customer"},
+		transition{20, "Waiting for Customer", "Closed"},
+	)
+	status, fraction, ok := DominantStatus(waiting)
+	if !ok || status != "Waiting for Customer" || fraction != 0.75 {
+		t.Errorf("DominantStatus = (%q, %v, %v), want Waiting for Customer for 0.75 of the ticket's life",
+			status, fraction, ok)
+	}
+
+	if status, fraction, ok := DominantStatus(ticketWith("D-2", "Open")); ok {
+		t.Errorf("DominantStatus without status history = (%q, %v), want not ok", status, fraction)
+	}
+}
+
+func TestActiveVsWaiting(t *testing.T) {
+	ticket := ticketWith("AW-1", "Closed",
+		transition{2, "Open", "In Progress"},
+		transition{5, "In Progress", "Waiting for Customer"},
+		transition{15, "Waiting for Customer", "In Progress"},
+		transition{24, "In Progress", "Closed"},
+	)
+	if active, waiting := ActiveVsWaiting(ticket, []string{" waiting for customer "}); active != 14 || waiting != 10 {
+		t.Errorf("ActiveVsWaiting = (%v, %v), want 14 active and 10 waiting hours", active, waiting)
+	}
+	if active, waiting := ActiveVsWaiting(ticket, nil); active != 24 || waiting != 0 {
+		t.Errorf("ActiveVsWaiting without waiting statuses = (%v, %v), want 24 active hours", active, waiting)
+	}
+}
+
+func TestLeadTime(t *testing.T) {
+	started := ticketWith("LT-1", "Closed",
+		transition{6, "Open", "In Progress"},
+		transition{30, "In Progress", "Closed"},
+	)
+	if hours, ok := LeadTime(started); !ok || hours != 6 {
+		t.Errorf("LeadTime = (%v, %v), want 6 hours", hours, ok)
+	}
+	if hours, ok := LeadTime(ticketWith("LT-2", "Open")); ok {
+		t.Errorf("LeadTime of a ticket that never left Open = %v, want none", hours)
+	}
+}
+
+func TestFrequentlyReopened(t *testing.T) {
+	reopened := func(key string, times int) jira.JiraIssue {
+		transitions := []transition{{1, "Open", "Closed"}}
+		for i := 0; i < times; i++ {
+			hours := float64(2*i + 2)
+			transitions = append(transitions, transition{hours, "Closed", "Reopened"},
+				transition{hours + 1, "Reopened", "Closed"})
+		}
+		return ticketWith(key, "Closed", transitions...)
+	}
+	tickets := []jira.JiraIssue{reopened("R-0", 0), reopened("R-4", 1), reopened("R-3", 3), reopened("R-1", 1)}
+
+	for threshold, want := range map[int][]string{
+		0: {"R-3", "R-1", "R-4"},
+		1: {"R-3", "R-1", "R-4"},
+		2: {"R-3"},
+		4: nil,
+	} {
+		if got := FrequentlyReopened(threshold, tickets...); !reflect.DeepEqual(got, want) {
+			t.Errorf("FrequentlyReopened(%d) = %v, want %v", threshold, got, want)
+		}
+	}
+	if got, want := ReopenDistribution(tickets...), map[int]int{0: 1, 1: 2, 3: 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReopenDistribution = %v, want %v", got, want)
+	}
+}
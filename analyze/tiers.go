@@ -0,0 +1,48 @@
+package analyze
+
+import (
+	"sort"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// Resolution speed tiers assigned by SpeedTier.
+const (
+	TierFast   = "fast"
+	TierNormal = "normal"
+	TierSlow   = "slow"
+)
+
+// SpeedTier labels each closed ticket, indexed by key, as fast if its time-to-close is at most the first quartile
+// of all closed tickets, slow if it is at least the third quartile and normal otherwise. It also returns the
+// number of tickets inside each tier.
+func SpeedTier(tickets ...jira.JiraIssue) (map[string]string, map[string]int) {
+	var times []float64
+	for _, t := range tickets {
+		if t.TimeToClose > 0 {
+			times = append(times, t.TimeToClose)
+		}
+	}
+	tiers := make(map[string]string)
+	counts := map[string]int{TierFast: 0, TierNormal: 0, TierSlow: 0}
+	if len(times) == 0 {
+		return tiers, counts
+	}
+	sort.Float64s(times)
+	q1, q3 := quantile(times, 0.25), quantile(times, 0.75)
+	for _, t := range tickets {
+		if t.TimeToClose <= 0 {
+			continue
+		}
+		tier := TierNormal
+		switch {
+		case t.TimeToClose <= q1:
+			tier = TierFast
+		case t.TimeToClose >= q3:
+			tier = TierSlow
+		}
+		tiers[t.Key] = tier
+		counts[tier]++
+	}
+	return tiers, counts
+}
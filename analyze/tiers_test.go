@@ -0,0 +1,36 @@
+package analyze
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestSpeedTier(t *testing.T) {
+	// times-to-close of 10 to 90 hours have their first quartile at 30 and their third one at 70.
+	var tickets []jira.JiraIssue
+	for i := 1; i <= 9; i++ {
+		tickets = append(tickets, jira.JiraIssue{Key: fmt.Sprintf("S-%d", i), TimeToClose: float64(10 * i)})
+	}
+	tickets = append(tickets, jira.JiraIssue{Key: "S-open"})
+
+	tiers, counts := SpeedTier(tickets...)
+	want := map[string]string{
+		"S-1": TierFast, "S-2": TierFast, "S-3": TierFast,
+		"S-4": TierNormal, "S-5": TierNormal, "S-6": TierNormal,
+		"S-7": TierSlow, "S-8": TierSlow, "S-9": TierSlow,
+	}
+	if !reflect.DeepEqual(tiers, want) {
+		t.Errorf("SpeedTier tiers = %v, want %v", tiers, want)
+	}
+	if want := map[string]int{TierFast: 3, TierNormal: 3, TierSlow: 3}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("SpeedTier counts = %v, want %v", counts, want)
+	}
+
+	tiers, counts = SpeedTier(jira.JiraIssue{Key: "S-open"})
+	if len(tiers) != 0 || counts[TierFast]+counts[TierNormal]+counts[TierSlow] != 0 {
+		t.Errorf("SpeedTier without closed tickets = %v, %v, want no tiers", tiers, counts)
+	}
+}
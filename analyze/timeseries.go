@@ -0,0 +1,399 @@
+package analyze
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// CumulativeResolved returns the start of each time bucket between the first and the last ticket resolution,
+// along with the total number of tickets resolved by the end of that bucket. Unresolved tickets are excluded.
+func CumulativeResolved(bucket time.Duration, tickets ...jira.JiraIssue) ([]time.Time, []int) {
+	var resolutions []time.Time
+	for _, ticket := range tickets {
+		if resolvedAt, ok := resolutionTime(ticket); ok {
+			resolutions = append(resolutions, resolvedAt)
+		}
+	}
+	if len(resolutions) == 0 || bucket <= 0 {
+		return nil, nil
+	}
+	sort.Slice(resolutions, func(i, j int) bool {
+		return resolutions[i].Before(resolutions[j])
+	})
+	var buckets []time.Time
+	var counts []int
+	var count int
+	last := resolutions[len(resolutions)-1]
+	for start := resolutions[0].Truncate(bucket); !start.After(last); start = start.Add(bucket) {
+		end := start.Add(bucket)
+		for count < len(resolutions) && resolutions[count].Before(end) {
+			count++
+		}
+		buckets = append(buckets, start)
+		counts = append(counts, count)
+	}
+	return buckets, counts
+}
+
+// TrendBucket holds the resolutions of a time bucket along with the rolling mean time-to-close ending with it.
+type TrendBucket struct {
+	Start           time.Time
+	Count           int
+	MeanTimeToClose float64
+	RollingMean     float64
+}
+
+// ResolutionTrend groups the closed tickets into time buckets by their resolution time and computes, for each
+// bucket, the mean time-to-close of its tickets and the rolling mean over the last window buckets. The rolling
+// mean weighs every non-empty bucket equally or, if volumeWeighted is true, by its number of tickets so that
+// sparse buckets do not make the trend noisy. Buckets without tickets carry the previous rolling mean over.
+func ResolutionTrend(bucket time.Duration, window int, volumeWeighted bool, tickets ...jira.JiraIssue) []TrendBucket {
+	type resolution struct {
+		at  time.Time
+		ttc float64
+	}
+	var resolutions []resolution
+	for _, ticket := range tickets {
+		if ticket.TimeToClose <= 0 {
+			continue
+		}
+		if resolvedAt, ok := resolutionTime(ticket); ok {
+			resolutions = append(resolutions, resolution{resolvedAt, ticket.TimeToClose})
+		}
+	}
+	if len(resolutions) == 0 || bucket <= 0 || window <= 0 {
+		return nil
+	}
+	sort.Slice(resolutions, func(i, j int) bool {
+		return resolutions[i].at.Before(resolutions[j].at)
+	})
+	var buckets []TrendBucket
+	var next int
+	last := resolutions[len(resolutions)-1].at
+	for start := resolutions[0].at.Truncate(bucket); !start.After(last); start = start.Add(bucket) {
+		b := TrendBucket{Start: start}
+		var total float64
+		for next < len(resolutions) && resolutions[next].at.Before(start.Add(bucket)) {
+			total += resolutions[next].ttc
+			b.Count++
+			next++
+		}
+		if b.Count > 0 {
+			b.MeanTimeToClose = total / float64(b.Count)
+		}
+		buckets = append(buckets, b)
+	}
+	for i := range buckets {
+		var sum, weights float64
+		for j := i; j >= 0 && j > i-window; j-- {
+			if buckets[j].Count == 0 {
+				continue
+			}
+			weight := 1.0
+			if volumeWeighted {
+				weight = float64(buckets[j].Count)
+			}
+			sum += weight * buckets[j].MeanTimeToClose
+			weights += weight
+		}
+		switch {
+		case weights > 0:
+			buckets[i].RollingMean = sum / weights
+		case i > 0:
+			buckets[i].RollingMean = buckets[i-1].RollingMean
+		}
+	}
+	return buckets
+}
+
+// BacklogOverTime returns the start of each time bucket between the first ticket creation and the last ticket
+// creation or resolution, along with the number of tickets created but not yet resolved by the end of that bucket.
+func BacklogOverTime(bucket time.Duration, tickets ...jira.JiraIssue) ([]time.Time, []int) {
+	if len(tickets) == 0 || bucket <= 0 {
+		return nil, nil
+	}
+	var created, resolved []time.Time
+	for _, ticket := range tickets {
+		created = append(created, time.Time(ticket.Fields.Created))
+		if resolvedAt, ok := resolutionTime(ticket); ok {
+			resolved = append(resolved, resolvedAt)
+		}
+	}
+	sort.Slice(created, func(i, j int) bool {
+		return created[i].Before(created[j])
+	})
+	sort.Slice(resolved, func(i, j int) bool {
+		return resolved[i].Before(resolved[j])
+	})
+	last := created[len(created)-1]
+	if len(resolved) > 0 && resolved[len(resolved)-1].After(last) {
+		last = resolved[len(resolved)-1]
+	}
+	var buckets []time.Time
+	var counts []int
+	var createdCount, resolvedCount int
+	for start := created[0].Truncate(bucket); !start.After(last); start = start.Add(bucket) {
+		end := start.Add(bucket)
+		for createdCount < len(created) && created[createdCount].Before(end) {
+			createdCount++
+		}
+		for resolvedCount < len(resolved) && resolved[resolvedCount].Before(end) {
+			resolvedCount++
+		}
+		buckets = append(buckets, start)
+		counts = append(counts, createdCount-resolvedCount)
+	}
+	return buckets, counts
+}
+
+// CreationRate returns the start of each time bucket between the first and the last ticket creation, along
+// with the number of tickets created during that bucket.
+func CreationRate(bucket time.Duration, tickets ...jira.JiraIssue) ([]time.Time, []int) {
+	if len(tickets) == 0 || bucket <= 0 {
+		return nil, nil
+	}
+	created := creationTimes(tickets...)
+	var buckets []time.Time
+	var counts []int
+	var count int
+	last := created[len(created)-1]
+	for start := created[0].Truncate(bucket); !start.After(last); start = start.Add(bucket) {
+		end := start.Add(bucket)
+		var n int
+		for ; count < len(created) && created[count].Before(end); count++ {
+			n++
+		}
+		buckets = append(buckets, start)
+		counts = append(counts, n)
+	}
+	return buckets, counts
+}
+
+// MeanInterArrival returns the mean time elapsed between two consecutive ticket creations and false if fewer
+// than two tickets are given.
+func MeanInterArrival(tickets ...jira.JiraIssue) (time.Duration, bool) {
+	if len(tickets) < 2 {
+		return 0, false
+	}
+	created := creationTimes(tickets...)
+	return created[len(created)-1].Sub(created[0]) / time.Duration(len(created)-1), true
+}
+
+// creationTimes returns the creation times of the tickets in chronological order.
+func creationTimes(tickets ...jira.JiraIssue) []time.Time {
+	created := make([]time.Time, len(tickets))
+	for i, ticket := range tickets {
+		created[i] = time.Time(ticket.Fields.Created)
+	}
+	sort.Slice(created, func(i, j int) bool {
+		return created[i].Before(created[j])
+	})
+	return created
+}
+
+// WeeklyThroughput returns the start, on Monday at midnight UTC, of each ISO week from the first to the last
+// one in which a ticket was resolved, along with the number of tickets resolved during that week.
+func WeeklyThroughput(tickets ...jira.JiraIssue) ([]time.Time, []int) {
+	perWeek := make(map[time.Time]int)
+	var first, last time.Time
+	for _, ticket := range tickets {
+		resolvedAt, ok := resolutionTime(ticket)
+		if !ok {
+			continue
+		}
+		week := isoWeekStart(resolvedAt)
+		if len(perWeek) == 0 || week.Before(first) {
+			first = week
+		}
+		if len(perWeek) == 0 || week.After(last) {
+			last = week
+		}
+		perWeek[week]++
+	}
+	if len(perWeek) == 0 {
+		return nil, nil
+	}
+	var weeks []time.Time
+	var counts []int
+	for week := first; !week.After(last); week = week.AddDate(0, 0, 7) {
+		weeks = append(weeks, week)
+		counts = append(counts, perWeek[week])
+	}
+	return weeks, counts
+}
+
+// CreationDayOfWeek returns the times-to-close of closed tickets bucketed by the weekday, in loc, on which they
+// were created; loc defaults to UTC if nil.
+func CreationDayOfWeek(loc *time.Location, tickets ...jira.JiraIssue) map[time.Weekday][]float64 {
+	if loc == nil {
+		loc = time.UTC
+	}
+	result := make(map[time.Weekday][]float64)
+	for _, ticket := range tickets {
+		if ticket.TimeToClose <= 0 {
+			continue
+		}
+		day := time.Time(ticket.Fields.Created).In(loc).Weekday()
+		result[day] = append(result[day], ticket.TimeToClose)
+	}
+	return result
+}
+
+// MonthlyResolutionStats returns the statistics of the times-to-close of closed tickets grouped by the calendar
+// month, in UTC, they were created in, regardless of the year, to reveal seasonal patterns.
+func MonthlyResolutionStats(tickets ...jira.JiraIssue) map[time.Month]Stats {
+	perMonth := make(map[time.Month][]float64)
+	for _, ticket := range tickets {
+		if ticket.TimeToClose <= 0 {
+			continue
+		}
+		month := time.Time(ticket.Fields.Created).UTC().Month()
+		perMonth[month] = append(perMonth[month], ticket.TimeToClose)
+	}
+	result := make(map[time.Month]Stats, len(perMonth))
+	for month, times := range perMonth {
+		result[month] = newStats(times)
+	}
+	return result
+}
+
+// BeforeAfterComparison splits the closed tickets by whether they were created before changeDate and returns the
+// statistics of the times-to-close on both sides, the percentage by which the mean after the change differs from
+// the one before it, negative when tickets got resolved faster, and the p value of the Mann-Whitney U test
+// comparing both distributions. Either side's Stats are zero when no closed ticket falls on it, in which case,
+// as whenever they cannot be computed, the percentage and the p value are NaN.
+func BeforeAfterComparison(
+	changeDate time.Time,
+	tickets ...jira.JiraIssue,
+) (before, after Stats, pctChange, significance float64) {
+	var beforeTimes, afterTimes []float64
+	for _, ticket := range tickets {
+		if ticket.TimeToClose <= 0 || ticket.TimeToClose > jira.MaxTimeToCloseH {
+			continue
+		}
+		if time.Time(ticket.Fields.Created).Before(changeDate) {
+			beforeTimes = append(beforeTimes, ticket.TimeToClose)
+		} else {
+			afterTimes = append(afterTimes, ticket.TimeToClose)
+		}
+	}
+	pctChange, significance = math.NaN(), math.NaN()
+	if len(beforeTimes) > 0 {
+		before = newStats(beforeTimes)
+	}
+	if len(afterTimes) > 0 {
+		after = newStats(afterTimes)
+	}
+	if before.Count == 0 || after.Count == 0 {
+		return before, after, pctChange, significance
+	}
+	if before.Mean != 0 {
+		pctChange = (after.Mean - before.Mean) / before.Mean * 100
+	}
+	return before, after, pctChange, mannWhitneyP(beforeTimes, afterTimes)
+}
+
+// mannWhitneyP returns the two-sided p value of the Mann-Whitney U test on two non-empty samples, using the
+// normal approximation corrected for ties, or NaN if all values are equal.
+func mannWhitneyP(xs, ys []float64) float64 {
+	type value struct {
+		v     float64
+		first bool
+	}
+	values := make([]value, 0, len(xs)+len(ys))
+	for _, x := range xs {
+		values = append(values, value{v: x, first: true})
+	}
+	for _, y := range ys {
+		values = append(values, value{v: y})
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].v < values[j].v })
+
+	n := float64(len(values))
+	var firstRanks, ties float64
+	for i := 0; i < len(values); {
+		j := i
+		for j < len(values) && values[j].v == values[i].v {
+			j++
+		}
+		// Tied values share the average of the ranks, counted from 1, they span.
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if values[k].first {
+				firstRanks += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+	n1, n2 := float64(len(xs)), float64(len(ys))
+	u := firstRanks - n1*(n1+1)/2
+	sigma := math.Sqrt(n1 * n2 / 12 * ((n + 1) - ties/(n*(n-1))))
+	if sigma == 0 || math.IsNaN(sigma) {
+		return math.NaN()
+	}
+	z := (u - n1*n2/2) / sigma
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+// ControlChart returns the creation dates and times-to-close of the closed tickets, in creation order, along with
+// the mean of the times-to-close and the upper and lower control limits lying 3 sample standard deviations above
+// and below it. Points outside of the limits hint at a process out of statistical control; see OutOfControl. With
+// fewer than two closed tickets, the limits equal the mean.
+func ControlChart(tickets ...jira.JiraIssue) (dates []time.Time, values []float64, mean, ucl, lcl float64) {
+	type point struct {
+		created     time.Time
+		timeToClose float64
+	}
+	var points []point
+	for _, ticket := range tickets {
+		if ticket.TimeToClose <= 0 || ticket.TimeToClose > jira.MaxTimeToCloseH {
+			continue
+		}
+		points = append(points, point{time.Time(ticket.Fields.Created), ticket.TimeToClose})
+	}
+	if len(points) == 0 {
+		return nil, nil, 0, 0, 0
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].created.Before(points[j].created)
+	})
+	dates = make([]time.Time, len(points))
+	values = make([]float64, len(points))
+	for i, p := range points {
+		dates[i], values[i] = p.created, p.timeToClose
+		mean += p.timeToClose
+	}
+	mean /= float64(len(values))
+	var sigma float64
+	if len(values) > 1 {
+		var squares float64
+		for _, v := range values {
+			squares += (v - mean) * (v - mean)
+		}
+		sigma = math.Sqrt(squares / float64(len(values)-1))
+	}
+	return dates, values, mean, mean + 3*sigma, mean - 3*sigma
+}
+
+// OutOfControl returns the indices of the values lying above ucl or below lcl.
+func OutOfControl(values []float64, ucl, lcl float64) []int {
+	var indices []int
+	for i, v := range values {
+		if v > ucl || v < lcl {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// isoWeekStart returns the Monday, at midnight UTC, starting the ISO week t belongs to.
+func isoWeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
@@ -0,0 +1,275 @@
+package analyze
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestCumulativeResolved(t *testing.T) {
+	tickets := []jira.JiraIssue{
+		ticketWith("C-1", "Closed", transition{53, "Open", "Closed"}),
+		ticketWith("C-2", "Closed", transition{5, "Open", "Closed"}),
+		ticketWith("C-3", "In Progress", transition{6, "Open", "In Progress"}),
+		ticketWith("C-4", "Closed", transition{20, "Open", "Closed"}),
+	}
+
+	buckets, counts := CumulativeResolved(24*time.Hour, tickets...)
+	wantBuckets := []time.Time{time.Time(at(1, 0)), time.Time(at(2, 0)), time.Time(at(3, 0))}
+	if !reflect.DeepEqual(buckets, wantBuckets) {
+		t.Errorf("buckets = %v, want %v", buckets, wantBuckets)
+	}
+	if want := []int{2, 2, 3}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("cumulative counts = %v, want %v", counts, want)
+	}
+	for i := 1; i < len(counts); i++ {
+		if counts[i] < counts[i-1] {
+			t.Errorf("cumulative counts %v are not monotonic", counts)
+		}
+	}
+
+	if buckets, counts := CumulativeResolved(24*time.Hour, tickets[2]); buckets != nil || counts != nil {
+		t.Errorf("CumulativeResolved of an unresolved ticket = %v, %v; want nothing", buckets, counts)
+	}
+}
+
+func TestResolutionTrendVolumeWeighted(t *testing.T) {
+	closedAfter := func(key string, hours, ttc float64) jira.JiraIssue {
+		ticket := ticketWith(key, "Closed", transition{hours, "Open", "Closed"})
+		ticket.TimeToClose = ttc
+		return ticket
+	}
+	// a single slow ticket is resolved on the first day and three fast ones on the second.
+	tickets := []jira.JiraIssue{
+		closedAfter("R-1", 5, 100),
+		closedAfter("R-2", 25, 10),
+		closedAfter("R-3", 28, 10),
+		closedAfter("R-4", 30, 10),
+	}
+
+	for _, tc := range []struct {
+		volumeWeighted bool
+		want           float64
+	}{
+		{false, 55},
+		{true, 32.5},
+	} {
+		trend := ResolutionTrend(24*time.Hour, 2, tc.volumeWeighted, tickets...)
+		if len(trend) != 2 || trend[0].Count != 1 || trend[1].Count != 3 {
+			t.Fatalf("trend buckets = %+v, want 1 and 3 tickets", trend)
+		}
+		if trend[0].RollingMean != 100 || trend[1].RollingMean != tc.want {
+			t.Errorf("volume weighted %v: rolling means = %v, %v; want 100, %v", tc.volumeWeighted,
+				trend[0].RollingMean, trend[1].RollingMean, tc.want)
+		}
+	}
+}
+
+func TestBacklogOverTime(t *testing.T) {
+	slow := ticketWith("B-1", "Closed", transition{50, "Open", "Closed"})
+	open := ticketWith("B-2", "Open")
+	open.Fields.Created = at(2, 1)
+	fast := ticketWith("B-3", "Closed", transition{30, "Open", "Closed"})
+	fast.Fields.Created = at(2, 2)
+
+	buckets, counts := BacklogOverTime(24*time.Hour, slow, open, fast)
+	if len(buckets) != 3 || !buckets[0].Equal(time.Time(at(1, 0))) {
+		t.Fatalf("buckets = %v, want the three days from March 1st", buckets)
+	}
+	// the backlog rises with the tickets created on the second day and falls with the resolution on the third.
+	if want := []int{1, 2, 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("backlog sizes = %v, want %v", counts, want)
+	}
+}
+
+func TestCreationRate(t *testing.T) {
+	var tickets []jira.JiraIssue
+	for _, created := range []jira.Time{at(4, 23), at(1, 2), at(3, 1), at(1, 5)} {
+		ticket := jira.JiraIssue{}
+		ticket.Fields.Created = created
+		tickets = append(tickets, ticket)
+	}
+
+	buckets, counts := CreationRate(24*time.Hour, tickets...)
+	if len(buckets) != 4 || !buckets[0].Equal(time.Time(at(1, 0))) {
+		t.Fatalf("buckets = %v, want the four days from March 1st", buckets)
+	}
+	if want := []int{2, 0, 1, 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("created tickets = %v, want %v", counts, want)
+	}
+
+	// the 93 hours between the first and the last creation span three inter-arrivals.
+	if mean, ok := MeanInterArrival(tickets...); !ok || mean != 31*time.Hour {
+		t.Errorf("MeanInterArrival = (%v, %v), want 31h", mean, ok)
+	}
+	if _, ok := MeanInterArrival(tickets[0]); ok {
+		t.Error("expected no mean inter-arrival time for a single ticket")
+	}
+}
+
+func TestWeeklyThroughput(t *testing.T) {
+	resolvedAt := func(key string, at time.Time) jira.JiraIssue {
+		ticket := jira.JiraIssue{Key: key}
+		ticket.Fields.Created = jira.Time(at.Add(-time.Hour))
+		ticket.Fields.Status.Name = "Closed"
+		ticket.Changelog.Histories = []jira.ChangelogHistory{{
+			Created: jira.Time(at),
+			Items:   []jira.ChangelogHistoryItem{{Field: "status", FromString: "Open", ToString: "Closed"}},
+		}}
+		return ticket
+	}
+	day := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+	}
+	// the last days of 2020 and the first Sunday of 2021 all belong to ISO week 53 of 2020.
+	tickets := []jira.JiraIssue{
+		resolvedAt("W-1", day(2020, time.December, 29, 10)),
+		resolvedAt("W-2", day(2021, time.January, 3, 23)),
+		resolvedAt("W-3", day(2021, time.January, 4, 0)),
+		resolvedAt("W-4", day(2021, time.January, 20, 12)),
+		ticketWith("W-5", "Open"),
+	}
+
+	weeks, counts := WeeklyThroughput(tickets...)
+	wantWeeks := []time.Time{
+		day(2020, time.December, 28, 0),
+		day(2021, time.January, 4, 0),
+		day(2021, time.January, 11, 0),
+		day(2021, time.January, 18, 0),
+	}
+	if !reflect.DeepEqual(weeks, wantWeeks) {
+		t.Errorf("weeks = %v, want %v", weeks, wantWeeks)
+	}
+	if want := []int{2, 1, 0, 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("weekly counts = %v, want %v", counts, want)
+	}
+
+	if weeks, counts := WeeklyThroughput(tickets[4]); weeks != nil || counts != nil {
+		t.Errorf("WeeklyThroughput of an unresolved ticket = %v, %v; want nothing", weeks, counts)
+	}
+}
+
+func TestCreationDayOfWeek(t *testing.T) {
+	createdAt := func(key string, created time.Time, ttc float64) jira.JiraIssue {
+		ticket := jira.JiraIssue{Key: key, TimeToClose: ttc}
+		ticket.Fields.Created = jira.Time(created)
+		return ticket
+	}
+	// late on Sunday the 4th of March 2018 in UTC, already Monday an hour east of it.
+	tickets := []jira.JiraIssue{
+		createdAt("D-1", time.Date(2018, 3, 4, 23, 30, 0, 0, time.UTC), 10),
+		createdAt("D-2", time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC), 20),
+		createdAt("D-3", time.Date(2018, 3, 5, 11, 0, 0, 0, time.UTC), 0),
+	}
+
+	want := map[time.Weekday][]float64{time.Sunday: {10}, time.Monday: {20}}
+	if got := CreationDayOfWeek(nil, tickets...); !reflect.DeepEqual(got, want) {
+		t.Errorf("CreationDayOfWeek in UTC = %v, want %v", got, want)
+	}
+	want = map[time.Weekday][]float64{time.Monday: {10, 20}}
+	if got := CreationDayOfWeek(time.FixedZone("UTC+1", 3600), tickets...); !reflect.DeepEqual(got, want) {
+		t.Errorf("CreationDayOfWeek an hour east of UTC = %v, want %v", got, want)
+	}
+}
+
+func TestMonthlyResolutionStats(t *testing.T) {
+	createdAt := func(created time.Time, hours float64) jira.JiraIssue {
+		ticket := jira.JiraIssue{TimeToClose: hours}
+		ticket.Fields.Created = jira.Time(created)
+		return ticket
+	}
+	got := MonthlyResolutionStats(
+		// months are compared regardless of the year.
+		createdAt(time.Date(2018, 1, 10, 9, 0, 0, 0, time.UTC), 10),
+		createdAt(time.Date(2018, 1, 31, 9, 0, 0, 0, time.UTC), 20),
+		createdAt(time.Date(2019, 1, 5, 9, 0, 0, 0, time.UTC), 30),
+		createdAt(time.Date(2018, 2, 14, 9, 0, 0, 0, time.UTC), 5),
+		// created on April 1st at 00:30 in UTC+2, which still is March in UTC.
+		createdAt(time.Date(2018, 4, 1, 0, 30, 0, 0, time.FixedZone("UTC+2", 2*3600)), 8),
+		// open tickets are left out.
+		createdAt(time.Date(2018, 5, 2, 9, 0, 0, 0, time.UTC), 0),
+	)
+	want := map[time.Month]Stats{
+		time.January:  {Count: 3, Mean: 20, Median: 20, Min: 10, Max: 30},
+		time.February: {Count: 1, Mean: 5, Median: 5, Min: 5, Max: 5},
+		time.March:    {Count: 1, Mean: 8, Median: 8, Min: 8, Max: 8},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MonthlyResolutionStats = %v, want %v", got, want)
+	}
+}
+
+func TestBeforeAfterComparison(t *testing.T) {
+	createdAt := func(day int, hours float64) jira.JiraIssue {
+		ticket := jira.JiraIssue{TimeToClose: hours}
+		ticket.Fields.Created = at(day, 0)
+		return ticket
+	}
+	// every ticket created after the change on March 10th got closed faster than any created before it.
+	var tickets []jira.JiraIssue
+	for i, hours := range []float64{40, 50, 60, 70, 80} {
+		tickets = append(tickets, createdAt(1+i, hours))
+	}
+	for i, hours := range []float64{10, 15, 20, 25, 30} {
+		tickets = append(tickets, createdAt(10+i, hours))
+	}
+	tickets = append(tickets, createdAt(12, 0))
+	change := time.Time(at(10, 0))
+
+	before, after, pctChange, significance := BeforeAfterComparison(change, tickets...)
+	if want := (Stats{Count: 5, Mean: 60, Median: 60, Min: 40, Max: 80}); before != want {
+		t.Errorf("before = %+v, want %+v", before, want)
+	}
+	if want := (Stats{Count: 5, Mean: 20, Median: 20, Min: 10, Max: 30}); after != want {
+		t.Errorf("after = %+v, want %+v", after, want)
+	}
+	if want := -200.0 / 3; math.Abs(pctChange-want) > 1e-9 {
+		t.Errorf("pctChange = %v, want %v", pctChange, want)
+	}
+	if significance <= 0 || significance >= 0.05 {
+		t.Errorf("significance = %v, want a p value below 0.05", significance)
+	}
+
+	before, after, pctChange, significance = BeforeAfterComparison(change, tickets[:5]...)
+	if before.Count != 5 || after != (Stats{}) || !math.IsNaN(pctChange) || !math.IsNaN(significance) {
+		t.Errorf("BeforeAfterComparison with no ticket after the change = %+v, %+v, %v, %v; want NaN comparisons",
+			before, after, pctChange, significance)
+	}
+}
+
+func TestControlChart(t *testing.T) {
+	// twenty tickets created a day apart, passed newest first, of which the eighth took far longer to close.
+	var tickets []jira.JiraIssue
+	for i := 19; i >= 0; i-- {
+		ticket := jira.JiraIssue{TimeToClose: 10}
+		if i == 7 {
+			ticket.TimeToClose = 200
+		}
+		ticket.Fields.Created = at(1+i, 0)
+		tickets = append(tickets, ticket)
+	}
+	tickets = append(tickets, ticketWith("CC-1", "Open"))
+
+	dates, values, mean, ucl, lcl := ControlChart(tickets...)
+	if len(dates) != 20 || !dates[0].Equal(time.Time(at(1, 0))) || !dates[19].Equal(time.Time(at(20, 0))) {
+		t.Fatalf("dates = %v, want the twenty closed tickets in creation order", dates)
+	}
+	if values[7] != 200 || values[8] != 10 {
+		t.Errorf("values = %v, want the slow ticket eighth", values)
+	}
+	// the squared deviations add up to 19*9.5^2 + 180.5^2 = 34295 over 19 degrees of freedom.
+	sigma := math.Sqrt(34295.0 / 19)
+	if mean != 19.5 || math.Abs(ucl-(19.5+3*sigma)) > 1e-9 || math.Abs(lcl-(19.5-3*sigma)) > 1e-9 {
+		t.Errorf("mean, ucl, lcl = %v, %v, %v; want 19.5 within %v either side", mean, ucl, lcl, 3*sigma)
+	}
+	if got, want := OutOfControl(values, ucl, lcl), []int{7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("OutOfControl = %v, want %v", got, want)
+	}
+
+	if _, _, mean, ucl, lcl := ControlChart(tickets[0]); mean != 10 || ucl != 10 || lcl != 10 {
+		t.Errorf("control limits of a single ticket = %v, %v around %v; want the mean", ucl, lcl, mean)
+	}
+}
@@ -0,0 +1,136 @@
+// Package api defines the response contract of the ticketguru HTTP endpoints, kept separate from the internal
+// ticket and analysis models so that those can evolve without breaking consumers.
+package api
+
+import (
+	"time"
+
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// TicketResponse defines a single ticket as returned by the /tickets endpoint.
+type TicketResponse struct {
+	// Key is the Jira key of the ticket, e.g. PROJ-123.
+	Key string `json:"key"`
+	// Summary is the one line summary of the ticket.
+	Summary string `json:"summary"`
+	// Priority is the name of the ticket's priority.
+	Priority string `json:"priority"`
+	// Status is the name of the ticket's current status.
+	Status string `json:"status"`
+	// Created is the time the ticket was created at.
+	Created time.Time `json:"created"`
+	// TimeToCloseHours is the number of hours it took to close the ticket, omitted if it is still open.
+	TimeToCloseHours *float64 `json:"time_to_close_hours,omitempty"`
+	// SummaryDescWords is the number of words in the summary and description of the ticket.
+	SummaryDescWords int `json:"summary_desc_words"`
+	// CommentWords is the number of words in all comments of the ticket.
+	CommentWords int `json:"comment_words"`
+	// Attachments is the number of files attached to the ticket.
+	Attachments int `json:"attachments"`
+	// HasStepsToReproduce states whether steps to reproduce were detected in the ticket.
+	HasStepsToReproduce bool `json:"has_steps_to_reproduce"`
+	// HasStackTrace states whether a stack trace was detected in the ticket.
+	HasStackTrace bool `json:"has_stack_trace"`
+	// Sentiment is the sentiment score of the ticket, omitted if it was not scored.
+	Sentiment *float64 `json:"sentiment,omitempty"`
+	// GrammarErrors is the number of grammar errors in the ticket, omitted if it was not scored.
+	GrammarErrors *int `json:"grammar_errors,omitempty"`
+}
+
+// TicketsResponse defines the body returned by the /tickets endpoint.
+type TicketsResponse struct {
+	// Total is the number of tickets matching the request.
+	Total int `json:"total"`
+	// Tickets holds the matching tickets.
+	Tickets []TicketResponse `json:"tickets"`
+}
+
+// SummaryResponse defines the body returned by the /metrics/summary endpoint. Apart from Total, all metrics only
+// take into account high priority tickets closed within the maximum time-to-close.
+type SummaryResponse struct {
+	// Total is the number of stored tickets.
+	Total int `json:"total"`
+	// Closed is the number of closed high priority tickets.
+	Closed int `json:"closed"`
+	// MeanTimeToCloseHours is the mean time-to-close of the closed tickets.
+	MeanTimeToCloseHours float64 `json:"mean_time_to_close_hours"`
+	// MinTimeToCloseHours is the shortest time-to-close of the closed tickets.
+	MinTimeToCloseHours float64 `json:"min_time_to_close_hours"`
+	// MaxTimeToCloseHours is the longest time-to-close of the closed tickets.
+	MaxTimeToCloseHours float64 `json:"max_time_to_close_hours"`
+	// Coverage holds the fraction of closed tickets having attachments, steps to reproduce, stack traces,
+	// a sentiment score and a grammar score.
+	Coverage CoverageResponse `json:"coverage"`
+}
+
+// CoverageResponse defines the coverage fractions returned as part of SummaryResponse.
+type CoverageResponse struct {
+	Attachments      float64 `json:"attachments"`
+	StepsToReproduce float64 `json:"steps_to_reproduce"`
+	StackTraces      float64 `json:"stack_traces"`
+	Sentiment        float64 `json:"sentiment"`
+	Grammar          float64 `json:"grammar"`
+}
+
+// ChartResponse defines a rendered chart as listed by the /charts endpoint.
+type ChartResponse struct {
+	// Name is the file name of the chart, e.g. comment_complexity.png.
+	Name string `json:"name"`
+	// URL is the location the PNG chart can be downloaded from.
+	URL string `json:"url"`
+}
+
+// ChartsResponse defines the body returned by the /charts endpoint.
+type ChartsResponse struct {
+	// Charts holds the available charts.
+	Charts []ChartResponse `json:"charts"`
+}
+
+// NewTicketResponse converts an internal ticket into its response representation.
+func NewTicketResponse(ticket jira.JiraIssue) TicketResponse {
+	resp := TicketResponse{
+		Key:                 ticket.Key,
+		Summary:             ticket.Fields.Summary,
+		Priority:            ticket.Fields.Priority.Name,
+		Status:              ticket.Fields.Status.Name,
+		Created:             time.Time(ticket.Fields.Created),
+		SummaryDescWords:    analyze.SummaryDescWords(ticket),
+		CommentWords:        analyze.CommentWords(ticket),
+		Attachments:         len(ticket.Fields.Attachments),
+		HasStepsToReproduce: ticket.HasStepsToReproduce,
+		HasStackTrace:       ticket.HasStackTrace,
+	}
+	if ticket.TimeToClose > 0 {
+		ttc := ticket.TimeToClose
+		resp.TimeToCloseHours = &ttc
+	}
+	if ticket.Sentiment.HasScore {
+		score := ticket.Sentiment.Score
+		resp.Sentiment = &score
+	}
+	if ticket.GrammarCorrectness.HasScore {
+		score := ticket.GrammarCorrectness.Score
+		resp.GrammarErrors = &score
+	}
+	return resp
+}
+
+// NewSummaryResponse converts the internal aggregates into their response representation.
+func NewSummaryResponse(agg analyze.Aggregates) SummaryResponse {
+	return SummaryResponse{
+		Total:                agg.Total,
+		Closed:               agg.Closed,
+		MeanTimeToCloseHours: agg.MeanTimeToClose,
+		MinTimeToCloseHours:  agg.MinTimeToClose,
+		MaxTimeToCloseHours:  agg.MaxTimeToClose,
+		Coverage: CoverageResponse{
+			Attachments:      agg.AttachmentsCoverage,
+			StepsToReproduce: agg.StepsToReproduceCoverage,
+			StackTraces:      agg.StackTracesCoverage,
+			Sentiment:        agg.SentimentCoverage,
+			Grammar:          agg.GrammarCoverage,
+		},
+	}
+}
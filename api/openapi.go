@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// endpoint defines a GET endpoint documented inside the OpenAPI spec.
+type endpoint struct {
+	path        string
+	summary     string
+	response    interface{}
+	contentType string
+}
+
+// endpoints lists the documented endpoints alongside their response DTOs.
+var endpoints = []endpoint{
+	{"/tickets", "List the stored tickets", TicketsResponse{}, "application/json"},
+	{"/metrics/summary", "Summarize the time-to-close and coverage metrics", SummaryResponse{}, "application/json"},
+	{"/charts", "List the rendered charts", ChartsResponse{}, "application/json"},
+}
+
+// Spec returns the OpenAPI 3 specification of the HTTP endpoints, with the response schemas generated from
+// the DTO types so that the two cannot drift apart.
+func Spec() map[string]interface{} {
+	schemas := make(map[string]interface{})
+	paths := make(map[string]interface{})
+	for _, e := range endpoints {
+		t := reflect.TypeOf(e.response)
+		addSchema(t, schemas)
+		paths[e.path] = map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary": e.summary,
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "OK",
+						"content": map[string]interface{}{
+							e.contentType: map[string]interface{}{
+								"schema": ref(t),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "ticketguru",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+		},
+	}
+}
+
+// SchemaHandler returns a handler serving the OpenAPI specification as JSON.
+func SchemaHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(Spec()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// ref returns a reference to the component schema of a struct type.
+func ref(t reflect.Type) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+}
+
+// addSchema registers the component schema of a struct type, and of all the struct types it references,
+// inside schemas.
+func addSchema(t reflect.Type, schemas map[string]interface{}) {
+	if _, ok := schemas[t.Name()]; ok {
+		return
+	}
+	properties := make(map[string]interface{})
+	var required []string
+	schemas[t.Name()] = map[string]interface{}{"type": "object", "properties": properties}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		if tag[0] == "" || tag[0] == "-" {
+			continue
+		}
+		properties[tag[0]] = schemaOf(field.Type, schemas)
+		if field.Type.Kind() != reflect.Ptr && !strings.Contains(field.Tag.Get("json"), "omitempty") {
+			required = append(required, tag[0])
+		}
+	}
+	if len(required) > 0 {
+		schemas[t.Name()].(map[string]interface{})["required"] = required
+	}
+}
+
+// schemaOf returns the schema of a field's type.
+func schemaOf(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct:
+		addSchema(t, schemas)
+		return ref(t)
+	case t.Kind() == reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+	case t.Kind() == reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{"type": "integer"}
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSchemaHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	SchemaHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("schema served with status %d and content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	var spec struct {
+		OpenAPI    string                            `json:"openapi"`
+		Paths      map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]interface{} `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("served schema is not valid JSON: %v", err)
+	}
+	if spec.OpenAPI == "" {
+		t.Error("served schema has no OpenAPI version")
+	}
+	for _, path := range []string{"/tickets", "/metrics/summary", "/charts"} {
+		if _, ok := spec.Paths[path]["get"]; !ok {
+			t.Errorf("served schema does not document GET %s", path)
+		}
+	}
+	for _, schema := range []string{"TicketsResponse", "TicketResponse", "SummaryResponse", "ChartsResponse"} {
+		if len(spec.Components.Schemas[schema].Properties) == 0 {
+			t.Errorf("served schema has no properties for %s", schema)
+		}
+	}
+	if _, ok := spec.Components.Schemas["SummaryResponse"].Properties["mean_time_to_close_hours"]; !ok {
+		t.Error("SummaryResponse schema does not hold its JSON field names")
+	}
+}
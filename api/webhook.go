@@ -0,0 +1,114 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// Jira webhook events the webhook handler reacts to.
+const (
+	IssueCreatedEvent = "jira:issue_created"
+	IssueUpdatedEvent = "jira:issue_updated"
+)
+
+// SignatureHeader holds the name of the header Jira sends the HMAC-SHA256 signature of a webhook payload in.
+const SignatureHeader = "X-Hub-Signature"
+
+// MaxPayloadBytes defines the size above which webhook payloads are rejected.
+var MaxPayloadBytes int64 = 10 << 20
+
+// WebhookStore defines the storage the webhook handler keeps up to date. UpsertAnalyzed must merge, analyze and
+// store each ticket atomically, so that concurrent events about the same ticket do not lose updates.
+type WebhookStore interface {
+	UpsertAnalyzed(analyses []analyze.TicketAnalysis, tickets ...jira.JiraIssue) error
+}
+
+// WebhookPayload defines the parts of a Jira issue event webhook payload used by the webhook handler.
+type WebhookPayload struct {
+	Event     string          `json:"webhookEvent"`
+	Timestamp int64           `json:"timestamp"`
+	User      jira.Author     `json:"user"`
+	Issue     jira.JiraIssue  `json:"issue"`
+	Changelog *WebhookChanges `json:"changelog,omitempty"`
+}
+
+// WebhookChanges defines the single changelog history an issue updated event carries.
+type WebhookChanges struct {
+	ID    string                      `json:"id"`
+	Items []jira.ChangelogHistoryItem `json:"items"`
+}
+
+// WebhookHandler returns a handler upserting the tickets carried by Jira issue created and updated events into
+// store, appending the change of an update to the ticket's changelog. If secret is not empty, payloads without
+// a valid signature are rejected. The given analyses are re-run on every upserted ticket and must not print, as
+// they run on every request.
+func WebhookHandler(store WebhookStore, secret string, analyses ...analyze.TicketAnalysis) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxPayloadBytes))
+		if err != nil {
+			status := http.StatusBadRequest
+			if int64(len(body)) >= MaxPayloadBytes {
+				status = http.StatusRequestEntityTooLarge
+			}
+			http.Error(w, "could not read webhook payload: "+err.Error(), status)
+			return
+		}
+		if secret != "" && !validSignature(body, r.Header.Get(SignatureHeader), secret) {
+			http.Error(w, "invalid webhook signature", http.StatusUnauthorized)
+			return
+		}
+		var payload WebhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			http.Error(w, "could not parse webhook payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if payload.Event != IssueCreatedEvent && payload.Event != IssueUpdatedEvent {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if payload.Issue.Key == "" {
+			http.Error(w, "webhook payload holds no issue key", http.StatusBadRequest)
+			return
+		}
+
+		ticket := payload.Issue
+		if payload.Changelog != nil && len(payload.Changelog.Items) > 0 {
+			ticket.Changelog.Histories = append(ticket.Changelog.Histories, jira.ChangelogHistory{
+				ID:      payload.Changelog.ID,
+				Author:  payload.User,
+				Created: jira.Time(time.Unix(0, payload.Timestamp*int64(time.Millisecond))),
+				Items:   payload.Changelog.Items,
+			})
+		}
+		if err := store.UpsertAnalyzed(analyses, ticket); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// validSignature returns whether signature, formatted as "sha256=<hex digest>", is the HMAC-SHA256 of body
+// keyed with secret.
+func validSignature(body []byte, signature, secret string) bool {
+	digest, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(digest, mac.Sum(nil))
+}
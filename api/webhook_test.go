@@ -0,0 +1,129 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// memoryStore holds the tickets upserted into it in memory, indexed by key.
+type memoryStore map[string]jira.JiraIssue
+
+func (s memoryStore) UpsertAnalyzed(analyses []analyze.TicketAnalysis, tickets ...jira.JiraIssue) error {
+	for _, ticket := range tickets {
+		if stored, ok := s[ticket.Key]; ok {
+			ticket = jira.Merge(stored, ticket)
+		}
+		analyzed := []jira.JiraIssue{ticket}
+		for _, analysis := range analyses {
+			analysis(analyzed...)
+		}
+		s[ticket.Key] = analyzed[0]
+	}
+	return nil
+}
+
+// postWebhook posts payload to handler, signed with secret unless it is empty, and returns the response status.
+func postWebhook(handler http.Handler, payload, secret string) int {
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(payload))
+	if secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(payload))
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+const (
+	createdPayload = `{"webhookEvent": "jira:issue_created", "timestamp": 1520000000000,
+		"issue": {"key": "W-1", "fields": {"summary": "login fails"}}}`
+	updatedPayload = `{"webhookEvent": "jira:issue_updated", "timestamp": 1520003600000,
+		"user": {"name": "alice"},
+		"issue": {"key": "W-1", "fields": {"summary": "login fails"}},
+		"changelog": {"id": "100", "items": [{"field": "status", "fromString": "Open", "toString": "Closed"}]}}`
+)
+
+func TestWebhookHandler(t *testing.T) {
+	store := memoryStore{}
+	handler := WebhookHandler(store, "")
+
+	if code := postWebhook(handler, createdPayload, ""); code != http.StatusNoContent {
+		t.Fatalf("issue created event answered with status %d", code)
+	}
+	if store["W-1"].Fields.Summary != "login fails" {
+		t.Fatalf("created ticket was not stored: %+v", store["W-1"])
+	}
+
+	if code := postWebhook(handler, updatedPayload, ""); code != http.StatusNoContent {
+		t.Fatalf("issue updated event answered with status %d", code)
+	}
+	histories := store["W-1"].Changelog.Histories
+	if len(histories) != 1 || histories[0].ID != "100" || histories[0].Author.Name != "alice" ||
+		histories[0].Items[0].ToString != "Closed" {
+		t.Errorf("updated ticket histories = %+v, want the change of the event", histories)
+	}
+
+	ignored := `{"webhookEvent": "jira:issue_deleted", "issue": {"key": "W-2"}}`
+	if code := postWebhook(handler, ignored, ""); code != http.StatusNoContent || len(store) != 1 {
+		t.Errorf("issue deleted event answered with status %d and stored %d tickets", code, len(store))
+	}
+}
+
+func TestWebhookHandlerSignature(t *testing.T) {
+	store := memoryStore{}
+	handler := WebhookHandler(store, "s3cret")
+
+	if code := postWebhook(handler, createdPayload, ""); code != http.StatusUnauthorized {
+		t.Errorf("unsigned payload answered with status %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := postWebhook(handler, createdPayload, "wrong"); code != http.StatusUnauthorized {
+		t.Errorf("payload signed with the wrong secret answered with status %d", code)
+	}
+	if len(store) != 0 {
+		t.Fatalf("rejected payloads updated the store: %v", store)
+	}
+	if code := postWebhook(handler, createdPayload, "s3cret"); code != http.StatusNoContent {
+		t.Errorf("signed payload answered with status %d", code)
+	}
+	if _, ok := store["W-1"]; !ok {
+		t.Error("signed payload did not update the store")
+	}
+}
+
+func TestWebhookHandlerReanalyzes(t *testing.T) {
+	store := memoryStore{}
+	handler := WebhookHandler(store, "", func(tickets ...jira.JiraIssue) {
+		for i := range tickets {
+			tickets[i].HasStepsToReproduce = true
+		}
+	})
+	if code := postWebhook(handler, createdPayload, ""); code != http.StatusNoContent {
+		t.Fatalf("issue created event answered with status %d", code)
+	}
+	if !store["W-1"].HasStepsToReproduce {
+		t.Error("stored ticket was not re-analyzed")
+	}
+}
+
+func TestWebhookHandlerRejectsLargePayloads(t *testing.T) {
+	previous := MaxPayloadBytes
+	MaxPayloadBytes = int64(len(createdPayload)) - 1
+	defer func() { MaxPayloadBytes = previous }()
+
+	store := memoryStore{}
+	if code := postWebhook(WebhookHandler(store, ""), createdPayload, ""); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized payload answered with status %d, want %d", code, http.StatusRequestEntityTooLarge)
+	}
+	if len(store) != 0 {
+		t.Errorf("oversized payload updated the store: %v", store)
+	}
+}
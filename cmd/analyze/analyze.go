@@ -6,88 +6,656 @@ import (
 	"fmt"
 	"github.com/joho/godotenv"
 	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/config"
 	"github.com/nclandrei/ticketguru/db"
+	"github.com/nclandrei/ticketguru/export"
+	"github.com/nclandrei/ticketguru/jira"
+	"io"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 )
 
-func main() {
-	boltDB, err := db.NewBolt("issues.db")
-	if err != nil {
-		log.Fatalf("could not access Bolt DB: %v\n", err)
-	}
+// namedScorer pairs a scorer with the name it is reported under in the run summary.
+type namedScorer struct {
+	name   string
+	scorer analyze.Scorer
+}
+
+// namedAnalysis pairs an analysis function with the name it is reported under in the run summary.
+type namedAnalysis struct {
+	name string
+	fn   analyze.TicketAnalysis
+}
+
+// requiredKeys maps the analysis types to the .env keys they need.
+var requiredKeys = map[string][]string{
+	"grammar":   {"BING_KEY_1"},
+	"sentiment": {"GOOGLE_APPLICATION_CREDENTIALS"},
+}
 
+const dbPath = "issues.db"
+
+func main() {
 	var analysisType string
 	flag.StringVar(&analysisType, "type", "all", "type of analysis to run; available types: grammar, sentiment, "+
-		"stack_traces, steps_to_reproduce, attachments, comment_complexity, fields_complexity, all")
+		"stack_traces, steps_to_reproduce, attachments, comment_complexity, weighted_comment_complexity, "+
+		"fields_complexity, summary_complexity, description_complexity, spam, all")
+	var commentHalfLife time.Duration
+	flag.DurationVar(&commentHalfLife, "commentHalfLife", 30*24*time.Hour, "age relative to a ticket's last activity "+
+		"at which a comment's words count half in the weighted comment complexity")
+	var summaryPath string
+	flag.StringVar(&summaryPath, "summary-json", "", "write a JSON summary of the run to the given file path or to stdout if set to -")
+
+	var excludedResolutions string
+	flag.StringVar(&excludedResolutions, "excludeResolutions", "", "comma separated resolutions (e.g. Duplicate) "+
+		"of the tickets left without a time-to-close")
+	var excludedStatuses string
+	flag.StringVar(&excludedStatuses, "excludeStatuses", "", "comma separated statuses whose time is not counted "+
+		"towards time-to-close (e.g. Waiting for Customer)")
+	var lastResolution bool
+	flag.BoolVar(&lastResolution, "lastResolution", false, "count reopened tickets as resolved at their last "+
+		"transition into a terminal status instead of their first one")
+	var budget time.Duration
+	flag.DurationVar(&budget, "budget", 0, "time after which scoring stops cleanly, persisting the tickets scored so "+
+		"far; already scored tickets are skipped when resuming with a new run; 0 disables the budget")
+	var batchSize int
+	flag.IntVar(&batchSize, "batchSize", 500, "number of tickets scored, analyzed and persisted at once")
+	var staleAfter time.Duration
+	flag.DurationVar(&staleAfter, "staleAfter", 0, "only analyze the tickets any of the selected scorers or "+
+		"analyses last ran on longer ago than this, or never ran on; 0 analyzes all tickets")
+	var workers int
+	flag.IntVar(&workers, "workers", runtime.GOMAXPROCS(0), "number of goroutines each local analysis of a batch "+
+		"is partitioned across")
+	var heuristicsPath string
+	flag.StringVar(&heuristicsPath, "heuristics", "", "path to a JSON file mapping custom heuristic names to regular "+
+		"expressions evaluated against ticket text")
+	var ndjson bool
+	flag.BoolVar(&ndjson, "ndjson", false, "stream the computed results of every ticket to -output as a JSON line "+
+		"as soon as its batch is processed")
+	var output string
+	flag.StringVar(&output, "output", "-", "path of the file the -ndjson results are streamed to; - streams them "+
+		"to stdout")
+	var grammarMaxChars, sentimentMaxChars int
+	flag.IntVar(&grammarMaxChars, "grammarMaxChars", 10000, "maximum number of characters sent to the grammar "+
+		"scorer per ticket; longer text is truncated at a word boundary; 0 disables the cap")
+	flag.IntVar(&sentimentMaxChars, "sentimentMaxChars", 0, "maximum number of characters sent to the sentiment "+
+		"scorer per ticket; longer text is truncated at a word boundary; 0 disables the cap")
+	var grammarText, sentimentText string
+	flag.StringVar(&grammarText, "grammarText", "", "+ separated fields whose text the grammar scorer scores, e.g. "+
+		"description; available fields: summary, description, environment, comments; summary and description if empty")
+	flag.StringVar(&sentimentText, "sentimentText", "", "+ separated fields whose text the sentiment scorer scores, "+
+		"e.g. summary+description; available fields: summary, description, environment, comments; comments if empty")
+	var stripJiraSyntax bool
+	flag.BoolVar(&stripJiraSyntax, "stripJiraSyntax", false, "strip Jira mentions, wiki links, {noformat} and "+
+		"{quote} blocks and smart-commit commands off the text before scoring it")
+	var rawResponses int
+	flag.IntVar(&rawResponses, "rawResponses", 0, "keep the raw responses of the grammar and sentiment APIs inside "+
+		"the database for auditing, evicting the oldest beyond this many; 0 keeps none")
+	var grammarAllowlistPath string
+	flag.StringVar(&grammarAllowlistPath, "grammarAllowlist", "", "path to a file holding, one per line, the terms "+
+		"(e.g. product names) the grammar scorer does not count as errors")
+	var skipIdentifiers bool
+	flag.BoolVar(&skipIdentifiers, "skipIdentifiers", false, "do not count tokens looking like code identifiers "+
+		"(camelCase or holding digits or underscores) as grammar errors")
+	var grammarSentences bool
+	flag.BoolVar(&grammarSentences, "grammarSentences", false, "end every field, comment and line of the text "+
+		"the grammar scorer scores as a sentence, so that the grammar API does not merge them")
+	var hoursMode, workCalendarPath string
+	flag.StringVar(&hoursMode, "hours", analyze.CalendarHours, "hours resolution times are counted in: calendar or "+
+		"business")
+	flag.StringVar(&workCalendarPath, "workCalendar", "", "path to a JSON work calendar defining the business "+
+		"hours; Monday to Friday, 9 to 17 UTC if empty")
+	var jiraURL string
+	flag.StringVar(&jiraURL, "jiraURL", "", "URL of the Jira instance whose workflow statuses define the terminal "+
+		"statuses; the well known terminal status names are used if empty")
+	var pipelinePath string
+	flag.StringVar(&pipelinePath, "pipeline", "", "path to a JSON file listing, in order, the analysis types "+
+		"and steps (time_to_close, custom_heuristics) run one after the other on each batch, e.g. "+
+		"{\"steps\": [\"time_to_close\", \"spam\", \"sentiment\"]}; overrides -type")
+	var notifyURL string
+	flag.StringVar(&notifyURL, "notifyURL", "", "URL the JSON summary of the run is posted to once it completes, "+
+		"including when it is interrupted or its budget elapses")
+	var validateOnly bool
+	flag.BoolVar(&validateOnly, "validate", false, "only validate the configuration and exit")
 
 	flag.Parse()
 
-	err = godotenv.Load()
+	var validator config.Validator
+	if err := godotenv.Load(); err != nil {
+		validator.Add("could not load .env file: %v", err)
+	}
+	types := []string{analysisType}
+	var pipelineConfig analyze.PipelineConfig
+	if pipelinePath != "" {
+		var err error
+		if pipelineConfig, err = analyze.LoadPipelineConfig(pipelinePath); err != nil {
+			validator.Add("%v", err)
+		}
+		types = pipelineConfig.Steps
+	}
+	for _, t := range types {
+		validator.RequireEnv(requiredKeys[t]...)
+	}
+	validator.RequireWritableFile(dbPath)
+	if summaryPath != "" && summaryPath != "-" {
+		validator.RequireWritableFile(summaryPath)
+	}
+	if ndjson && summaryPath == "-" {
+		validator.Add("the JSON summary cannot be written to stdout while streaming NDJSON results")
+	}
+	if batchSize <= 0 {
+		validator.Add("batch size must be positive")
+	}
+	var heuristics []analyze.Heuristic
+	if heuristicsPath != "" {
+		var err error
+		heuristics, err = analyze.LoadHeuristics(heuristicsPath)
+		if err != nil {
+			validator.Add("%v", err)
+		}
+	}
+	var grammarAllowlist []string
+	if grammarAllowlistPath != "" {
+		var err error
+		grammarAllowlist, err = analyze.LoadAllowlist(grammarAllowlistPath)
+		if err != nil {
+			validator.Add("%v", err)
+		}
+	}
+	var grammarSelector, sentimentSelector analyze.TextSelector
+	if grammarText != "" {
+		var err error
+		if grammarSelector, err = analyze.ParseTextSelector(grammarText); err != nil {
+			validator.Add("%v", err)
+		}
+	}
+	if sentimentText != "" {
+		var err error
+		if sentimentSelector, err = analyze.ParseTextSelector(sentimentText); err != nil {
+			validator.Add("%v", err)
+		}
+	}
+	if stripJiraSyntax {
+		if grammarSelector == nil {
+			grammarSelector, _ = analyze.ParseTextSelector("summary+description")
+		}
+		if sentimentSelector == nil {
+			sentimentSelector, _ = analyze.ParseTextSelector("comments")
+		}
+		grammarSelector = analyze.WithoutJiraSyntax(grammarSelector)
+		sentimentSelector = analyze.WithoutJiraSyntax(sentimentSelector)
+	}
+	workCalendar, err := analyze.CalendarFor(hoursMode, workCalendarPath)
+	if err != nil {
+		validator.Add("%v", err)
+	}
+	var jiraClient *jira.Client
+	if jiraURL != "" {
+		validator.RequireEnv("JIRA_USERNAME", "JIRA_PASSWORD")
+		clientURL, err := url.Parse(jiraURL)
+		if err != nil {
+			validator.Add("jira URL provided is not a valid URL: %v", err)
+		} else if jiraClient, err = jira.NewClient(clientURL); err != nil {
+			validator.Add("could not create Jira client: %v", err)
+		}
+	}
+	var notify notifier
+	if notifyURL != "" {
+		if u, err := url.Parse(notifyURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			validator.Add("notify URL %q is not a valid http(s) URL", notifyURL)
+		} else {
+			notify = webhookNotifier{url: notifyURL, client: &http.Client{Timeout: 30 * time.Second}}
+		}
+	}
+	if output != "-" && !ndjson {
+		validator.Add("-output %s is only used along with -ndjson", output)
+	}
+	if err := validator.Err(); err != nil {
+		log.Fatalln(err)
+	}
+	if validateOnly {
+		log.Println("configuration is valid")
+		return
+	}
+
+	boltDB, err := db.Open(dbPath)
 	if err != nil {
-		log.Fatalf("could not load .env file: %v\n", err)
+		log.Fatalf("could not access Bolt DB: %v\n", err)
+	}
+	// the results are streamed outside of the time budget, which would otherwise abort their upload.
+	var results io.WriteCloser
+	if ndjson {
+		if results, err = export.Open(context.Background(), output, export.Uploaders); err != nil {
+			log.Fatalf("could not open ndjson destination: %v\n", err)
+		}
 	}
 
-	var clients []analyze.Scorer
-	var analysisFuncs []analyze.TicketAnalysis
-	analysisFuncs = append(analysisFuncs, analyze.TimesToClose)
+	// an interrupt stops the run cleanly once the current batch is scored and persisted, so that the summary is
+	// still written and the completion notification sent exactly once; a second interrupt exits immediately.
+	var interrupted int32
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer func() {
+		signal.Stop(signals)
+		close(signals)
+	}()
+	go func() {
+		if _, ok := <-signals; !ok {
+			return
+		}
+		signal.Stop(signals)
+		atomic.StoreInt32(&interrupted, 1)
+		log.Printf("interrupt issued... finishing the current batch\n")
+	}()
 
-	switch analysisType {
-	case "grammar":
-		clients = append(clients, analyze.NewBingClient(os.Getenv("BING_KEY_1")))
-		break
-	case "sentiment":
-		sentimentClient, err := analyze.NewSentimentClient(context.Background())
+	ctx := context.Background()
+	if budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+	if jiraClient != nil {
+		if err := jiraClient.AuthenticateClient(); err != nil {
+			log.Fatalf("could not authenticate Jira client: %v\n", err)
+		}
+		statuses, err := jiraClient.FetchStatuses(ctx)
 		if err != nil {
-			log.Fatalf("could not create GCP sentiment client: %v\n", err)
-		}
-		clients = append(clients, sentimentClient)
-		break
-	case "steps_to_reproduce":
-		analysisFuncs = append(analysisFuncs, analyze.StepsToReproduce)
-		break
-	case "stack_traces":
-		analysisFuncs = append(analysisFuncs, analyze.StackTraces)
-		break
-	case "attachments":
-		analysisFuncs = append(analysisFuncs, analyze.Attachments)
-		break
-	case "comment_complexity":
-		analysisFuncs = append(analysisFuncs, analyze.CommentsComplexity)
-		break
-	case "fields_complexity":
-		analysisFuncs = append(analysisFuncs, analyze.FieldsComplexity)
-		break
-	case "all":
-		analysisFuncs = append(analysisFuncs, analyze.StepsToReproduce, analyze.StackTraces, analyze.Attachments,
-			analyze.CommentsComplexity, analyze.FieldsComplexity)
-		break
-	default:
-		fmt.Printf("%s is not a valid analysis type; available types are grammar, sentiment and all", analysisType)
-		os.Exit(1)
+			log.Fatalf("could not fetch workflow statuses: %v\n", err)
+		}
+		analyze.UseWorkflowStatuses(statuses)
+	}
+
+	var clients []namedScorer
+	var resolutionOpts analyze.ResolutionOptions
+	resolutionOpts.ExcludedStatuses = splitList(excludedStatuses)
+	resolutionOpts.ExcludedResolutions = splitList(excludedResolutions)
+	resolutionOpts.Calendar = workCalendar
+	resolutionOpts.LastTransition = lastResolution
+	analysisFuncs := []namedAnalysis{{"time_to_close", analyze.ParallelTimesToClose(workers, resolutionOpts)}}
+
+	for _, t := range types {
+		switch t {
+		case "grammar":
+			bingClient := analyze.NewBingClient(ctx, os.Getenv("BING_KEY_1"))
+			bingClient.SetMaxChars(grammarMaxChars)
+			bingClient.SetAllowlist(grammarAllowlist)
+			bingClient.SetSkipIdentifiers(skipIdentifiers)
+			bingClient.SetTextSelector(grammarSelector)
+			bingClient.SetSentenceBoundaries(grammarSentences)
+			if rawResponses > 0 {
+				bingClient.SetResponseRecorder(boltDB.Responses(rawResponses))
+			}
+			clients = append(clients, namedScorer{"grammar", bingClient})
+			break
+		case "sentiment":
+			sentimentClient, err := analyze.NewSentimentClient(ctx)
+			if err != nil {
+				log.Fatalf("could not create GCP sentiment client: %v\n", err)
+			}
+			sentimentClient.SetMaxChars(sentimentMaxChars)
+			sentimentClient.SetTextSelector(sentimentSelector)
+			if rawResponses > 0 {
+				sentimentClient.SetResponseRecorder(boltDB.Responses(rawResponses))
+			}
+			clients = append(clients, namedScorer{"sentiment", sentimentClient})
+			break
+		case "spam":
+			clients = append(clients, namedScorer{"spam", analyze.NewSpamDetector()})
+			break
+		case "steps_to_reproduce":
+			analysisFuncs = append(analysisFuncs, namedAnalysis{"steps_to_reproduce", analyze.StepsToReproduce})
+			break
+		case "stack_traces":
+			analysisFuncs = append(analysisFuncs, namedAnalysis{"stack_traces", analyze.StackTraces})
+			break
+		case "attachments":
+			analysisFuncs = append(analysisFuncs, namedAnalysis{"attachments", analyze.Attachments})
+			break
+		case "comment_complexity":
+			analysisFuncs = append(analysisFuncs, namedAnalysis{"comment_complexity", analyze.CommentsComplexity})
+			break
+		case "weighted_comment_complexity":
+			analysisFuncs = append(analysisFuncs,
+				namedAnalysis{"weighted_comment_complexity", analyze.WeightedCommentsComplexity(commentHalfLife)})
+			break
+		case "fields_complexity":
+			analysisFuncs = append(analysisFuncs, namedAnalysis{"fields_complexity", analyze.FieldsComplexity})
+			break
+		case "summary_complexity":
+			analysisFuncs = append(analysisFuncs, namedAnalysis{"summary_complexity", analyze.SummaryComplexity})
+			break
+		case "description_complexity":
+			analysisFuncs = append(analysisFuncs, namedAnalysis{"description_complexity", analyze.DescriptionComplexity})
+			break
+		case "all":
+			clients = append(clients, namedScorer{"spam", analyze.NewSpamDetector()})
+			analysisFuncs = append(analysisFuncs,
+				namedAnalysis{"steps_to_reproduce", analyze.StepsToReproduce},
+				namedAnalysis{"stack_traces", analyze.StackTraces},
+				namedAnalysis{"attachments", analyze.Attachments},
+				namedAnalysis{"comment_complexity", analyze.CommentsComplexity},
+				namedAnalysis{"weighted_comment_complexity", analyze.WeightedCommentsComplexity(commentHalfLife)},
+				namedAnalysis{"fields_complexity", analyze.FieldsComplexity},
+				namedAnalysis{"summary_complexity", analyze.SummaryComplexity},
+				namedAnalysis{"description_complexity", analyze.DescriptionComplexity},
+			)
+			break
+		case "time_to_close", "custom_heuristics":
+			// always set up below for the pipeline to pick
+			break
+		default:
+			fmt.Printf("%s is not a valid analysis type; available types are grammar, sentiment and all", t)
+			os.Exit(1)
+		}
+	}
+	if len(heuristics) > 0 {
+		analysisFuncs = append(analysisFuncs, namedAnalysis{"custom_heuristics", analyze.CustomHeuristics(heuristics)})
+	}
+	// time_to_close already runs in parallel so as to print its counts once per batch.
+	for i := 1; i < len(analysisFuncs); i++ {
+		analysisFuncs[i].fn = analyze.Parallel(workers, analysisFuncs[i].fn)
+	}
+	wordCounts := analyze.Parallel(workers, analyze.WordCounts)
+	var pipeline *analyze.Pipeline
+	if pipelinePath != "" {
+		available := make(map[string]analyze.Step, len(clients)+len(analysisFuncs))
+		for _, c := range clients {
+			available[c.name] = analyze.Step{Scorer: c.scorer}
+		}
+		for _, a := range analysisFuncs {
+			available[a.name] = analyze.Step{Analysis: a.fn}
+		}
+		if pipeline, err = analyze.NewPipeline(pipelineConfig, available); err != nil {
+			log.Fatalf("could not build pipeline: %v\n", err)
+		}
+		analysisType = "pipeline"
 	}
 
 	tickets, err := boltDB.Tickets()
 	if err != nil {
 		log.Fatalf("could not get all issues inside the database: %v\n", err)
 	}
+	if staleAfter > 0 {
+		tickets = staleTickets(time.Now().Add(-staleAfter), clients, analysisFuncs, tickets...)
+		log.Printf("%d tickets have stale analyses\n", len(tickets))
+	}
+
+	summary := &Summary{
+		AnalysisType:     analysisType,
+		TicketsProcessed: len(tickets),
+		StartedAt:        time.Now(),
+		Errors:           []string{},
+	}
+
+	scorerSummaries := make([]AnalysisSummary, len(clients))
+	for i := range clients {
+		scorerSummaries[i].Name = clients[i].name
+	}
+	analysisSummaries := make([]AnalysisSummary, len(analysisFuncs))
+	for i := range analysisFuncs {
+		analysisSummaries[i].Name = analysisFuncs[i].name
+	}
+	var stepSummaries []AnalysisSummary
+	if pipeline != nil {
+		for _, name := range pipeline.Steps() {
+			stepSummaries = append(stepSummaries, AnalysisSummary{Name: name})
+		}
+	}
+
+	// Tickets are scored, analyzed and persisted in batches so that a late failure does not discard
+	// the work done for the previous batches; their results are only streamed once persisted.
+	var store inserter = boltDB
+	if ndjson {
+		store = streamingStore{inserter: boltDB, w: results}
+	}
+	err = persistInBatches(ctx, &interrupted, store, summary, tickets, batchSize, func(batch []jira.JiraIssue) {
+		// word counts are refreshed before the concurrent analyses so that all of them read the stored values
+		wordCounts(batch...)
+		markAnalyzed("word_counts", time.Now(), batch...)
+		if pipeline != nil {
+			runPipeline(ctx, pipeline, batch, stepSummaries)
+		} else {
+			processBatch(batch, clients, analysisFuncs, scorerSummaries, analysisSummaries)
+		}
+	})
+	if summary.BudgetExhausted {
+		log.Printf("time budget of %v elapsed after %d of %d tickets; run again to resume\n",
+			budget, summary.TicketsPersisted, len(tickets))
+	}
+
+	analyses := append(scorerSummaries, analysisSummaries...)
+	if pipeline != nil {
+		analyses = stepSummaries
+	}
+	if results != nil {
+		if closeErr := results.Close(); closeErr != nil {
+			summary.Errors = append(summary.Errors, fmt.Sprintf("could not write ndjson results: %v", closeErr))
+		}
+	}
+	completeRun(summary, analyses, err, summaryPath, notify)
+
+	if err != nil {
+		log.Fatalf("could not insert tickets: %v\n", err)
+	}
+}
+
+// finishSummary completes the summary of a run once its tickets were persisted with the outcome of each scorer
+// or analysis, collecting their errors along with runErr, the error which stopped the run, if any.
+func finishSummary(summary *Summary, analyses []AnalysisSummary, runErr error) {
+	summary.Analyses = analyses
+	for _, a := range analyses {
+		if a.Error != "" {
+			summary.Errors = append(summary.Errors, a.Name+": "+a.Error)
+		}
+	}
+	if runErr != nil {
+		summary.Errors = append(summary.Errors, runErr.Error())
+	}
+	summary.DurationSeconds = time.Since(summary.StartedAt).Seconds()
+}
+
+// completeRun finishes the summary of a run like finishSummary, then writes it to summaryPath, if set, and sends
+// it to notify, if set. It is called exactly once per run, whether the run persisted all its tickets, failed or
+// was stopped early, so that every run is reported once.
+func completeRun(summary *Summary, analyses []AnalysisSummary, runErr error, summaryPath string, notify notifier) {
+	finishSummary(summary, analyses, runErr)
+	if summaryPath != "" {
+		if err := writeSummary(summary, summaryPath); err != nil {
+			log.Printf("could not write JSON summary: %v\n", err)
+		}
+	}
+	if notify != nil {
+		if err := notify.Notify(summary); err != nil {
+			log.Printf("could not send completion notification: %v\n", err)
+		}
+	}
+}
+
+// splitList splits a comma separated flag value into its trimmed, non-empty entries.
+func splitList(s string) []string {
+	var entries []string
+	for _, entry := range strings.Split(s, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// batches splits the tickets into consecutive batches of at most size tickets.
+func batches(tickets []jira.JiraIssue, size int) [][]jira.JiraIssue {
+	var result [][]jira.JiraIssue
+	for low := 0; low < len(tickets); low += size {
+		high := low + size
+		if high > len(tickets) {
+			high = len(tickets)
+		}
+		result = append(result, tickets[low:high])
+	}
+	return result
+}
+
+// persistInBatches processes and persists the tickets batch by batch, recording the progress inside the summary.
+// It stops cleanly before the next batch once interrupted is set or ctx is done, e.g. because the time budget
+// elapsed, so that the tickets processed so far are kept and skipped when resuming with a new run. Both are only
+// checked between batches: a batch being processed when they are set is still processed and persisted.
+func persistInBatches(
+	ctx context.Context,
+	interrupted *int32,
+	store inserter,
+	summary *Summary,
+	tickets []jira.JiraIssue,
+	batchSize int,
+	process func([]jira.JiraIssue)) error {
 
-	analyze.MultipleScores(tickets, clients...)
+	for _, batch := range batches(tickets, batchSize) {
+		if atomic.LoadInt32(interrupted) == 1 {
+			log.Printf("interrupted after %d of %d tickets; run again to resume\n", summary.TicketsPersisted,
+				len(tickets))
+			summary.Interrupted = true
+			return nil
+		}
+		if ctx.Err() != nil {
+			summary.BudgetExhausted = true
+			return nil
+		}
+		process(batch)
+		if err := persistBatch(store, summary, batch...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inserter defines the store processed tickets are persisted into, e.g. the Bolt DB.
+type inserter interface {
+	Insert(tickets ...jira.JiraIssue) error
+}
+
+// persistBatch inserts a processed batch of tickets and counts them as persisted in the summary; on failure, the
+// returned error reports how many of the run's tickets were already persisted by the previous batches.
+func persistBatch(store inserter, summary *Summary, batch ...jira.JiraIssue) error {
+	if err := store.Insert(batch...); err != nil {
+		return fmt.Errorf("%v (%d of %d tickets were already persisted)", err, summary.TicketsPersisted,
+			summary.TicketsProcessed)
+	}
+	summary.TicketsPersisted += len(batch)
+	return nil
+}
+
+// processBatch runs all scorers and then all analyses on a batch of tickets, accumulating their durations
+// and errors inside the index-aligned summaries.
+func processBatch(
+	batch []jira.JiraIssue,
+	clients []namedScorer,
+	analysisFuncs []namedAnalysis,
+	scorerSummaries []AnalysisSummary,
+	analysisSummaries []AnalysisSummary) {
+
+	var scorersWg sync.WaitGroup
+	scored := make([]bool, len(clients))
+	for i := range clients {
+		scorersWg.Add(1)
+		go func(i int) {
+			defer scorersWg.Done()
+			start := time.Now()
+			if err := clients[i].scorer.Scores(batch...); err != nil {
+				log.Printf("could not compute %s scores: %v\n", clients[i].name, err)
+				if scorerSummaries[i].Error != "" {
+					scorerSummaries[i].Error += "; "
+				}
+				scorerSummaries[i].Error += err.Error()
+			} else {
+				scored[i] = true
+			}
+			scorerSummaries[i].DurationSeconds += time.Since(start).Seconds()
+		}(i)
+	}
+	scorersWg.Wait()
+	// tickets are stamped once all scorers are done as they write to the same tickets concurrently
+	now := time.Now()
+	for i := range clients {
+		if scored[i] {
+			markAnalyzed(clients[i].name, now, batch...)
+		}
+	}
 
 	var wg sync.WaitGroup
-	for _, f := range analysisFuncs {
+	for i := range analysisFuncs {
 		wg.Add(1)
-		go func(fn analyze.TicketAnalysis) {
+		go func(i int) {
 			defer wg.Done()
-			fn(tickets...)
-		}(f)
+			start := time.Now()
+			analysisFuncs[i].fn(batch...)
+			analysisSummaries[i].DurationSeconds += time.Since(start).Seconds()
+		}(i)
 	}
-
 	wg.Wait()
+	now = time.Now()
+	for i := range analysisFuncs {
+		markAnalyzed(analysisFuncs[i].name, now, batch...)
+	}
+}
 
-	err = boltDB.Insert(tickets...)
+// runPipeline runs the pipeline on a batch of tickets, accumulating the duration and errors of each step inside
+// the summaries index-aligned with the pipeline steps, and records when the successful steps ran.
+func runPipeline(ctx context.Context, pipeline *analyze.Pipeline, batch []jira.JiraIssue, summaries []AnalysisSummary) {
+	results, err := pipeline.Run(ctx, batch...)
 	if err != nil {
-		log.Fatalf("could not insert tickets: %v\n", err)
+		log.Printf("could not run all pipeline steps: %v\n", err)
+	}
+	now := time.Now()
+	for i, result := range results {
+		summaries[i].DurationSeconds += result.Duration.Seconds()
+		if result.Err != nil {
+			if summaries[i].Error != "" {
+				summaries[i].Error += "; "
+			}
+			summaries[i].Error += result.Err.Error()
+			continue
+		}
+		markAnalyzed(result.Name, now, batch...)
+	}
+}
+
+// staleTickets returns the tickets any of the scorers or analyses last ran on before cutoff or never ran on.
+func staleTickets(
+	cutoff time.Time,
+	clients []namedScorer,
+	analysisFuncs []namedAnalysis,
+	tickets ...jira.JiraIssue) []jira.JiraIssue {
+
+	var names []string
+	for _, c := range clients {
+		names = append(names, c.name)
+	}
+	for _, a := range analysisFuncs {
+		names = append(names, a.name)
+	}
+	var stale []jira.JiraIssue
+	for i := range tickets {
+		for _, name := range names {
+			if tickets[i].AnalyzedBefore(name, cutoff) {
+				stale = append(stale, tickets[i])
+				break
+			}
+		}
+	}
+	return stale
+}
+
+// markAnalyzed records that the named scorer or analysis computed the fields of the tickets at the given time.
+func markAnalyzed(name string, at time.Time, tickets ...jira.JiraIssue) {
+	for i := range tickets {
+		tickets[i].MarkAnalyzed(name, at)
 	}
 }
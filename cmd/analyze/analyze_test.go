@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// failingStore persists the tickets it is given until its failAt-th insert, which fails.
+type failingStore struct {
+	failAt  int
+	inserts int
+	keys    []string
+}
+
+func (s *failingStore) Insert(tickets ...jira.JiraIssue) error {
+	s.inserts++
+	if s.inserts == s.failAt {
+		return errors.New("disk full")
+	}
+	for _, ticket := range tickets {
+		s.keys = append(s.keys, ticket.Key)
+	}
+	return nil
+}
+
+func TestPersistBatchFailurePartway(t *testing.T) {
+	tickets := []jira.JiraIssue{{Key: "P-1"}, {Key: "P-2"}, {Key: "P-3"}, {Key: "P-4"}, {Key: "P-5"}}
+	store := &failingStore{failAt: 3}
+	summary := &Summary{TicketsProcessed: len(tickets)}
+
+	var err error
+	for _, batch := range batches(tickets, 2) {
+		if err = persistBatch(store, summary, batch...); err != nil {
+			break
+		}
+	}
+	if err == nil || !strings.Contains(err.Error(), "4 of 5 tickets were already persisted") {
+		t.Fatalf("error = %v, want it to report 4 of 5 tickets persisted", err)
+	}
+	if summary.TicketsPersisted != 4 || strings.Join(store.keys, ",") != "P-1,P-2,P-3,P-4" {
+		t.Errorf("persisted %d tickets %v, want the first two batches", summary.TicketsPersisted, store.keys)
+	}
+}
+
+func TestPersistInBatchesStopsWhenBudgetElapses(t *testing.T) {
+	tickets := []jira.JiraIssue{{Key: "P-1"}, {Key: "P-2"}, {Key: "P-3"}, {Key: "P-4"}, {Key: "P-5"}}
+	store := &failingStore{}
+	summary := &Summary{TicketsProcessed: len(tickets)}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	var interrupted int32
+	var processed int
+	err := persistInBatches(ctx, &interrupted, store, summary, tickets, 2, func(batch []jira.JiraIssue) {
+		// scoring the first batch takes longer than the whole budget.
+		time.Sleep(10 * time.Millisecond)
+		processed += len(batch)
+	})
+	if err != nil {
+		t.Fatalf("could not persist tickets: %v", err)
+	}
+	if !summary.BudgetExhausted || processed != 2 {
+		t.Fatalf("budget exhausted = %v after processing %d tickets, want true after the first batch",
+			summary.BudgetExhausted, processed)
+	}
+	if summary.TicketsPersisted != 2 || strings.Join(store.keys, ",") != "P-1,P-2" {
+		t.Errorf("persisted %d tickets %v, want the first batch", summary.TicketsPersisted, store.keys)
+	}
+}
+
+func TestPersistInBatchesStopsWhenInterrupted(t *testing.T) {
+	tickets := []jira.JiraIssue{{Key: "P-1"}, {Key: "P-2"}, {Key: "P-3"}, {Key: "P-4"}, {Key: "P-5"}}
+	store := &failingStore{}
+	summary := &Summary{TicketsProcessed: len(tickets)}
+
+	var interrupted int32
+	err := persistInBatches(context.Background(), &interrupted, store, summary, tickets, 2,
+		func(batch []jira.JiraIssue) {
+			// the interrupt arrives while the second batch is scored, which is still persisted.
+			if batch[0].Key == "P-3" {
+				atomic.StoreInt32(&interrupted, 1)
+			}
+		})
+	if err != nil {
+		t.Fatalf("could not persist tickets: %v", err)
+	}
+	if !summary.Interrupted || summary.BudgetExhausted {
+		t.Errorf("interrupted = %v, budget exhausted = %v, want only interrupted", summary.Interrupted,
+			summary.BudgetExhausted)
+	}
+	if summary.TicketsPersisted != 4 || strings.Join(store.keys, ",") != "P-1,P-2,P-3,P-4" {
+		t.Errorf("persisted %d tickets %v, want the first two batches", summary.TicketsPersisted, store.keys)
+	}
+
+	// a run that is not interrupted persists every ticket and is not reported as interrupted.
+	store, summary = &failingStore{}, &Summary{TicketsProcessed: len(tickets)}
+	atomic.StoreInt32(&interrupted, 0)
+	if err := persistInBatches(context.Background(), &interrupted, store, summary, tickets, 2,
+		func([]jira.JiraIssue) {}); err != nil {
+		t.Fatalf("could not persist tickets: %v", err)
+	}
+	if summary.Interrupted || summary.TicketsPersisted != len(tickets) {
+		t.Errorf("interrupted = %v after persisting %d tickets, want false after all %d", summary.Interrupted,
+			summary.TicketsPersisted, len(tickets))
+	}
+}
+
+// scorerFunc scores tickets by calling itself.
+type scorerFunc func(...jira.JiraIssue) error
+
+func (f scorerFunc) Scores(tickets ...jira.JiraIssue) error {
+	return f(tickets...)
+}
+
+func TestProcessBatchMarksAnalyzed(t *testing.T) {
+	clients := []namedScorer{
+		{"spam", scorerFunc(func(...jira.JiraIssue) error { return nil })},
+		{"grammar", scorerFunc(func(...jira.JiraIssue) error { return errors.New("quota exceeded") })},
+	}
+	analysisFuncs := []namedAnalysis{{"attachments", func(...jira.JiraIssue) {}}}
+	batch := []jira.JiraIssue{{Key: "M-1"}, {Key: "M-2"}}
+
+	before := time.Now()
+	processBatch(batch, clients, analysisFuncs, make([]AnalysisSummary, len(clients)),
+		make([]AnalysisSummary, len(analysisFuncs)))
+	for _, ticket := range batch {
+		for _, name := range []string{"spam", "attachments"} {
+			if ticket.AnalyzedBefore(name, before) {
+				t.Errorf("%s of %s was not marked as analyzed during the batch", name, ticket.Key)
+			}
+		}
+		if _, ok := ticket.AnalyzedAt["grammar"]; ok {
+			t.Errorf("failing grammar scorer was marked as analyzed on %s", ticket.Key)
+		}
+	}
+
+	// the tickets stay stale for the scorer which failed on them.
+	if stale := staleTickets(before, clients[:1], analysisFuncs, batch...); len(stale) != 0 {
+		t.Errorf("staleTickets = %v, want none as spam and attachments just ran", stale)
+	}
+	if stale := staleTickets(before, clients, analysisFuncs, batch...); len(stale) != len(batch) {
+		t.Errorf("staleTickets returned %d tickets, want all %d never scored for grammar", len(stale), len(batch))
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	for s, want := range map[string][]string{
+		"Done, Won't Fix":         {"Done", "Won't Fix"},
+		" Waiting for Customer ,": {"Waiting for Customer"},
+		"":                        nil,
+	} {
+		if got := splitList(s); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitList(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
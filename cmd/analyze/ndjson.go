@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// TicketResult defines the computed results of a single ticket, emitted as a JSON line when -ndjson is set.
+type TicketResult struct {
+	Key                   string          `json:"key"`
+	TimeToClose           float64         `json:"time_to_close"`
+	SummaryWordsCount     int             `json:"summary_words"`
+	DescriptionWordsCount int             `json:"description_words"`
+	CommentWordsCount     int             `json:"comment_words"`
+	HasStepsToReproduce   bool            `json:"has_steps_to_reproduce"`
+	HasStackTrace         bool            `json:"has_stack_trace"`
+	Sentiment             *float64        `json:"sentiment,omitempty"`
+	GrammarErrors         *int            `json:"grammar_errors,omitempty"`
+	CustomFlags           map[string]bool `json:"custom_flags,omitempty"`
+}
+
+// streamResults writes the computed results of every ticket as one JSON line to w.
+func streamResults(w io.Writer, tickets ...jira.JiraIssue) error {
+	encoder := json.NewEncoder(w)
+	for _, t := range tickets {
+		result := TicketResult{
+			Key:                   t.Key,
+			TimeToClose:           t.TimeToClose,
+			SummaryWordsCount:     t.SummaryWordsCount,
+			DescriptionWordsCount: t.DescriptionWordsCount,
+			CommentWordsCount:     t.CommentWordsCount,
+			HasStepsToReproduce:   t.HasStepsToReproduce,
+			HasStackTrace:         t.HasStackTrace,
+			CustomFlags:           t.CustomFlags,
+		}
+		if t.Sentiment.HasScore {
+			score := t.Sentiment.Score
+			result.Sentiment = &score
+		}
+		if t.GrammarCorrectness.HasScore {
+			score := t.GrammarCorrectness.Score
+			result.GrammarErrors = &score
+		}
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamingStore streams the results of the tickets it persists to w once the wrapped store inserted them, so that
+// the stream never holds tickets which failed to be persisted.
+type streamingStore struct {
+	inserter
+	w io.Writer
+}
+
+// Insert inserts the tickets into the wrapped store and then streams their results.
+func (s streamingStore) Insert(tickets ...jira.JiraIssue) error {
+	if err := s.inserter.Insert(tickets...); err != nil {
+		return err
+	}
+	if err := streamResults(s.w, tickets...); err != nil {
+		log.Printf("could not stream results: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestStreamResults(t *testing.T) {
+	scored := jira.JiraIssue{Key: "N-1", TimeToClose: 12, HasStackTrace: true}
+	scored.Sentiment = jira.Sentiment{Score: 0.5, HasScore: true}
+	unscored := jira.JiraIssue{Key: "N-2", CommentWordsCount: 7}
+
+	var out bytes.Buffer
+	if err := streamResults(&out, scored, unscored); err != nil {
+		t.Fatalf("could not stream results: %v", err)
+	}
+	var results []TicketResult
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var result TicketResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			t.Fatalf("line %q is not valid JSON: %v", scanner.Text(), err)
+		}
+		results = append(results, result)
+	}
+	if len(results) != 2 {
+		t.Fatalf("streamed %d lines, want one per ticket", len(results))
+	}
+	if r := results[0]; r.Key != "N-1" || r.TimeToClose != 12 || !r.HasStackTrace || r.Sentiment == nil ||
+		*r.Sentiment != 0.5 || r.GrammarErrors != nil {
+		t.Errorf("streamed result %+v for %s", r, scored.Key)
+	}
+	if r := results[1]; r.Key != "N-2" || r.CommentWordsCount != 7 || r.Sentiment != nil {
+		t.Errorf("streamed result %+v for %s", r, unscored.Key)
+	}
+}
+
+func TestStreamingStoreStreamsPersistedTickets(t *testing.T) {
+	tickets := []jira.JiraIssue{{Key: "N-1"}, {Key: "N-2"}, {Key: "N-3"}}
+	summary := &Summary{TicketsProcessed: len(tickets)}
+	var out bytes.Buffer
+	store := streamingStore{inserter: &failingStore{failAt: 2}, w: &out}
+
+	var interrupted int32
+	err := persistInBatches(context.Background(), &interrupted, store, summary, tickets, 1, func([]jira.JiraIssue) {})
+	if err == nil {
+		t.Fatal("expected the failed insert of the second batch")
+	}
+	var keys []string
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var result TicketResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			t.Fatalf("line %q is not valid JSON: %v", scanner.Text(), err)
+		}
+		keys = append(keys, result.Key)
+	}
+	if len(keys) != summary.TicketsPersisted || len(keys) != 1 || keys[0] != "N-1" {
+		t.Errorf("streamed %v with %d tickets persisted, want only the persisted N-1", keys, summary.TicketsPersisted)
+	}
+}
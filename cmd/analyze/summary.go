@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Summary defines the machine readable outcome of an analysis run, emitted when -summary-json is set.
+type Summary struct {
+	AnalysisType     string            `json:"analysis_type"`
+	TicketsProcessed int               `json:"tickets_processed"`
+	TicketsPersisted int               `json:"tickets_persisted"`
+	StartedAt        time.Time         `json:"started_at"`
+	DurationSeconds  float64           `json:"duration_seconds"`
+	BudgetExhausted  bool              `json:"budget_exhausted"`
+	Interrupted      bool              `json:"interrupted"`
+	Analyses         []AnalysisSummary `json:"analyses"`
+	Errors           []string          `json:"errors"`
+}
+
+// AnalysisSummary holds the outcome of a single scorer or analysis function run.
+type AnalysisSummary struct {
+	Name            string  `json:"name"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	Error           string  `json:"error,omitempty"`
+}
+
+// writeSummary encodes the summary as JSON to stdout if path is "-" or to the file found at path otherwise.
+func writeSummary(s *Summary, path string) error {
+	out := os.Stdout
+	if path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// notifier is sent the summary of a completed run, e.g. to announce it on a chat or alerting webhook.
+type notifier interface {
+	Notify(s *Summary) error
+}
+
+// webhookNotifier posts the JSON summary of a run to the URL set through -notifyURL.
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// Notify posts the summary to the webhook, failing unless it answers with a 2xx status.
+func (n webhookNotifier) Notify(s *Summary) error {
+	body, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notification webhook answered with %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestWriteSummary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "summary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "summary.json")
+
+	want := Summary{
+		AnalysisType:     "all",
+		TicketsProcessed: 3,
+		TicketsPersisted: 2,
+		StartedAt:        time.Date(2018, 3, 1, 10, 0, 0, 0, time.UTC),
+		DurationSeconds:  1.5,
+		BudgetExhausted:  true,
+		Analyses: []AnalysisSummary{
+			{Name: "sentiment", DurationSeconds: 1},
+			{Name: "grammar", DurationSeconds: 0.5, Error: "quota exceeded"},
+		},
+		Errors: []string{"grammar: quota exceeded"},
+	}
+	if err := writeSummary(&want, path); err != nil {
+		t.Fatalf("could not write summary: %v", err)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Summary
+	if err := json.Unmarshal(content, &got); err != nil {
+		t.Fatalf("summary is not valid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decoded summary = %+v, want %+v", got, want)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(content, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{
+		"analysis_type", "tickets_processed", "tickets_persisted", "started_at", "duration_seconds",
+		"budget_exhausted", "interrupted", "analyses", "errors",
+	} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("summary misses the %s field", key)
+		}
+	}
+	analyses := fields["analyses"].([]interface{})
+	if _, ok := analyses[0].(map[string]interface{})["error"]; ok {
+		t.Errorf("successful analysis has an error field")
+	}
+}
+
+func TestWriteSummaryUnwritablePath(t *testing.T) {
+	if err := writeSummary(&Summary{}, filepath.Join("does", "not", "exist", "summary.json")); err == nil {
+		t.Error("expected an error writing the summary to a missing directory")
+	}
+}
+
+func TestRunFillsSummary(t *testing.T) {
+	tickets := []jira.JiraIssue{{Key: "S-1"}, {Key: "S-2"}, {Key: "S-3"}, {Key: "S-4"}, {Key: "S-5"}}
+	clients := []namedScorer{
+		{"spam", scorerFunc(func(...jira.JiraIssue) error { return nil })},
+		{"grammar", scorerFunc(func(...jira.JiraIssue) error { return errors.New("quota exceeded") })},
+	}
+	analysisFuncs := []namedAnalysis{{"attachments", func(...jira.JiraIssue) {}}}
+	scorerSummaries := []AnalysisSummary{{Name: "spam"}, {Name: "grammar"}}
+	analysisSummaries := []AnalysisSummary{{Name: "attachments"}}
+	summary := &Summary{TicketsProcessed: len(tickets), StartedAt: time.Now(), Errors: []string{}}
+
+	// the store fails to persist the last batch, after the grammar scorer failed on all three.
+	var interrupted int32
+	err := persistInBatches(context.Background(), &interrupted, &failingStore{failAt: 3}, summary, tickets, 2,
+		func(batch []jira.JiraIssue) {
+			processBatch(batch, clients, analysisFuncs, scorerSummaries, analysisSummaries)
+		})
+	finishSummary(summary, append(scorerSummaries, analysisSummaries...), err)
+
+	if summary.TicketsProcessed != 5 || summary.TicketsPersisted != 4 {
+		t.Errorf("summary counts %d processed and %d persisted tickets, want 5 and 4", summary.TicketsProcessed,
+			summary.TicketsPersisted)
+	}
+	if len(summary.Analyses) != 3 || summary.Analyses[1].Name != "grammar" || summary.Analyses[2].Error != "" {
+		t.Errorf("analyses = %+v, want spam, grammar and attachments", summary.Analyses)
+	}
+	if len(summary.Errors) != 2 ||
+		summary.Errors[0] != "grammar: quota exceeded; quota exceeded; quota exceeded" ||
+		!strings.Contains(summary.Errors[1], "4 of 5 tickets were already persisted") {
+		t.Errorf("errors = %q, want the grammar errors of every batch and the failed insert", summary.Errors)
+	}
+	if summary.DurationSeconds <= 0 {
+		t.Errorf("duration = %v, want the time elapsed since the run started", summary.DurationSeconds)
+	}
+}
+
+// countingNotifier records the summaries it is notified of.
+type countingNotifier struct {
+	notified []Summary
+}
+
+func (n *countingNotifier) Notify(s *Summary) error {
+	n.notified = append(n.notified, *s)
+	return nil
+}
+
+func TestCompleteRunNotifiesOnce(t *testing.T) {
+	for _, interrupt := range []bool{false, true} {
+		tickets := []jira.JiraIssue{{Key: "N-1"}, {Key: "N-2"}, {Key: "N-3"}}
+		summary := &Summary{TicketsProcessed: len(tickets), StartedAt: time.Now(), Errors: []string{}}
+		var interrupted int32
+		err := persistInBatches(context.Background(), &interrupted, &failingStore{}, summary, tickets, 1,
+			func([]jira.JiraIssue) {
+				if interrupt {
+					atomic.StoreInt32(&interrupted, 1)
+				}
+			})
+		notify := &countingNotifier{}
+		completeRun(summary, nil, err, "", notify)
+
+		if len(notify.notified) != 1 {
+			t.Fatalf("notifications of a run interrupted: %v = %d, want 1", interrupt, len(notify.notified))
+		}
+		if got := notify.notified[0]; got.Interrupted != interrupt || got.DurationSeconds <= 0 {
+			t.Errorf("notified summary of a run interrupted: %v = %+v, want it finished", interrupt, got)
+		}
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var received Summary
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	notify := webhookNotifier{url: server.URL, client: server.Client()}
+	if err := notify.Notify(&Summary{AnalysisType: "spam", TicketsPersisted: 7}); err != nil {
+		t.Fatalf("could not notify webhook: %v", err)
+	}
+	if received.AnalysisType != "spam" || received.TicketsPersisted != 7 {
+		t.Errorf("webhook received %+v, want the run summary", received)
+	}
+	if err := (webhookNotifier{url: server.URL + "/down", client: server.Client()}).Notify(&Summary{}); err == nil {
+		t.Error("notifying an unavailable webhook succeeded")
+	}
+}
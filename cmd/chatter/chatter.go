@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/db"
+	"log"
+)
+
+var (
+	dbPath = flag.String(
+		"dbPath",
+		"/Users/nclandrei/Code/go/src/github.com/nclandrei/ticketguru/issues.db",
+		"path to Bolt database file",
+	)
+	minComments = flag.Int("minComments", analyze.PostResolutionChatterComments, "minimum number of comments "+
+		"created after its resolution for a ticket to be listed")
+)
+
+func main() {
+	flag.Parse()
+
+	boltDB, err := db.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("could not open bolt db: %v\n", err)
+	}
+	tickets, err := boltDB.Tickets()
+	if err != nil {
+		log.Fatalf("could not get tickets from bolt db: %v\n", err)
+	}
+
+	for _, t := range analyze.PostResolutionChatter(*minComments, tickets...) {
+		fmt.Printf("%s\t%d\n", t.Key, int(t.Value))
+	}
+}
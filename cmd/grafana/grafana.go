@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/db"
+	"log"
+	"os"
+	"time"
+)
+
+var (
+	dbPath = flag.String(
+		"dbPath",
+		"/Users/nclandrei/Code/go/src/github.com/nclandrei/ticketguru/issues.db",
+		"path to Bolt database file",
+	)
+	format      = flag.String("format", "influx", "format of the exported series: influx (line protocol) or json")
+	measurement = flag.String("measurement", "ticketguru", "InfluxDB measurement the series are written under")
+	bucket      = flag.Duration("bucket", 24*time.Hour, "time bucket the resolved tickets are grouped by")
+	out         = flag.String("out", "-", "path of the file the series are written to; - writes them to stdout")
+)
+
+func main() {
+	flag.Parse()
+	if *format != "influx" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "%s is not a valid format; available formats are influx and json\n", *format)
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *bucket <= 0 {
+		fmt.Fprintln(os.Stderr, "bucket must be positive")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	boltDB, err := db.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("could not open bolt db: %v\n", err)
+	}
+	tickets, err := boltDB.Tickets()
+	if err != nil {
+		log.Fatalf("could not get tickets from bolt db: %v\n", err)
+	}
+
+	output := os.Stdout
+	if *out != "-" {
+		file, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("could not create export file: %v\n", err)
+		}
+		defer file.Close()
+		output = file
+	}
+	points := analyze.MetricTimeSeries(*bucket, tickets...)
+	if *format == "json" {
+		err = analyze.WriteGrafanaJSON(output, points)
+	} else {
+		err = analyze.WriteLineProtocol(output, *measurement, points)
+	}
+	if err != nil {
+		log.Fatalf("could not write series: %v\n", err)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/db"
+	"log"
+)
+
+var dbPath = flag.String(
+	"dbPath",
+	"/Users/nclandrei/Code/go/src/github.com/nclandrei/ticketguru/issues.db",
+	"path to Bolt database file",
+)
+
+func main() {
+	flag.Parse()
+
+	boltDB, err := db.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("could not open bolt db: %v\n", err)
+	}
+	tickets, err := boltDB.Tickets()
+	if err != nil {
+		log.Fatalf("could not get tickets from bolt db: %v\n", err)
+	}
+
+	for _, m := range analyze.PriorityResolutionMismatches(tickets...) {
+		fmt.Printf("%s\t%s\t%.2f\t%s\n", m.Key, m.Priority, m.TimeToClose, m.Reason)
+	}
+}
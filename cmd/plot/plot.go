@@ -3,11 +3,18 @@ package main
 import (
 	"flag"
 	"fmt"
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/config"
 	"github.com/nclandrei/ticketguru/db"
+	"github.com/nclandrei/ticketguru/jira"
 	"github.com/nclandrei/ticketguru/plot"
 	"log"
 	"os"
-	"sync"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
 )
 
 var (
@@ -17,11 +24,160 @@ var (
 		"path to Bolt database file",
 	)
 	pType = flag.String("type", "all", "plot(s) to draw - available types: grammar, sentiment, steps_to_reprodce"+
-		"stack_traces, attachments, comments_complexity, fields_complexity, all")
+		"stack_traces, attachments, comments_complexity, fields_complexity, summary_complexity, "+
+		"description_complexity, wordiness, grammar_sentiment, cumulative_resolved, correlation_matrix, "+
+		"slowest_transitions, resolution_trend, backlog, comment_length, reading_time, "+
+		"weekly_throughput, first_response, creation_weekday, active_waiting, "+
+		"monthly_resolution, creation_rate, attachment_percentiles, word_histogram, "+
+		"reopen_distribution, reopen_rate_description, control_chart, all")
+	wordinessField = flag.String("wordinessField", "description", "field(s) whose word count feeds the wordiness plot; "+
+		"available fields: summary, description, comment, summary+description")
+	minWords = flag.Int("minWords", 0, "exclude tickets with fewer words than this across summary, description "+
+		"and comments from the complexity plots; 0 keeps all tickets")
+	chartsToDB        = flag.Bool("chartsToDB", false, "store rendered charts inside the Bolt database")
+	bucket            = flag.Duration("bucket", 7*24*time.Hour, "time bucket used by the plots over time")
+	colorByPercentile = flag.Bool("colorByPercentile", false, "color scatter plot points by the percentile rank "+
+		"of their y value instead of its absolute value")
+	hoursMode = flag.String("hours", analyze.CalendarHours, "hours resolution times are counted in: calendar or "+
+		"business; business hours recompute the time-to-close of every ticket")
+	workCalendar = flag.String("workCalendar", "", "path to a JSON work calendar defining the business hours; "+
+		"Monday to Friday, 9 to 17 UTC if empty")
+	excludeSpam = flag.Bool("excludeSpam", false, "exclude the tickets flagged as spam by the analyze "+
+		"command from all plots")
+	excludeResolutions = flag.String("excludeResolutions", "", "comma separated resolutions (e.g. Duplicate) of "+
+		"the tickets excluded from all plots")
+	excludeProjects = flag.String("excludeProjects", "", "comma separated key prefixes (e.g. TEST-) of the "+
+		"internal or test projects whose tickets are excluded from all plots")
+	pointSidecar = flag.Bool("pointSidecar", false, "write next to each scatter plot a JSON file mapping the "+
+		"coordinates of every point to its ticket key")
+	yMax = flag.Float64("yMax", 0, "fix the upper bound of the scatter plots' Y axis, omitting the points above "+
+		"it; 0 fits the axis to the data")
+	yLabel         = flag.String("yLabel", "", "label of the scatter plots' Y axis; each plot's default if empty")
+	trendWindow    = flag.Int("trendWindow", 4, "number of buckets the resolution trend rolling mean spans")
+	volumeWeighted = flag.Bool("volumeWeighted", false, "weigh the resolution trend rolling mean by the number of "+
+		"tickets in each bucket")
+	minVolume     = flag.Int("minVolume", 5, "annotate resolution trend buckets holding fewer tickets than this")
+	commentBucket = flag.Int("commentBucket", 50, "number of words per bucket of the comment length plot")
+	essayWords    = flag.Int("essayWords", analyze.EssayCommentWords, "number of words above which a comment "+
+		"counts as essay-length")
+	wpm = flag.Int("wpm", analyze.DefaultWordsPerMinute, "reading speed, in words per minute, of the "+
+		"reading time plot")
+	responseBucket = flag.Int("responseBucket", 24, "number of hours per bucket of the first response plot")
+	readingBucket  = flag.Int("readingBucket", 1, "number of minutes per bucket of the reading time plot")
+	timezone       = flag.String("timezone", "UTC", "IANA time zone in which the creation weekdays are computed")
+	transitions    = flag.Int("transitions", 10, "number of transitions drawn by the slowest transitions plot")
+	chartFiles     = flag.Bool("chartFiles", true, "write rendered charts to the output directory")
+	outputDir      = flag.String("outputDir", plot.GraphsFolder, "directory rendered charts are written to")
+	runDirs        = flag.Bool("runDirs", false, "write the charts of each run to a new timestamped directory "+
+		"inside the output directory and point its latest symlink to it")
+	runDirLayout = flag.String("runDirLayout", time.RFC3339, "time layout the run directories are named with")
+	concurrency  = flag.Int("concurrency", runtime.NumCPU(), "maximum number of charts rendered at once")
+	palette      = flag.String("palette", "viridis", "palette used to color scatter plot points: viridis, cividis "+
+		"or grayscale")
+	excludeCode = flag.Bool("excludeCode", false, "strip code blocks before counting words for the comments and "+
+		"fields complexity plots")
+	sampleSize = flag.Int("sampleSize", 0, "draw the complexity and wordiness plots from a weighted sample of this "+
+		"many tickets; 0 plots all tickets")
+	sampleSeed      = flag.Int64("sampleSeed", 1, "seed of the weighted sample, for reproducible plots")
+	priorityWeights = flag.String("priorityWeights", "1=4,2=2", "comma separated priority ID or name to weight "+
+		"pairs used by the weighted sample and the priority-weighted quality; priorities not listed weigh 1")
+	priorityWeightsFile = flag.String("priorityWeightsFile", "", "path of a JSON file mapping priority IDs or "+
+		"names to weights, used instead of -priorityWeights if set")
+	waitingStatuses = flag.String("waitingStatuses", "Waiting for Customer,Waiting for Support", "comma separated "+
+		"statuses counted as waiting by the active vs waiting plot")
+	pdfPath = flag.String("pdf", "", "path of a PDF report gathering a summary table of headline metrics and all "+
+		"the charts of the output directory; no report is written if empty")
+	trimPct = flag.Float64("trimPct", 0, "fraction, in [0, 0.5), of the fastest and of the slowest tickets "+
+		"left out of the mean time-to-close of the attachments, steps to reproduce, stack traces and creation "+
+		"weekday bars")
+	descriptionBucket = flag.Int("descriptionBucket", 50, "number of description words per bucket of the reopen "+
+		"rate by description length plot")
+	wordBin = flag.Int("wordBin", 50, "number of words per bin of the word count histogram of the wordiness field")
 )
 
+// excludeLowSignal wraps a plotting function so that it only receives tickets holding at least minWords words.
+func excludeLowSignal(f plot.Plot) plot.Plot {
+	if *minWords <= 0 {
+		return f
+	}
+	return func(tickets ...jira.JiraIssue) error {
+		return f(analyze.ExcludeLowSignal(*minWords, tickets...)...)
+	}
+}
+
+// scatterPlot wraps a scatter plotting function so that it excludes low signal tickets and, if requested,
+// only receives a weighted sample of the tickets.
+func scatterPlot(f plot.Plot, weights analyze.PriorityWeights) plot.Plot {
+	return plot.SampledByPriority(excludeLowSignal(f), weights, *sampleSize, *sampleSeed)
+}
+
 func main() {
 	flag.Parse()
+	plot.ColorByPercentile = *colorByPercentile
+	plot.ExcludeCode = *excludeCode
+	plot.PointSidecar = *pointSidecar
+	plot.ScatterYMax = *yMax
+	plot.ScatterYLabel = *yLabel
+	plot.OutputDir = *outputDir
+	scatterPalette, err := plot.PaletteByName(*palette)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(1)
+	}
+	plot.ScatterPalette = scatterPalette
+	plot.TrimPct = *trimPct
+
+	wordiness, err := plot.WordinessAnalysis(*wordinessField)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	wordHistogram, err := plot.WordCountHistogram(*wordinessField, *wordBin)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	reopenRate, err := plot.ReopenRateByDescriptionLength(*descriptionBucket)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	weights, err := analyze.ParsePriorityWeights(*priorityWeights)
+	if *priorityWeightsFile != "" {
+		weights, err = analyze.LoadPriorityWeights(*priorityWeightsFile)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	wordiness = scatterPlot(wordiness, weights)
+	commentsComplexity := scatterPlot(plot.CommentsComplexity, weights)
+	fieldsComplexity := scatterPlot(plot.FieldsComplexity, weights)
+	summaryComplexity := scatterPlot(plot.SummaryComplexity, weights)
+	descriptionComplexity := scatterPlot(plot.DescriptionComplexity, weights)
+
+	commentLength := plot.CommentLengthDistribution(*commentBucket, *essayWords)
+	readingTime := plot.ReadingTimeDistribution(*wpm, *readingBucket)
+	firstResponse := plot.FirstResponseDistribution(*responseBucket)
+	resolutionTrend := plot.ResolutionTrend(*bucket, *trendWindow, *volumeWeighted, *minVolume)
+
+	loc, err := time.LoadLocation(*timezone)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(1)
+	}
+	creationWeekday := plot.CreationDayOfWeek(loc)
+	activeWaiting := plot.ActiveVsWaiting(strings.Split(*waitingStatuses, ","))
 
 	var funcs []plot.Plot
 	switch *pType {
@@ -41,14 +197,86 @@ func main() {
 		funcs = append(funcs, plot.Attachments)
 		break
 	case "comments_complexity":
-		funcs = append(funcs, plot.CommentsComplexity)
+		funcs = append(funcs, commentsComplexity)
 		break
 	case "fields_complexity":
-		funcs = append(funcs, plot.FieldsComplexity)
+		funcs = append(funcs, fieldsComplexity)
+		break
+	case "summary_complexity":
+		funcs = append(funcs, summaryComplexity)
+		break
+	case "description_complexity":
+		funcs = append(funcs, descriptionComplexity)
+		break
+	case "wordiness":
+		funcs = append(funcs, wordiness)
+		break
+	case "grammar_sentiment":
+		funcs = append(funcs, plot.GrammarSentiment)
+		break
+	case "cumulative_resolved":
+		funcs = append(funcs, plot.CumulativeResolved(*bucket))
+		break
+	case "correlation_matrix":
+		funcs = append(funcs, plot.CorrelationMatrix)
+		break
+	case "slowest_transitions":
+		funcs = append(funcs, plot.SlowestTransitions(*transitions))
+		break
+	case "resolution_trend":
+		funcs = append(funcs, resolutionTrend)
+		break
+	case "backlog":
+		funcs = append(funcs, plot.BacklogOverTime(*bucket))
+		break
+	case "comment_length":
+		funcs = append(funcs, commentLength)
+		break
+	case "reading_time":
+		funcs = append(funcs, readingTime)
+		break
+	case "weekly_throughput":
+		funcs = append(funcs, plot.WeeklyThroughput)
+		break
+	case "first_response":
+		funcs = append(funcs, firstResponse)
+		break
+	case "creation_weekday":
+		funcs = append(funcs, creationWeekday)
+		break
+	case "active_waiting":
+		funcs = append(funcs, activeWaiting)
+		break
+	case "monthly_resolution":
+		funcs = append(funcs, plot.MonthlyResolution)
+		break
+	case "creation_rate":
+		funcs = append(funcs, plot.CreationRate(*bucket))
+		break
+	case "attachment_percentiles":
+		funcs = append(funcs, plot.AttachmentPercentiles)
+		break
+	case "word_histogram":
+		funcs = append(funcs, wordHistogram)
+		break
+	case "reopen_distribution":
+		funcs = append(funcs, plot.ReopenDistribution)
+		break
+	case "reopen_rate_description":
+		funcs = append(funcs, reopenRate)
+		break
+	case "control_chart":
+		funcs = append(funcs, plot.ControlChart)
 		break
 	case "all":
-		funcs = append(funcs, plot.CommentsComplexity, plot.FieldsComplexity, plot.SentimentAnalysis,
-			plot.GrammarCorrectness, plot.Stacktraces, plot.StepsToReproduce, plot.Attachments)
+		funcs = append(funcs, commentsComplexity, fieldsComplexity, summaryComplexity, descriptionComplexity,
+			plot.SentimentAnalysis, plot.GrammarCorrectness, plot.Stacktraces, plot.StepsToReproduce,
+			plot.Attachments, wordiness, plot.GrammarSentiment, plot.CumulativeResolved(*bucket),
+			plot.CorrelationMatrix, plot.SlowestTransitions(*transitions),
+			resolutionTrend, plot.BacklogOverTime(*bucket), commentLength, readingTime,
+			plot.WeeklyThroughput, firstResponse, creationWeekday, activeWaiting,
+			plot.MonthlyResolution, plot.CreationRate(*bucket), plot.AttachmentPercentiles,
+			wordHistogram, plot.ReopenDistribution, reopenRate, plot.ControlChart)
 		break
 	default:
 		fmt.Fprintln(os.Stderr, "plot type not available")
@@ -56,25 +284,101 @@ func main() {
 		os.Exit(1)
 	}
 
-	boltDB, err := db.NewBolt(*dbPath)
+	var validator config.Validator
+	validator.RequireWritableFile(*dbPath)
+	if *chartFiles {
+		validator.RequireDir(*outputDir)
+	} else if !*chartsToDB {
+		validator.Add("charts must be written to the database, the filesystem or both")
+	}
+	if *commentBucket <= 0 {
+		validator.Add("comment bucket must be positive")
+	}
+	if *readingBucket <= 0 {
+		validator.Add("reading time bucket must be positive")
+	}
+	if *responseBucket <= 0 {
+		validator.Add("first response bucket must be positive")
+	}
+	if *trimPct < 0 || *trimPct >= 0.5 {
+		validator.Add("trim fraction must lie in [0, 0.5)")
+	}
+	if *pdfPath != "" {
+		if !*chartFiles {
+			validator.Add("the PDF report needs the charts to be written to the filesystem")
+		}
+		validator.RequireWritableFile(*pdfPath)
+	}
+	calendar, err := analyze.CalendarFor(*hoursMode, *workCalendar)
+	if err != nil {
+		validator.Add("%v", err)
+	}
+	if err := validator.Err(); err != nil {
+		log.Fatalln(err)
+	}
+	if *chartFiles && *runDirs {
+		dir, err := plot.NewRunDir(*outputDir, *runDirLayout, time.Now())
+		if err != nil {
+			log.Fatalln(err)
+		}
+		plot.OutputDir = dir
+		log.Printf("writing charts to %s\n", dir)
+	}
+
+	boltDB, err := db.Open(*dbPath)
 	if err != nil {
 		log.Fatalf("could not open bolt db: %v\n", err)
 	}
-	tickets, err := boltDB.Tickets()
+	if *chartsToDB {
+		plot.StoreCharts(boltDB, *chartFiles)
+	}
+	tickets, excluded, err := boltDB.TicketsExcludingProjects(strings.Split(*excludeProjects, ","))
 	if err != nil {
 		log.Fatalf("could not get tickets from bolt db: %v\n", err)
 	}
+	if excluded > 0 {
+		log.Printf("excluded %d tickets of excluded projects\n", excluded)
+	}
+	if *excludeResolutions != "" {
+		var excluded int
+		tickets, excluded = analyze.ExcludeResolutions(strings.Split(*excludeResolutions, ","), tickets...)
+		log.Printf("excluded %d tickets by resolution\n", excluded)
+	}
+	if *excludeSpam {
+		var excluded int
+		tickets, excluded = analyze.ExcludeSpam(tickets...)
+		log.Printf("excluded %d spam tickets\n", excluded)
+	}
+	if unmapped := weights.Unmapped(tickets...); len(unmapped) > 0 {
+		log.Printf("priorities without a weight, weighing 1: %s\n", strings.Join(unmapped, ", "))
+	}
+	if calendar != nil {
+		analyze.TimesToCloseWith(analyze.ResolutionOptions{Calendar: calendar})(tickets...)
+	}
+
+	if err := plot.RenderAll(*concurrency, funcs, tickets...); err != nil {
+		log.Fatalf("could not plot data: %v\n", err)
+	}
+
+	if *pdfPath != "" {
+		if err := writePDFReport(*pdfPath, weights, tickets...); err != nil {
+			log.Fatalf("could not write PDF report: %v\n", err)
+		}
+	}
+}
 
-	var wg sync.WaitGroup
-	for _, f := range funcs {
-		wg.Add(1)
-		go func(f plot.Plot) {
-			defer wg.Done()
-			err := f(tickets...)
-			if err != nil {
-				log.Printf("could not plot data: %v\n", err)
-			}
-		}(f)
+// writePDFReport writes to path a PDF report of the headline metrics of the tickets, weighing their quality with
+// weights, and of all the charts found inside the output directory.
+func writePDFReport(path string, weights analyze.PriorityWeights, tickets ...jira.JiraIssue) error {
+	charts, err := filepath.Glob(filepath.Join(plot.OutputDir, "*.png"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(charts)
+	file, err := os.Create(path)
+	if err != nil {
+		return err
 	}
-	wg.Wait()
+	defer file.Close()
+	return plot.PDFReport(file, "ticketguru report", plot.HeadlineMetrics(weights, tickets...), charts)
 }
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/db"
+	"log"
+	"os"
+	"strings"
+)
+
+var (
+	dbPath = flag.String(
+		"dbPath",
+		"/Users/nclandrei/Code/go/src/github.com/nclandrei/ticketguru/issues.db",
+		"path to Bolt database file",
+	)
+	where = flag.String("where", "", "comma separated metric predicates all matching tickets satisfy, e.g. "+
+		"\"sentiment<-0.5,time_to_close>100\"; available metrics are those of the top command plus sentiment "+
+		"and grammar_errors")
+)
+
+func main() {
+	flag.Parse()
+
+	var predicates []analyze.Predicate
+	for _, s := range strings.Split(*where, ",") {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		p, err := analyze.ParsePredicate(s)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			flag.Usage()
+			os.Exit(1)
+		}
+		predicates = append(predicates, p)
+	}
+
+	boltDB, err := db.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("could not open bolt db: %v\n", err)
+	}
+	tickets, err := boltDB.Tickets()
+	if err != nil {
+		log.Fatalf("could not get tickets from bolt db: %v\n", err)
+	}
+
+	keys, err := analyze.Query(predicates, tickets...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(1)
+	}
+	for _, key := range keys {
+		fmt.Println(key)
+	}
+}
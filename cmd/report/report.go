@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/db"
+	"github.com/nclandrei/ticketguru/jira"
+	"github.com/nclandrei/ticketguru/plot"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	dbPath = flag.String(
+		"dbPath",
+		"/Users/nclandrei/Code/go/src/github.com/nclandrei/ticketguru/issues.db",
+		"path to Bolt database file",
+	)
+	key        = flag.String("key", "", "key of the ticket to report on")
+	trajectory = flag.String("trajectory", "", "directory to draw the sentiment trajectory of the ticket's comments "+
+		"into, scoring each comment with GCP; no trajectory is drawn if empty")
+)
+
+func main() {
+	flag.Parse()
+	if *key == "" {
+		fmt.Fprintln(os.Stderr, "a ticket key is required")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	boltDB, err := db.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("could not open bolt db: %v\n", err)
+	}
+	ticket, err := boltDB.TicketByKey(*key)
+	if err != nil {
+		log.Fatalf("could not get ticket %s from bolt db: %v\n", *key, err)
+	}
+	if ticket == nil {
+		log.Fatalf("ticket %s not found inside bolt db\n", *key)
+	}
+
+	printReport(analyze.BuildReport(*ticket))
+
+	if *trajectory != "" {
+		if err := drawTrajectory(*ticket, *trajectory); err != nil {
+			log.Fatalf("could not draw sentiment trajectory: %v\n", err)
+		}
+	}
+}
+
+// drawTrajectory scores every comment of the ticket and draws their sentiment trajectory inside dir.
+func drawTrajectory(ticket jira.JiraIssue, dir string) error {
+	if err := godotenv.Load(); err != nil {
+		return fmt.Errorf("could not load .env file: %v", err)
+	}
+	client, err := analyze.NewSentimentClient(context.Background())
+	if err != nil {
+		return fmt.Errorf("could not create GCP sentiment client: %v", err)
+	}
+	scores, err := client.CommentScores(ticket)
+	if err != nil {
+		return err
+	}
+	plot.OutputDir = dir
+	return plot.SentimentTrajectory(ticket, scores)
+}
+
+// printReport writes a human readable version of the report to stdout.
+func printReport(r analyze.Report) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "Key:\t%s\n", r.Key)
+	fmt.Fprintf(w, "Summary:\t%s\n", r.Summary)
+	fmt.Fprintf(w, "Priority:\t%s\n", r.Priority)
+	fmt.Fprintf(w, "Status:\t%s\n", r.Status)
+	fmt.Fprintf(w, "Created:\t%s\n", r.Created.Format(time.RFC3339))
+	if r.Anomaly != "" {
+		fmt.Fprintf(w, "Anomaly:\t%s\n", r.Anomaly)
+	}
+	if r.Resolved {
+		fmt.Fprintf(w, "Resolved:\t%s\n", r.ResolvedAt.Format(time.RFC3339))
+		fmt.Fprintf(w, "Time to close:\t%.2fh\n", r.TimeToClose)
+	} else {
+		fmt.Fprintf(w, "Resolved:\tno\n")
+	}
+
+	fmt.Fprintf(w, "\nSummary words:\t%d\n", r.SummaryWords)
+	fmt.Fprintf(w, "Description words:\t%d\n", r.DescriptionWords)
+	fmt.Fprintf(w, "Comment words:\t%d\n", r.CommentWords)
+	fmt.Fprintf(w, "Comments:\t%d\n", r.Comments)
+	if r.Resolved {
+		fmt.Fprintf(w, "Comments after resolution:\t%d\n", r.PostResolutionComments)
+	}
+	fmt.Fprintf(w, "Attachments:\t%d\n", r.Attachments)
+	fmt.Fprintf(w, "Links:\t%d\n", r.Links)
+	fmt.Fprintf(w, "Reassignments:\t%d\n", r.Reassignments)
+	fmt.Fprintf(w, "Quality score:\t%.2f\n", r.QualityScore)
+
+	if r.Sentiment != nil {
+		fmt.Fprintf(w, "Sentiment:\t%.2f\n", *r.Sentiment)
+	} else {
+		fmt.Fprintf(w, "Sentiment:\tnot scored\n")
+	}
+	if r.GrammarCorrectness != nil {
+		fmt.Fprintf(w, "Grammar errors:\t%d\n", *r.GrammarCorrectness)
+	} else {
+		fmt.Fprintf(w, "Grammar errors:\tnot scored\n")
+	}
+
+	fmt.Fprintf(w, "\nHeuristics:\n")
+	if len(r.Heuristics) == 0 {
+		fmt.Fprintf(w, "  none detected\n")
+	}
+	names := make([]string, 0, len(r.Heuristics))
+	for name := range r.Heuristics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s:\t%q\n", name, r.Heuristics[name])
+	}
+
+	fmt.Fprintf(w, "\nStatus timeline:\n")
+	for _, t := range r.Timeline {
+		fmt.Fprintf(w, "  %s\t%s -> %s\n", t.At.Format(time.RFC3339), t.From, t.To)
+	}
+	statuses := make([]string, 0, len(r.HoursInStatus))
+	for status := range r.HoursInStatus {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	fmt.Fprintf(w, "\nHours in status:\n")
+	for _, status := range statuses {
+		fmt.Fprintf(w, "  %s:\t%.2f\n", status, r.HoursInStatus[status])
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/db"
+	"github.com/nclandrei/ticketguru/export"
+	"log"
+)
+
+var (
+	dbPath = flag.String(
+		"dbPath",
+		"/Users/nclandrei/Code/go/src/github.com/nclandrei/ticketguru/issues.db",
+		"path to Bolt database file",
+	)
+	n          = flag.Int("n", 10, "number of top reporters to list; 0 lists all of them")
+	minTickets = flag.Int("minTickets", analyze.MinReporterTickets, "minimum number of tickets a reporter must "+
+		"have reported to be ranked")
+	out = flag.String("out", "-", "path of the CSV file the ranking is written to; - writes it to stdout")
+)
+
+func main() {
+	flag.Parse()
+
+	boltDB, err := db.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("could not open bolt db: %v\n", err)
+	}
+	tickets, err := boltDB.Tickets()
+	if err != nil {
+		log.Fatalf("could not get tickets from bolt db: %v\n", err)
+	}
+
+	output, err := export.Open(context.Background(), *out, export.Uploaders)
+	if err != nil {
+		log.Fatalf("could not open ranking destination: %v\n", err)
+	}
+	reporters := analyze.TopReportersByQuality(*n, *minTickets, tickets...)
+	if err := analyze.WriteReporterQualityCSV(output, reporters); err != nil {
+		log.Fatalf("could not write reporter ranking: %v\n", err)
+	}
+	if err := output.Close(); err != nil {
+		log.Fatalf("could not write reporter ranking: %v\n", err)
+	}
+}
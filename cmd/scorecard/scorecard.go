@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/db"
+	"github.com/nclandrei/ticketguru/export"
+	"log"
+)
+
+var (
+	dbPath = flag.String(
+		"dbPath",
+		"/Users/nclandrei/Code/go/src/github.com/nclandrei/ticketguru/issues.db",
+		"path to Bolt database file",
+	)
+	out = flag.String("out", "-", "path of the CSV file the scorecards are written to; - writes them to stdout")
+)
+
+func main() {
+	flag.Parse()
+
+	boltDB, err := db.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("could not open bolt db: %v\n", err)
+	}
+	tickets, err := boltDB.Tickets()
+	if err != nil {
+		log.Fatalf("could not get tickets from bolt db: %v\n", err)
+	}
+
+	output, err := export.Open(context.Background(), *out, export.Uploaders)
+	if err != nil {
+		log.Fatalf("could not open scorecard destination: %v\n", err)
+	}
+	if err := analyze.WriteScorecardsCSV(output, analyze.AssigneeScorecard(tickets...)); err != nil {
+		log.Fatalf("could not write scorecards: %v\n", err)
+	}
+	if err := output.Close(); err != nil {
+		log.Fatalf("could not write scorecards: %v\n", err)
+	}
+}
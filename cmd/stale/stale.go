@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/db"
+	"log"
+	"time"
+)
+
+var (
+	dbPath = flag.String(
+		"dbPath",
+		"/Users/nclandrei/Code/go/src/github.com/nclandrei/ticketguru/issues.db",
+		"path to Bolt database file",
+	)
+	count    = flag.Int("n", 20, "number of stale open tickets to list; 0 lists all of them")
+	halfLife = flag.Duration("halfLife", analyze.DefaultFreshnessHalfLife, "time since the last activity on a "+
+		"ticket after which its freshness halves")
+)
+
+func main() {
+	flag.Parse()
+
+	boltDB, err := db.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("could not open bolt db: %v\n", err)
+	}
+	tickets, err := boltDB.Tickets()
+	if err != nil {
+		log.Fatalf("could not get tickets from bolt db: %v\n", err)
+	}
+
+	for _, t := range analyze.StalestOpenTickets(*count, time.Now(), *halfLife, tickets...) {
+		fmt.Printf("%s\t%.4f\n", t.Key, t.Value)
+	}
+}
@@ -2,9 +2,11 @@ package main
 
 import (
 	"flag"
+	"github.com/nclandrei/ticketguru/analyze"
 	"github.com/nclandrei/ticketguru/db"
 	"github.com/nclandrei/ticketguru/stats"
 	"log"
+	"strings"
 	"sync"
 )
 
@@ -16,8 +18,11 @@ var (
 	)
 )
 
+// timeToCloseBounds defines the name under which the baseline time-to-close outlier bounds are stored.
+const timeToCloseBounds = "time_to_close"
+
 func main() {
-	boltDB, err := db.NewBolt(*dbPath)
+	boltDB, err := db.Open(*dbPath)
 	if err != nil {
 		log.Fatalf("could not access Bolt DB: %v\n", err)
 	}
@@ -26,6 +31,22 @@ func main() {
 	flag.StringVar(&analysisType, "type", "all", "type of statistics to run; available types: grammar, sentiment, "+
 		"stack_traces, steps_to_reproduce, attachments, comment_complexity, fields_complexity, all")
 
+	var boundsMode string
+	flag.StringVar(&boundsMode, "bounds", "none", "time-to-close outlier filtering: none, compute (compute IQR bounds "+
+		"from this dataset and save them as the baseline) or saved (reuse the saved baseline bounds)")
+
+	var excludeSpam bool
+	flag.BoolVar(&excludeSpam, "excludeSpam", false, "exclude the tickets flagged as spam by the analyze command "+
+		"from the statistics")
+
+	var excludedResolutions string
+	flag.StringVar(&excludedResolutions, "excludeResolutions", "", "comma separated resolutions (e.g. Duplicate) "+
+		"of the tickets excluded from the statistics")
+
+	var excludedProjects string
+	flag.StringVar(&excludedProjects, "excludeProjects", "", "comma separated key prefixes (e.g. TEST-) of the "+
+		"internal or test projects whose tickets are excluded from the statistics")
+
 	flag.Parse()
 
 	categoricalTests := map[string]stats.CategoricalTest{
@@ -34,16 +55,58 @@ func main() {
 		"Stack Traces":       stats.Stacktraces,
 	}
 	continuousTests := map[string]stats.ContinuousTest{
-		"Comments Complexity": stats.CommentsComplexity,
-		"Fields Complexity":   stats.FieldsComplexity,
-		"Sentiment Analysis":  stats.Sentiment,
-		"Grammar Correctness": stats.Grammar,
+		"Comments Complexity":    stats.CommentsComplexity,
+		"Fields Complexity":      stats.FieldsComplexity,
+		"Summary Complexity":     stats.SummaryComplexity,
+		"Description Complexity": stats.DescriptionComplexity,
+		"Sentiment Analysis":     stats.Sentiment,
+		"Grammar Correctness":    stats.Grammar,
+		"Reassignments":          stats.Reassignments,
+		"Description Ratio":      stats.DescriptionCommentRatio,
+		"Links":                  stats.Links,
 	}
 
-	tickets, err := boltDB.Tickets()
+	tickets, excluded, err := boltDB.TicketsExcludingProjects(strings.Split(excludedProjects, ","))
 	if err != nil {
 		log.Fatalf("could not fetch tickets from bolt db: %v\n", err)
 	}
+	if excluded > 0 {
+		log.Printf("excluded %d tickets of excluded projects\n", excluded)
+	}
+	if excludedResolutions != "" {
+		var excluded int
+		tickets, excluded = analyze.ExcludeResolutions(strings.Split(excludedResolutions, ","), tickets...)
+		log.Printf("excluded %d tickets by resolution\n", excluded)
+	}
+	if excludeSpam {
+		var excluded int
+		tickets, excluded = analyze.ExcludeSpam(tickets...)
+		log.Printf("excluded %d spam tickets\n", excluded)
+	}
+
+	switch boundsMode {
+	case "none":
+		break
+	case "compute":
+		bounds := analyze.TimeToCloseBounds(tickets...)
+		if err := boltDB.SaveBounds(timeToCloseBounds, bounds); err != nil {
+			log.Fatalf("could not save outlier bounds: %v\n", err)
+		}
+		tickets = analyze.ExcludeTimeToCloseOutliers(bounds, tickets...)
+		break
+	case "saved":
+		bounds, found, err := boltDB.LoadBounds(timeToCloseBounds)
+		if err != nil {
+			log.Fatalf("could not load outlier bounds: %v\n", err)
+		}
+		if !found {
+			log.Fatalf("no saved outlier bounds; run with -bounds=compute on a baseline dataset first\n")
+		}
+		tickets = analyze.ExcludeTimeToCloseOutliers(bounds, tickets...)
+		break
+	default:
+		log.Fatalf("%s is not a valid bounds mode; available modes are none, compute and saved\n", boundsMode)
+	}
 
 	var wg sync.WaitGroup
 	for k, v := range categoricalTests {
@@ -72,4 +135,8 @@ func main() {
 	}
 
 	wg.Wait()
+
+	withMean, withoutMean, difference, p := stats.EffectOfSteps(tickets...)
+	log.Printf("Effect Of Steps --- mean_with: %f --- mean_without: %f --- difference: %.2f%% --- P: %f\n",
+		withMean, withoutMean, difference, p)
 }
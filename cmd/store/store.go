@@ -2,12 +2,16 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"github.com/joho/godotenv"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 
+	"github.com/nclandrei/ticketguru/config"
 	"github.com/nclandrei/ticketguru/db"
 
 	"log"
@@ -22,14 +26,42 @@ import (
 const maxNoGoroutines = 100
 
 var (
-	jiraURL     = flag.String("jiraURL", "http://issues.apache.org", "URL for Jira instance")
-	project     = flag.String("project", "Kafka", "name of the project to be queried upon")
-	gortnCnt    = flag.Int("goroutinesCount", maxNoGoroutines, "number of goroutines to be used")
-	dbPath      = flag.String("dbPath", "issues.db", "absolute path to the Bolt database")
-	logToFile   = flag.Bool("file_log", false, "specifies whether application should log to file or not")
-	logFilePath = flag.String("log_path", "~/Code/go/src/github.com/nclandrei/ticketguru/log.txt", "path to logging file")
+	jiraURL      = flag.String("jiraURL", "http://issues.apache.org", "URL for Jira instance")
+	project      = flag.String("project", "Kafka", "name of the project to be queried upon")
+	gortnCnt     = flag.Int("goroutinesCount", maxNoGoroutines, "number of goroutines to be used")
+	dbPath       = flag.String("dbPath", "issues.db", "absolute path to the Bolt database")
+	logToFile    = flag.Bool("file_log", false, "specifies whether application should log to file or not")
+	logFilePath  = flag.String("log_path", "~/Code/go/src/github.com/nclandrei/ticketguru/log.txt", "path to logging file")
+	fieldMapping = flag.String("fieldMapping", "", "path to a JSON file mapping standard Jira field keys to the "+
+		"keys holding them in the instance's responses")
+	shards = flag.Int("shards", 0, "number of buckets tickets are spread across by key hash; 0 keeps the number "+
+		"the database is already sharded with, if any")
+	maxAttempts = flag.Int("maxAttempts", jira.DefaultRetryPolicy.MaxAttempts, "maximum number of times a Jira "+
+		"request is sent; 1 fails fast")
+	retryBaseDelay = flag.Duration("retryBaseDelay", jira.DefaultRetryPolicy.BaseDelay, "delay before the first "+
+		"retry of a Jira request, doubled before each further one")
+	retryMaxDelay = flag.Duration("retryMaxDelay", jira.DefaultRetryPolicy.MaxDelay, "maximum delay between two "+
+		"attempts of a Jira request; 0 leaves it uncapped")
+	retryStatuses = flag.String("retryStatuses", "429,502,503,504", "comma separated HTTP status codes of the "+
+		"Jira responses that are retried")
 )
 
+// parseStatuses parses comma separated HTTP status codes, e.g. 429,503.
+func parseStatuses(s string) ([]int, error) {
+	var statuses []int
+	if strings.TrimSpace(s) == "" {
+		return statuses, nil
+	}
+	for _, code := range strings.Split(s, ",") {
+		status, err := strconv.Atoi(strings.TrimSpace(code))
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry status %q: %v", code, err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
 func main() {
 	flag.Parse()
 
@@ -57,13 +89,29 @@ func main() {
 		os.Exit(1)
 	}()
 
-	err := godotenv.Load()
-	if err != nil {
-		logger.Fatalf("could not load .env file: %v\n", err)
+	var validator config.Validator
+	if err := godotenv.Load(); err != nil {
+		validator.Add("could not load .env file: %v", err)
 	}
-
+	validator.RequireEnv("JIRA_USERNAME", "JIRA_PASSWORD")
+	validator.RequireWritableFile(*dbPath)
 	if *gortnCnt > maxNoGoroutines {
-		logger.Fatalf("cannot have more than maximum number of goroutines... exiting now\n")
+		validator.Add("cannot have more than %d goroutines", maxNoGoroutines)
+	}
+	if *fieldMapping != "" {
+		if err := jira.LoadFieldMapping(*fieldMapping); err != nil {
+			validator.Add("%v", err)
+		}
+	}
+	statuses, err := parseStatuses(*retryStatuses)
+	if err != nil {
+		validator.Add("%v", err)
+	}
+	if *maxAttempts < 1 {
+		validator.Add("max attempts must be at least 1")
+	}
+	if err := validator.Err(); err != nil {
+		logger.Fatalln(err)
 	}
 
 	clientURL, err := url.Parse(*jiraURL)
@@ -71,15 +119,27 @@ func main() {
 		logger.Fatalf("jira URL provided is not a valid URL: %v\n", err)
 	}
 
-	jiraClient, err := jira.NewClient(clientURL)
+	jiraClient, err := jira.NewClient(clientURL, jira.WithRetryPolicy(jira.RetryPolicy{
+		MaxAttempts:   *maxAttempts,
+		BaseDelay:     *retryBaseDelay,
+		MaxDelay:      *retryMaxDelay,
+		RetryStatuses: statuses,
+	}))
 	if err != nil {
 		logger.Fatalf("could not create Jira client: %v\n", err)
 	}
 
-	boltDB, err := db.NewBolt(*dbPath)
+	boltDB, err := db.NewShardedBolt(*dbPath, *shards)
 	if err != nil {
 		logger.Fatalf("could not create Bolt DB: %v\n", err)
 	}
+	moved, err := boltDB.MoveLegacyTickets()
+	if err != nil {
+		logger.Fatalf("could not move tickets into shards: %v\n", err)
+	}
+	if moved > 0 {
+		logger.Printf("moved %d tickets into shards\n", moved)
+	}
 
 	err = jiraClient.AuthenticateClient()
 	if err != nil {
@@ -103,7 +163,7 @@ func main() {
 			if err != nil {
 				logger.Printf("error while getting issues: %v\n", err)
 			}
-			err = boltDB.Insert(issues...)
+			err = boltDB.Upsert(issues...)
 			if err != nil {
 				logger.Printf("could not add issues to bolt: %v\n", err)
 			}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/db"
+	"github.com/nclandrei/ticketguru/jira"
+	"github.com/nclandrei/ticketguru/plot"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	// ansiBold, ansiRed, ansiGreen and ansiReset hold the escape sequences coloring the summary on terminals.
+	ansiBold  = "\033[1m"
+	ansiRed   = "\033[31m"
+	ansiGreen = "\033[32m"
+	ansiReset = "\033[0m"
+)
+
+var (
+	dbPath = flag.String(
+		"dbPath",
+		"/Users/nclandrei/Code/go/src/github.com/nclandrei/ticketguru/issues.db",
+		"path to Bolt database file",
+	)
+	count   = flag.Int("n", 10, "number of slowest tickets and of tickets showing each anomaly to list")
+	reopens = flag.Int("reopens", 3, "number of reopens from which a ticket counts as frequently reopened")
+	silence = flag.Float64("silence", analyze.LongSilenceHours, "number of hours without a new comment above "+
+		"which an open ticket counts as silent")
+	priorityWeights = flag.String("priorityWeights", "1=4,2=2", "comma separated priority ID or name to weight "+
+		"pairs used by the priority-weighted quality; priorities not listed weigh 1")
+	color = flag.Bool("color", true, "color the summary with ANSI escape sequences when stdout is a terminal")
+)
+
+func main() {
+	flag.Parse()
+	weights, err := analyze.ParsePriorityWeights(*priorityWeights)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	boltDB, err := db.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("could not open bolt db: %v\n", err)
+	}
+	tickets, err := boltDB.Tickets()
+	if err != nil {
+		log.Fatalf("could not get tickets from bolt db: %v\n", err)
+	}
+
+	s := summary{
+		w:       os.Stdout,
+		colors:  *color && isTerminal(os.Stdout),
+		n:       *count,
+		reopens: *reopens,
+		silence: *silence,
+	}
+	if err := s.write(weights, tickets...); err != nil {
+		log.Fatalf("could not write summary: %v\n", err)
+	}
+}
+
+// isTerminal returns whether file is a character device, i.e. a terminal rather than a pipe or a regular file.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// summary writes a text summary of the tickets to w, listing at most n tickets per section and coloring it with
+// ANSI escape sequences if colors is true. Tickets reopened at least reopens times and open tickets silent for
+// more than silence hours count as anomalies.
+type summary struct {
+	w       io.Writer
+	colors  bool
+	n       int
+	reopens int
+	silence float64
+}
+
+// paint wraps text within the given escape sequence if colors are enabled.
+func (s summary) paint(escape, text string) string {
+	if !s.colors {
+		return text
+	}
+	return escape + text + ansiReset
+}
+
+// write writes the headline metrics, slowest tickets, coverage fractions and anomalies of the tickets, weighing
+// their quality with weights. Colored values are only ever found in the last column so that escape sequences do
+// not throw off the alignment.
+func (s summary) write(weights analyze.PriorityWeights, tickets ...jira.JiraIssue) error {
+	w := tabwriter.NewWriter(s.w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, s.paint(ansiBold, "Headline metrics"))
+	for _, m := range plot.HeadlineMetrics(weights, tickets...) {
+		fmt.Fprintf(w, "  %s:\t%s\n", m.Name, m.Value)
+	}
+
+	fmt.Fprintf(w, "\n%s\n", s.paint(ansiBold, "Slowest tickets"))
+	slowest, err := analyze.Rank("time_to_close", s.n, false, tickets...)
+	if err != nil {
+		return err
+	}
+	if len(slowest) == 0 {
+		fmt.Fprintf(w, "  none closed\n")
+	}
+	for _, t := range slowest {
+		fmt.Fprintf(w, "  %s\t%.2fh\n", t.Key, t.Value)
+	}
+
+	agg := analyze.Aggregate(tickets...)
+	fmt.Fprintf(w, "\n%s\n", s.paint(ansiBold, "Coverage of closed high priority tickets"))
+	for _, c := range []struct {
+		name     string
+		fraction float64
+	}{
+		{"Attachments", agg.AttachmentsCoverage},
+		{"Steps to reproduce", agg.StepsToReproduceCoverage},
+		{"Stack traces", agg.StackTracesCoverage},
+		{"Sentiment scored", agg.SentimentCoverage},
+		{"Grammar scored", agg.GrammarCoverage},
+	} {
+		fmt.Fprintf(w, "  %s:\t%.1f%%\n", c.name, c.fraction*100)
+	}
+
+	fmt.Fprintf(w, "\n%s\n", s.paint(ansiBold, "Anomalies"))
+	anomalies := analyze.TimestampAnomalies(tickets...)
+	anomalyKeys := make([]string, 0, len(anomalies))
+	for key := range anomalies {
+		anomalyKeys = append(anomalyKeys, key)
+	}
+	sort.Strings(anomalyKeys)
+	var mismatchKeys []string
+	for _, m := range analyze.PriorityResolutionMismatches(tickets...) {
+		mismatchKeys = append(mismatchKeys, m.Key)
+	}
+	var silentKeys []string
+	for _, t := range analyze.SilentOpenTickets(s.silence, tickets...) {
+		silentKeys = append(silentKeys, t.Key)
+	}
+	for _, a := range []struct {
+		name string
+		keys []string
+	}{
+		{"Untrustworthy timestamps", anomalyKeys},
+		{"Priority and resolution time mismatches", mismatchKeys},
+		{"Frequently reopened", analyze.FrequentlyReopened(s.reopens, tickets...)},
+		{"Silent open tickets", silentKeys},
+	} {
+		s.anomaly(w, a.name, a.keys)
+	}
+	return w.Flush()
+}
+
+// anomaly writes the number of tickets showing an anomaly, red if there are any, followed by at most n of
+// their keys.
+func (s summary) anomaly(w io.Writer, name string, keys []string) {
+	if len(keys) == 0 {
+		fmt.Fprintf(w, "  %s:\t%s\n", name, s.paint(ansiGreen, "0"))
+		return
+	}
+	listed := keys
+	if s.n > 0 && len(listed) > s.n {
+		listed = append(listed[:s.n:s.n], "...")
+	}
+	fmt.Fprintf(w, "  %s:\t%s (%s)\n", name, s.paint(ansiRed, strconv.Itoa(len(keys))), strings.Join(listed, ", "))
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestSummaryWrite(t *testing.T) {
+	ticket := func(key string, created, resolved time.Time, ttc float64) jira.JiraIssue {
+		ticket := jira.JiraIssue{Key: key, TimeToClose: ttc}
+		ticket.Fields.Priority.ID = "1"
+		ticket.Fields.Created = jira.Time(created)
+		ticket.Fields.Status.Name = "Open"
+		if !resolved.IsZero() {
+			ticket.Fields.Status.Name = "Closed"
+			ticket.Changelog.Histories = []jira.ChangelogHistory{{
+				Created: jira.Time(resolved),
+				Items:   []jira.ChangelogHistoryItem{{Field: "status", FromString: "Open", ToString: "Closed"}},
+			}}
+		}
+		return ticket
+	}
+	day := func(day, hour int) time.Time {
+		return time.Date(2018, 3, day, hour, 0, 0, 0, time.UTC)
+	}
+	fast := ticket("S-1", day(1, 0), day(1, 10), 10)
+	fast.Fields.Attachments = []jira.Attachment{{Filename: "crash.png"}}
+	tickets := []jira.JiraIssue{
+		fast,
+		ticket("S-2", day(2, 0), day(3, 6), 30),
+		ticket("S-3", day(3, 0), time.Time{}, 0),
+		// resolved before being created.
+		ticket("S-4", day(5, 0), day(4, 0), 0),
+	}
+
+	var buf bytes.Buffer
+	s := summary{w: &buf, n: 10, reopens: 3, silence: analyze.LongSilenceHours}
+	if err := s.write(analyze.PriorityWeights{}, tickets...); err != nil {
+		t.Fatalf("could not write summary: %v", err)
+	}
+	// lines are compared regardless of the padding aligning their columns.
+	var lines []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		lines = append(lines, strings.Join(strings.Fields(line), " "))
+	}
+	want := []string{
+		"Tickets: 4",
+		"Closed tickets: 2",
+		"Mean time-to-close: 20.0h",
+		"S-2 30.00h",
+		"S-1 10.00h",
+		"Attachments: 50.0%",
+		"Steps to reproduce: 0.0%",
+		"Untrustworthy timestamps: 1 (S-4)",
+		"Frequently reopened: 0",
+	}
+	next := 0
+	for _, line := range lines {
+		if next < len(want) && line == want[next] {
+			next++
+		}
+	}
+	if next < len(want) {
+		t.Errorf("summary is missing %q, in order after the previous lines:\n%s", want[next], buf.String())
+	}
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("summary without colors contains escape sequences:\n%q", buf.String())
+	}
+
+	buf.Reset()
+	s.colors = true
+	if err := s.write(analyze.PriorityWeights{}, tickets...); err != nil {
+		t.Fatalf("could not write colored summary: %v", err)
+	}
+	for _, colored := range []string{ansiBold + "Headline metrics" + ansiReset, ansiRed + "1" + ansiReset + " (S-4)",
+		ansiGreen + "0" + ansiReset} {
+		if !strings.Contains(buf.String(), colored) {
+			t.Errorf("colored summary is missing %q:\n%q", colored, buf.String())
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/db"
+	"github.com/nclandrei/ticketguru/jira"
+	"log"
+	"os"
+)
+
+var (
+	dbPath = flag.String(
+		"dbPath",
+		"/Users/nclandrei/Code/go/src/github.com/nclandrei/ticketguru/issues.db",
+		"path to Bolt database file",
+	)
+	metric = flag.String("metric", "time_to_close", "metric to sort tickets by - available metrics: time_to_close, "+
+		"comments, comment_words, reassignments, quality, quality_urgency, late_attachments, sentiment, "+
+		"grammar_errors, lead_time, reopens, post_resolution_comments, max_comment_gap")
+	count        = flag.Int("n", 20, "number of tickets to list; 0 lists all of them")
+	ascending    = flag.Bool("ascending", false, "list the tickets with the lowest values instead of the highest")
+	highPriority = flag.Bool("highPriority", true, "only list high priority tickets")
+)
+
+func main() {
+	flag.Parse()
+
+	boltDB, err := db.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("could not open bolt db: %v\n", err)
+	}
+	tickets, err := boltDB.Tickets()
+	if err != nil {
+		log.Fatalf("could not get tickets from bolt db: %v\n", err)
+	}
+
+	if *highPriority {
+		var filtered []jira.JiraIssue
+		for _, t := range tickets {
+			if jira.IsHighPriority(t) {
+				filtered = append(filtered, t)
+			}
+		}
+		tickets = filtered
+	}
+
+	ranked, err := analyze.Rank(*metric, *count, *ascending, tickets...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(1)
+	}
+	for _, t := range ranked {
+		fmt.Printf("%s\t%.2f\n", t.Key, t.Value)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/api"
+	"github.com/nclandrei/ticketguru/db"
+	"github.com/nclandrei/ticketguru/jira"
+	"log"
+	"net/http"
+	"os"
+)
+
+var (
+	dbPath = flag.String(
+		"dbPath",
+		"/Users/nclandrei/Code/go/src/github.com/nclandrei/ticketguru/issues.db",
+		"path to Bolt database file",
+	)
+	addr      = flag.String("addr", ":8080", "address the webhook server listens on")
+	path      = flag.String("path", "/webhook", "path Jira webhooks are posted to")
+	secret    = flag.String("secret", os.Getenv("JIRA_WEBHOOK_SECRET"), "secret webhook payloads are signed with")
+	reanalyze = flag.Bool("reanalyze", true, "re-run the local analyses on every upserted ticket")
+	cacheSize = flag.Int("cacheSize", 0, "number of decoded tickets kept in memory between reads; 0 disables the "+
+		"cache")
+)
+
+func main() {
+	flag.Parse()
+
+	boltDB, err := db.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("could not open bolt db: %v\n", err)
+	}
+	boltDB.CacheTickets(*cacheSize)
+
+	var analyses []analyze.TicketAnalysis
+	if *reanalyze {
+		analyses = []analyze.TicketAnalysis{
+			// times-to-close are computed without printing counts on every request.
+			func(tickets ...jira.JiraIssue) { analyze.ResolveTimesToClose(analyze.ResolutionOptions{}, tickets...) },
+			analyze.WordCounts,
+			analyze.StepsToReproduce,
+			analyze.StackTraces,
+		}
+	}
+
+	http.Handle(*path, api.WebhookHandler(boltDB, *secret, analyses...))
+	log.Printf("listening for Jira webhooks on %s%s\n", *addr, *path)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Validator collects configuration problems so that all of them can be reported at once before any work starts.
+type Validator struct {
+	problems []string
+}
+
+// Add records a configuration problem.
+func (v *Validator) Add(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+// RequireEnv records a problem for every environment variable which is either missing or empty.
+func (v *Validator) RequireEnv(keys ...string) {
+	for _, key := range keys {
+		if strings.TrimSpace(os.Getenv(key)) == "" {
+			v.Add("required key %s is missing or empty", key)
+		}
+	}
+}
+
+// RequireWritableFile records a problem if the file at path cannot be written to or, if it does not exist yet,
+// created inside its parent directory.
+func (v *Validator) RequireWritableFile(path string) {
+	info, err := os.Stat(path)
+	switch {
+	case err == nil && info.IsDir():
+		v.Add("%s is a directory, not a file", path)
+	case err == nil:
+		file, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			v.Add("%s is not writable: %v", path, err)
+			return
+		}
+		file.Close()
+	case os.IsNotExist(err):
+		file, err := ioutil.TempFile(filepath.Dir(path), ".ticketguru")
+		if err != nil {
+			v.Add("%s cannot be created: %v", path, err)
+			return
+		}
+		file.Close()
+		os.Remove(file.Name())
+	default:
+		v.Add("could not access %s: %v", path, err)
+	}
+}
+
+// RequireDir records a problem if there is no directory at path.
+func (v *Validator) RequireDir(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		v.Add("directory %s is not accessible: %v", path, err)
+		return
+	}
+	if !info.IsDir() {
+		v.Add("%s is not a directory", path)
+	}
+}
+
+// Err returns an error listing all the recorded problems or nil if there are none.
+func (v *Validator) Err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid configuration:\n\t%s", strings.Join(v.problems, "\n\t"))
+}
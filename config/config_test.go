@@ -0,0 +1,67 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidatorWithoutProblems(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("TICKETGURU_TEST_KEY", "value")
+	defer os.Unsetenv("TICKETGURU_TEST_KEY")
+
+	var v Validator
+	v.RequireEnv("TICKETGURU_TEST_KEY")
+	v.RequireDir(dir)
+	v.RequireWritableFile(filepath.Join(dir, "issues.db"))
+	if err := v.Err(); err != nil {
+		t.Errorf("valid configuration reported %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "issues.db")); !os.IsNotExist(err) {
+		t.Errorf("checking a missing file created it")
+	}
+}
+
+func TestValidatorReportsAllProblems(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("TICKETGURU_TEST_BLANK", "  ")
+	defer os.Unsetenv("TICKETGURU_TEST_BLANK")
+
+	var v Validator
+	v.RequireEnv("TICKETGURU_TEST_MISSING", "TICKETGURU_TEST_BLANK")
+	v.RequireDir(file)
+	v.RequireDir(filepath.Join(dir, "missing"))
+	v.RequireWritableFile(dir)
+	v.RequireWritableFile(filepath.Join(dir, "missing", "issues.db"))
+	err = v.Err()
+	if err == nil {
+		t.Fatal("invalid configuration reported no problems")
+	}
+	for _, want := range []string{
+		"TICKETGURU_TEST_MISSING is missing or empty",
+		"TICKETGURU_TEST_BLANK is missing or empty",
+		file + " is not a directory",
+		"directory " + filepath.Join(dir, "missing") + " is not accessible",
+		dir + " is a directory, not a file",
+		filepath.Join(dir, "missing", "issues.db") + " cannot be created",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("reported problems %q miss %q", err, want)
+		}
+	}
+}
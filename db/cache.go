@@ -0,0 +1,84 @@
+package db
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// ticketCache holds up to size decoded tickets, evicting the least recently used one when full. A ticketCache
+// is safe for concurrent use.
+type ticketCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+	// generation is incremented whenever tickets are removed, so that tickets read from the database before
+	// their removal are not cached afterwards.
+	generation uint64
+}
+
+// newTicketCache returns an empty cache holding at most size tickets.
+func newTicketCache(size int) *ticketCache {
+	return &ticketCache{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// get returns the cached ticket with the given key, marking it as the most recently used, whether it was found
+// and the cache's current generation.
+func (c *ticketCache) get(key string) (jira.JiraIssue, bool, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return jira.JiraIssue{}, false, c.generation
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(jira.JiraIssue), true, c.generation
+}
+
+// add caches a ticket, replacing any cached version of it, and evicts the least recently used tickets beyond the
+// cache's size.
+func (c *ticketCache) add(ticket jira.JiraIssue) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.put(ticket)
+}
+
+// addUnchanged caches a ticket like add, unless tickets were removed since the given generation was returned by
+// get, in which case the ticket may be stale and is left out.
+func (c *ticketCache) addUnchanged(ticket jira.JiraIssue, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.put(ticket)
+	}
+}
+
+// put caches a ticket; c.mu must be held.
+func (c *ticketCache) put(ticket jira.JiraIssue) {
+	if e, ok := c.entries[ticket.Key]; ok {
+		e.Value = ticket
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[ticket.Key] = c.order.PushFront(ticket)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(jira.JiraIssue).Key)
+	}
+}
+
+// remove drops the cached tickets with the given keys; keys of tickets which are not cached are ignored.
+func (c *ticketCache) remove(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	for _, key := range keys {
+		if e, ok := c.entries[key]; ok {
+			c.order.Remove(e)
+			delete(c.entries, key)
+		}
+	}
+}
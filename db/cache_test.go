@@ -0,0 +1,92 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestTicketCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newTicketCache(2)
+	for _, ticket := range closedTickets(10, 20) {
+		cache.add(ticket)
+	}
+	cache.get("B-0")
+	cache.add(jira.JiraIssue{Key: "B-2"})
+	for key, want := range map[string]bool{"B-0": true, "B-1": false, "B-2": true} {
+		if _, ok, _ := cache.get(key); ok != want {
+			t.Errorf("%s cached = %v, want %v", key, ok, want)
+		}
+	}
+	cache.remove("B-0", "B-9")
+	if _, ok, _ := cache.get("B-0"); ok {
+		t.Error("removed ticket is still cached")
+	}
+}
+
+func TestTicketCacheSkipsTicketsReadBeforeRemoval(t *testing.T) {
+	cache := newTicketCache(2)
+	_, _, generation := cache.get("B-0")
+	// a concurrent write of B-0 uncaches it while the stale version is being read.
+	cache.remove("B-0")
+	cache.addUnchanged(jira.JiraIssue{Key: "B-0"}, generation)
+	if _, ok, _ := cache.get("B-0"); ok {
+		t.Error("ticket read before its removal was cached")
+	}
+	_, _, generation = cache.get("B-0")
+	cache.addUnchanged(jira.JiraIssue{Key: "B-0"}, generation)
+	if _, ok, _ := cache.get("B-0"); !ok {
+		t.Error("ticket read without concurrent removals was not cached")
+	}
+}
+
+func TestShardedCachedTicketByKey(t *testing.T) {
+	path, remove := tempPath(t)
+	defer remove()
+	db, err := NewShardedBolt(path, 2)
+	if err != nil {
+		t.Fatalf("could not open sharded bolt db: %v", err)
+	}
+	defer db.Close()
+	db.CacheTickets(4)
+	if err := db.Insert(closedTickets(10, 20)...); err != nil {
+		t.Fatalf("could not insert tickets: %v", err)
+	}
+	// overwriting the stored ticket behind the cache's back shows whether a read decodes it again.
+	overwrite := func(ttc float64) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			return db.put(tx, jira.JiraIssue{Key: "B-0", TimeToClose: ttc})
+		})
+		if err != nil {
+			t.Fatalf("could not overwrite ticket: %v", err)
+		}
+	}
+	timeToClose := func() float64 {
+		ticket, err := db.TicketByKey("B-0")
+		if err != nil || ticket == nil {
+			t.Fatalf("TicketByKey(B-0) = (%v, %v)", ticket, err)
+		}
+		return ticket.TimeToClose
+	}
+
+	if ttc := timeToClose(); ttc != 10 {
+		t.Fatalf("time to close of B-0 = %v, want 10", ttc)
+	}
+	overwrite(99)
+	if ttc := timeToClose(); ttc != 10 {
+		t.Errorf("time to close of B-0 after a cache hit = %v, want the cached 10", ttc)
+	}
+	if err := db.Insert(jira.JiraIssue{Key: "B-0", TimeToClose: 30}); err != nil {
+		t.Fatalf("could not insert ticket: %v", err)
+	}
+	if ttc := timeToClose(); ttc != 30 {
+		t.Errorf("time to close of B-0 after inserting it again = %v, want 30", ttc)
+	}
+	if err := db.Delete("B-0"); err != nil {
+		t.Fatalf("could not delete ticket: %v", err)
+	}
+	if ticket, err := db.TicketByKey("B-0"); err != nil || ticket != nil {
+		t.Errorf("TicketByKey of a deleted ticket = (%v, %v), want nothing", ticket, err)
+	}
+}
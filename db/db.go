@@ -3,6 +3,7 @@ package db
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/nclandrei/ticketguru/analyze"
 	"github.com/nclandrei/ticketguru/jira"
 	"time"
 
@@ -12,6 +13,12 @@ import (
 // Name of the bucket where we'll be inserting our users.
 const (
 	bucketName = "users"
+	// chartsBucketName holds the name of the bucket where rendered charts are cached.
+	chartsBucketName = "charts"
+	// boundsBucketName holds the name of the bucket where outlier bounds computed from a baseline are stored.
+	boundsBucketName = "bounds"
+	// migrationsBucketName holds the name of the bucket where the versions of applied migrations are tracked.
+	migrationsBucketName = "migrations"
 )
 
 // TicketStorage defines a generic interface for different DBs to implement.
@@ -38,6 +45,18 @@ func NewBolt(path string) (*Bolt, error) {
 	}
 	err = db.Update(func(tx *bolt.Tx) error {
 		_, txErr := tx.CreateBucketIfNotExists([]byte(bucketName))
+		if txErr != nil {
+			return txErr
+		}
+		_, txErr = tx.CreateBucketIfNotExists([]byte(chartsBucketName))
+		if txErr != nil {
+			return txErr
+		}
+		_, txErr = tx.CreateBucketIfNotExists([]byte(boundsBucketName))
+		if txErr != nil {
+			return txErr
+		}
+		_, txErr = tx.CreateBucketIfNotExists([]byte(migrationsBucketName))
 		return txErr
 	})
 	if err != nil {
@@ -48,9 +67,10 @@ func NewBolt(path string) (*Bolt, error) {
 	}, err
 }
 
-// Insert takes a slice of tickets and inserts them into Bolt.
+// Insert takes a slice of tickets and inserts them into Bolt, synthesizing the keys of keyless tickets.
 func (db *Bolt) Insert(tickets ...jira.JiraIssue) error {
 	for _, ticket := range tickets {
+		jira.EnsureKey(&ticket)
 		tx, err := db.Begin(true)
 		if err != nil {
 			return fmt.Errorf("could not create transaction: %v", err)
@@ -71,6 +91,37 @@ func (db *Bolt) Insert(tickets ...jira.JiraIssue) error {
 	return nil
 }
 
+// Upsert inserts the given tickets into Bolt, merging each of them into its already stored version, if any,
+// so that new changelog histories are appended and locally computed scores are preserved. Keyless tickets are
+// stored under a synthesized key.
+func (db *Bolt) Upsert(tickets ...jira.JiraIssue) error {
+	for _, ticket := range tickets {
+		jira.EnsureKey(&ticket)
+		err := db.Update(func(tx *bolt.Tx) error {
+			b := tx.Bucket([]byte(bucketName))
+			if stored := b.Get([]byte(ticket.Key)); stored != nil {
+				var existing jira.JiraIssue
+				if err := json.Unmarshal(stored, &existing); err != nil {
+					return fmt.Errorf("could not unmarshal stored ticket %s: %v", ticket.Key, err)
+				}
+				ticket = jira.Merge(existing, ticket)
+			}
+			buf, err := json.Marshal(&ticket)
+			if err != nil {
+				return fmt.Errorf("could not marshal ticket %s: %v", ticket.Key, err)
+			}
+			if err := b.Put([]byte(ticket.Key), buf); err != nil {
+				return fmt.Errorf("could not insert ticket %s: %v", ticket.Key, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // TicketByKey returns a single ticket searched for by key.
 func (db *Bolt) TicketByKey(key string) (*jira.JiraIssue, error) {
 	tx, err := db.Begin(false)
@@ -172,10 +223,106 @@ func (db *Bolt) Cursor() (*bolt.Cursor, func() error, error) {
 
 // Size returns the total number of key/value pairs inside the tickets bucket.
 func (db *Bolt) Size() (int, error) {
-	tx, err := db.Begin(false)
+	return db.Count()
+}
+
+// Count returns the number of stored tickets from the tickets bucket statistics, without loading any of them.
+func (db *Bolt) Count() (int, error) {
+	var count int
+	err := db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucketName))
+		if b == nil {
+			return fmt.Errorf("could not retrieve users bucket from bolt")
+		}
+		count = b.Stats().KeyN
+		return nil
+	})
+	return count, err
+}
+
+// Delete removes the tickets with the given keys; keys of tickets which are not stored are ignored.
+func (db *Bolt) Delete(keys ...string) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucketName))
+		if b == nil {
+			return fmt.Errorf("could not retrieve users bucket from bolt")
+		}
+		for _, key := range keys {
+			if err := b.Delete([]byte(key)); err != nil {
+				return fmt.Errorf("could not delete ticket %s: %v", key, err)
+			}
+		}
+		return nil
+	})
+}
+
+// PutChart stores a rendered PNG chart under the given name, replacing any previous chart with the same name.
+func (db *Bolt) PutChart(name string, png []byte) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(chartsBucketName))
+		if b == nil {
+			return fmt.Errorf("could not retrieve charts bucket from bolt")
+		}
+		if err := b.Put([]byte(name), png); err != nil {
+			return fmt.Errorf("could not insert chart %s: %v", name, err)
+		}
+		return nil
+	})
+}
+
+// GetChart returns the rendered PNG chart stored under the given name and whether it was found.
+func (db *Bolt) GetChart(name string) ([]byte, bool, error) {
+	var png []byte
+	err := db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(chartsBucketName))
+		if b == nil {
+			return fmt.Errorf("could not retrieve charts bucket from bolt")
+		}
+		if v := b.Get([]byte(name)); v != nil {
+			// Values returned by bolt are only valid during the transaction, so copy them out.
+			png = append([]byte(nil), v...)
+		}
+		return nil
+	})
 	if err != nil {
-		return -1, err
+		return nil, false, err
 	}
-	defer tx.Rollback()
-	return tx.Bucket([]byte(bucketName)).Stats().KeyN, nil
+	return png, png != nil, nil
+}
+
+// SaveBounds stores outlier bounds under the given name so that later runs can reuse them.
+func (db *Bolt) SaveBounds(name string, bounds analyze.OutlierBounds) error {
+	buf, err := json.Marshal(bounds)
+	if err != nil {
+		return fmt.Errorf("could not marshal bounds %s: %v", name, err)
+	}
+	return db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(boundsBucketName))
+		if b == nil {
+			return fmt.Errorf("could not retrieve bounds bucket from bolt")
+		}
+		if err := b.Put([]byte(name), buf); err != nil {
+			return fmt.Errorf("could not insert bounds %s: %v", name, err)
+		}
+		return nil
+	})
+}
+
+// LoadBounds returns the outlier bounds stored under the given name and whether they were found.
+func (db *Bolt) LoadBounds(name string) (analyze.OutlierBounds, bool, error) {
+	var bounds analyze.OutlierBounds
+	var found bool
+	err := db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(boundsBucketName))
+		if b == nil {
+			return fmt.Errorf("could not retrieve bounds bucket from bolt")
+		}
+		v := b.Get([]byte(name))
+		if v == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(v, &bounds)
+	})
+	return bounds, found, err
 }
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// tempPath returns the path of a database file inside a new temporary directory and a function removing it.
+func tempPath(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "issues.db"), func() { os.RemoveAll(dir) }
+}
+
+// newTempBolt opens a legacy Bolt database inside a temporary directory and returns a function closing and
+// removing it.
+func newTempBolt(t *testing.T) (*Bolt, func()) {
+	path, remove := tempPath(t)
+	db, err := NewBolt(path)
+	if err != nil {
+		remove()
+		t.Fatalf("could not open bolt db: %v", err)
+	}
+	return db, func() {
+		db.Close()
+		remove()
+	}
+}
+
+func TestPutGetChart(t *testing.T) {
+	db, cleanup := newTempBolt(t)
+	defer cleanup()
+
+	if _, ok, err := db.GetChart("attachments.png"); err != nil || ok {
+		t.Fatalf("GetChart of a missing chart = (%v, %v), want not found", ok, err)
+	}
+	png := []byte("\x89PNG first")
+	if err := db.PutChart("attachments.png", png); err != nil {
+		t.Fatalf("could not put chart: %v", err)
+	}
+	if err := db.PutChart("attachments.png", []byte("\x89PNG second")); err != nil {
+		t.Fatalf("could not replace chart: %v", err)
+	}
+	got, ok, err := db.GetChart("attachments.png")
+	if err != nil || !ok {
+		t.Fatalf("GetChart = (%v, %v), want found", ok, err)
+	}
+	if !bytes.Equal(got, []byte("\x89PNG second")) {
+		t.Errorf("GetChart = %q, want the replacing chart", got)
+	}
+}
+
+func TestCount(t *testing.T) {
+	db, cleanup := newTempBolt(t)
+	defer cleanup()
+
+	const n = 25
+	times := make([]float64, n)
+	if err := db.Insert(closedTickets(times...)...); err != nil {
+		t.Fatalf("could not insert tickets: %v", err)
+	}
+	if count, err := db.Count(); err != nil || count != n {
+		t.Fatalf("Count = (%d, %v), want %d", count, err, n)
+	}
+	if err := db.Delete("B-0", "B-1", "B-missing"); err != nil {
+		t.Fatalf("could not delete tickets: %v", err)
+	}
+	if count, err := db.Count(); err != nil || count != n-2 {
+		t.Errorf("Count after deleting 2 tickets = (%d, %v), want %d", count, err, n-2)
+	}
+}
+
+// closedTickets returns one closed ticket per time-to-close, keyed by its index.
+func closedTickets(times ...float64) []jira.JiraIssue {
+	tickets := make([]jira.JiraIssue, len(times))
+	for i, ttc := range times {
+		tickets[i] = jira.JiraIssue{Key: fmt.Sprintf("B-%d", i), TimeToClose: ttc}
+	}
+	return tickets
+}
+
+func TestSavedBoundsAppliedOnLaterRun(t *testing.T) {
+	path, remove := tempPath(t)
+	defer remove()
+
+	baselineDB, err := NewBolt(path)
+	if err != nil {
+		t.Fatalf("could not open bolt db: %v", err)
+	}
+	if _, ok, err := baselineDB.LoadBounds("time_to_close"); err != nil || ok {
+		t.Fatalf("LoadBounds before saving = (%v, %v), want not found", ok, err)
+	}
+	baseline := analyze.TimeToCloseBounds(closedTickets(10, 20, 30, 40, 50)...)
+	if err := baselineDB.SaveBounds("time_to_close", baseline); err != nil {
+		t.Fatalf("could not save bounds: %v", err)
+	}
+	baselineDB.Close()
+
+	laterDB, err := NewBolt(path)
+	if err != nil {
+		t.Fatalf("could not reopen bolt db: %v", err)
+	}
+	defer laterDB.Close()
+	bounds, ok, err := laterDB.LoadBounds("time_to_close")
+	if err != nil || !ok {
+		t.Fatalf("LoadBounds = (%v, %v), want found", ok, err)
+	}
+	if bounds != baseline {
+		t.Errorf("loaded bounds %+v, want the saved %+v", bounds, baseline)
+	}
+	// the later run's own bounds would keep 80 and drop 10, unlike the baseline ones.
+	var kept []float64
+	for _, ticket := range analyze.ExcludeTimeToCloseOutliers(bounds, closedTickets(10, 60, 65, 80)...) {
+		kept = append(kept, ticket.TimeToClose)
+	}
+	if fmt.Sprint(kept) != "[10 60 65]" {
+		t.Errorf("tickets kept with the saved bounds = %v, want [10 60 65]", kept)
+	}
+}
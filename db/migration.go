@@ -0,0 +1,83 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// Migration defines a versioned change to the stored tickets (e.g. backfilling a new analysis field).
+type Migration interface {
+	Version() int
+	Apply(TicketStorage) error
+}
+
+// MigrationStorage defines a ticket storage which also tracks the versions of the migrations applied to it.
+type MigrationStorage interface {
+	TicketStorage
+	AppliedMigrations() (map[int]bool, error)
+	MarkMigrationApplied(int) error
+}
+
+// Migrate applies, in ascending version order, the migrations which have not been applied to the storage yet
+// and returns the versions it applied. It stops at the first failing migration.
+func Migrate(storage MigrationStorage, migrations ...Migration) ([]int, error) {
+	applied, err := storage.AppliedMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve applied migrations: %v", err)
+	}
+	pending := make([]Migration, 0, len(migrations))
+	for _, m := range migrations {
+		if !applied[m.Version()] {
+			pending = append(pending, m)
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].Version() < pending[j].Version()
+	})
+	var versions []int
+	for _, m := range pending {
+		if err := m.Apply(storage); err != nil {
+			return versions, fmt.Errorf("could not apply migration %d: %v", m.Version(), err)
+		}
+		if err := storage.MarkMigrationApplied(m.Version()); err != nil {
+			return versions, fmt.Errorf("could not mark migration %d as applied: %v", m.Version(), err)
+		}
+		versions = append(versions, m.Version())
+	}
+	return versions, nil
+}
+
+// AppliedMigrations returns the versions of the migrations applied to the database.
+func (db *Bolt) AppliedMigrations() (map[int]bool, error) {
+	applied := make(map[int]bool)
+	err := db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(migrationsBucketName))
+		if b == nil {
+			return fmt.Errorf("could not retrieve migrations bucket from bolt")
+		}
+		return b.ForEach(func(k, v []byte) error {
+			version, err := strconv.Atoi(string(k))
+			if err != nil {
+				return fmt.Errorf("invalid migration version %q: %v", k, err)
+			}
+			applied[version] = true
+			return nil
+		})
+	})
+	return applied, err
+}
+
+// MarkMigrationApplied records that the migration with the given version has been applied to the database.
+func (db *Bolt) MarkMigrationApplied(version int) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(migrationsBucketName))
+		if b == nil {
+			return fmt.Errorf("could not retrieve migrations bucket from bolt")
+		}
+		return b.Put([]byte(strconv.Itoa(version)), []byte(time.Now().Format(time.RFC3339)))
+	})
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+// recordingMigration appends its version to the shared log every time it is applied.
+type recordingMigration struct {
+	version int
+	log     *[]int
+}
+
+func (m recordingMigration) Version() int {
+	return m.version
+}
+
+func (m recordingMigration) Apply(TicketStorage) error {
+	*m.log = append(*m.log, m.version)
+	return nil
+}
+
+func TestMigrateAppliesPendingMigrationsOnceInOrder(t *testing.T) {
+	db, cleanup := newTempBolt(t)
+	defer cleanup()
+
+	var log []int
+	migrations := []Migration{recordingMigration{2, &log}, recordingMigration{1, &log}}
+	versions, err := Migrate(db, migrations...)
+	if err != nil {
+		t.Fatalf("could not migrate: %v", err)
+	}
+	if !reflect.DeepEqual(versions, []int{1, 2}) || !reflect.DeepEqual(log, []int{1, 2}) {
+		t.Errorf("applied versions %v, log %v; want both [1 2]", versions, log)
+	}
+
+	versions, err = Migrate(db, migrations...)
+	if err != nil {
+		t.Fatalf("could not migrate again: %v", err)
+	}
+	if len(versions) != 0 || len(log) != 2 {
+		t.Errorf("second run applied %v, log %v; want the migrations skipped", versions, log)
+	}
+}
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/boltdb/bolt"
+)
+
+const (
+	// responsesBucketName holds the name of the bucket where raw scorer responses are kept for auditing.
+	responsesBucketName = "responses"
+	// responsesOrderBucketName holds the name of the bucket indexing the raw responses by insertion order.
+	responsesOrderBucketName = "responses_order"
+)
+
+// ResponseStore keeps the raw responses scorers got from external APIs, indexed by ticket and scorer, evicting
+// the oldest responses once more than maxEntries of them are stored.
+type ResponseStore struct {
+	db         *Bolt
+	maxEntries int
+}
+
+// Responses returns a store keeping at most maxEntries raw responses inside db; a non-positive maxEntries keeps
+// all of them.
+func (db *Bolt) Responses(maxEntries int) *ResponseStore {
+	return &ResponseStore{db: db, maxEntries: maxEntries}
+}
+
+// responseKey returns the key of the raw response of a scorer for a ticket.
+func responseKey(ticketKey, scorer string) []byte {
+	return []byte(ticketKey + "/" + scorer)
+}
+
+// PutResponse stores the raw response of a scorer for a ticket, replacing any previous one, and evicts the
+// oldest responses beyond the store's capacity.
+func (s *ResponseStore) PutResponse(ticketKey, scorer string, raw []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		responses, err := tx.CreateBucketIfNotExists([]byte(responsesBucketName))
+		if err != nil {
+			return err
+		}
+		order, err := tx.CreateBucketIfNotExists([]byte(responsesOrderBucketName))
+		if err != nil {
+			return err
+		}
+		key := responseKey(ticketKey, scorer)
+		if previous := responses.Get(key); previous != nil {
+			if err := order.Delete(previous[:8]); err != nil {
+				return err
+			}
+		}
+		seq, err := order.NextSequence()
+		if err != nil {
+			return err
+		}
+		id := make([]byte, 8)
+		binary.BigEndian.PutUint64(id, seq)
+		if err := responses.Put(key, append(id, raw...)); err != nil {
+			return fmt.Errorf("could not insert response of %s for ticket %s: %v", scorer, ticketKey, err)
+		}
+		if err := order.Put(id, key); err != nil {
+			return err
+		}
+		if s.maxEntries <= 0 {
+			return nil
+		}
+		var ids [][]byte
+		err = order.ForEach(func(k, v []byte) error {
+			ids = append(ids, append([]byte(nil), k...))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(ids)-s.maxEntries; i++ {
+			if err := responses.Delete(order.Get(ids[i])); err != nil {
+				return err
+			}
+			if err := order.Delete(ids[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Response returns the raw response of a scorer for a ticket and whether it was found.
+func (s *ResponseStore) Response(ticketKey, scorer string) ([]byte, bool, error) {
+	var raw []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(responsesBucketName))
+		if b == nil {
+			return nil
+		}
+		if v := b.Get(responseKey(ticketKey, scorer)); v != nil {
+			// Values returned by bolt are only valid during the transaction, so copy them out.
+			raw = append([]byte(nil), v[8:]...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return raw, raw != nil, nil
+}
@@ -0,0 +1,49 @@
+package db
+
+import "testing"
+
+func TestResponseStore(t *testing.T) {
+	db, remove := newTempBolt(t)
+	defer remove()
+	store := db.Responses(2)
+	found := func(ticketKey, scorer string) string {
+		t.Helper()
+		raw, ok, err := store.Response(ticketKey, scorer)
+		if err != nil {
+			t.Fatalf("could not read response of %s for %s: %v", scorer, ticketKey, err)
+		}
+		if !ok {
+			return ""
+		}
+		return string(raw)
+	}
+	put := func(ticketKey, scorer, raw string) {
+		t.Helper()
+		if err := store.PutResponse(ticketKey, scorer, []byte(raw)); err != nil {
+			t.Fatalf("could not store response of %s for %s: %v", scorer, ticketKey, err)
+		}
+	}
+
+	if got := found("T-1", "grammar"); got != "" {
+		t.Errorf("response found inside an empty store: %s", got)
+	}
+	put("T-1", "grammar", `{"flaggedTokens": []}`)
+	put("T-1", "sentiment", `{"score": 0.1}`)
+	if got := found("T-1", "grammar"); got != `{"flaggedTokens": []}` {
+		t.Errorf("grammar response of T-1 = %s", got)
+	}
+	put("T-2", "grammar", `{"flaggedTokens": [{"token": "teh"}]}`)
+	if got := found("T-1", "grammar"); got != "" {
+		t.Errorf("oldest response was not evicted beyond the cap: %s", got)
+	}
+
+	// replacing a response makes it the newest one, so the next eviction spares it.
+	put("T-1", "sentiment", `{"score": 0.3}`)
+	put("T-3", "grammar", `{"flaggedTokens": []}`)
+	if got := found("T-1", "sentiment"); got != `{"score": 0.3}` {
+		t.Errorf("sentiment response of T-1 = %s, want the replacement", got)
+	}
+	if got := found("T-2", "grammar"); got != "" {
+		t.Errorf("response of T-2 was not evicted: %s", got)
+	}
+}
@@ -0,0 +1,398 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+const (
+	// shardsBucketName holds the name of the bucket where the number of ticket shards is recorded.
+	shardsBucketName = "shards"
+	// shardCountKey holds the key, inside the shards bucket, of the number of ticket shards.
+	shardCountKey = "count"
+)
+
+// ErrShardedCursor is returned when a single cursor is requested over tickets spread across several buckets.
+var ErrShardedCursor = errors.New("cursors are not supported over sharded tickets")
+
+// ShardedBolt defines a Bolt database spreading the tickets across several buckets by key hash so that they
+// can be read concurrently. Tickets still inside the single bucket used by Bolt are read alongside the sharded
+// ones, so databases written before sharding keep working until MoveLegacyTickets is called.
+type ShardedBolt struct {
+	*Bolt
+	shards int
+
+	cacheMu sync.RWMutex
+	cache   *ticketCache
+}
+
+// NewShardedBolt returns a Bolt database spreading tickets across the given number of buckets. A database
+// already sharded with a different number of buckets is rejected; a non-positive number of shards reuses the
+// recorded one, if any, or keeps all tickets inside the single tickets bucket otherwise.
+func NewShardedBolt(path string, shards int) (*ShardedBolt, error) {
+	b, err := NewBolt(path)
+	if err != nil {
+		return nil, err
+	}
+	err = b.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists([]byte(shardsBucketName))
+		if err != nil {
+			return err
+		}
+		recorded := 0
+		if v := meta.Get([]byte(shardCountKey)); v != nil {
+			if recorded, err = strconv.Atoi(string(v)); err != nil {
+				return fmt.Errorf("invalid number of shards %q: %v", v, err)
+			}
+		}
+		switch {
+		case shards <= 0:
+			shards = recorded
+		case recorded > 0 && recorded != shards:
+			return fmt.Errorf("database is sharded across %d buckets, not %d", recorded, shards)
+		}
+		if shards <= 0 {
+			return nil
+		}
+		for i := 0; i < shards; i++ {
+			if _, err := tx.CreateBucketIfNotExists(shardBucketName(i)); err != nil {
+				return err
+			}
+		}
+		return meta.Put([]byte(shardCountKey), []byte(strconv.Itoa(shards)))
+	})
+	if err != nil {
+		b.Close()
+		return nil, err
+	}
+	return &ShardedBolt{Bolt: b, shards: shards}, nil
+}
+
+// Open returns the database found at path, sharded as recorded inside it.
+func Open(path string) (*ShardedBolt, error) {
+	return NewShardedBolt(path, 0)
+}
+
+// CacheTickets keeps up to size of the tickets returned by TicketByKey decoded in memory, so that reading them
+// again skips unmarshalling them, until they are written or deleted through this database. A non-positive size
+// disables the cache. Cached tickets are shared between callers, which must not modify them. It is safe to call
+// while the database is in use.
+func (db *ShardedBolt) CacheTickets(size int) {
+	var cache *ticketCache
+	if size > 0 {
+		cache = newTicketCache(size)
+	}
+	db.cacheMu.Lock()
+	defer db.cacheMu.Unlock()
+	db.cache = cache
+}
+
+// currentCache returns the cache enabled through CacheTickets, if any.
+func (db *ShardedBolt) currentCache() *ticketCache {
+	db.cacheMu.RLock()
+	defer db.cacheMu.RUnlock()
+	return db.cache
+}
+
+// uncache drops the tickets with the given keys from the cache, if any.
+func (db *ShardedBolt) uncache(keys ...string) {
+	if cache := db.currentCache(); cache != nil {
+		cache.remove(keys...)
+	}
+}
+
+// shardBucketName returns the name of the bucket holding the i-th shard of tickets.
+func shardBucketName(i int) []byte {
+	return []byte(fmt.Sprintf("%s_%d", bucketName, i))
+}
+
+// bucketFor returns the name of the bucket a ticket with the given key is stored in.
+func (db *ShardedBolt) bucketFor(key string) []byte {
+	if db.shards <= 0 {
+		return []byte(bucketName)
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return shardBucketName(int(h.Sum32() % uint32(db.shards)))
+}
+
+// buckets returns the names of all buckets tickets are read from, the single tickets bucket included.
+func (db *ShardedBolt) buckets() [][]byte {
+	names := [][]byte{[]byte(bucketName)}
+	for i := 0; i < db.shards; i++ {
+		names = append(names, shardBucketName(i))
+	}
+	return names
+}
+
+// put stores a ticket inside its shard and removes any copy left inside the single tickets bucket.
+func (db *ShardedBolt) put(tx *bolt.Tx, ticket jira.JiraIssue) error {
+	buf, err := json.Marshal(&ticket)
+	if err != nil {
+		return fmt.Errorf("could not marshal ticket %s: %v", ticket.Key, err)
+	}
+	bucket := db.bucketFor(ticket.Key)
+	if err := tx.Bucket(bucket).Put([]byte(ticket.Key), buf); err != nil {
+		return fmt.Errorf("could not insert ticket %s: %v", ticket.Key, err)
+	}
+	if string(bucket) != bucketName {
+		return tx.Bucket([]byte(bucketName)).Delete([]byte(ticket.Key))
+	}
+	return nil
+}
+
+// get returns the stored encoding of a ticket, looking inside its shard first and inside the single tickets
+// bucket otherwise, or nil if the ticket is not stored.
+func (db *ShardedBolt) get(tx *bolt.Tx, key string) []byte {
+	if v := tx.Bucket(db.bucketFor(key)).Get([]byte(key)); v != nil {
+		return v
+	}
+	return tx.Bucket([]byte(bucketName)).Get([]byte(key))
+}
+
+// Insert takes a slice of tickets and inserts them into their shards, synthesizing the keys of keyless tickets.
+func (db *ShardedBolt) Insert(tickets ...jira.JiraIssue) error {
+	var keys []string
+	defer func() { db.uncache(keys...) }()
+	return db.Update(func(tx *bolt.Tx) error {
+		for _, ticket := range tickets {
+			jira.EnsureKey(&ticket)
+			keys = append(keys, ticket.Key)
+			if err := db.put(tx, ticket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Upsert inserts the given tickets into their shards, merging each of them into its already stored version.
+// Keyless tickets are stored under a synthesized key.
+func (db *ShardedBolt) Upsert(tickets ...jira.JiraIssue) error {
+	return db.UpsertAnalyzed(nil, tickets...)
+}
+
+// UpsertAnalyzed upserts the given tickets like Upsert, running the analyses on each merged ticket before storing
+// it. Reading, analyzing and storing a ticket happen within the same transaction, so that concurrent upserts of
+// the same ticket do not lose each other's updates.
+func (db *ShardedBolt) UpsertAnalyzed(analyses []analyze.TicketAnalysis, tickets ...jira.JiraIssue) error {
+	var keys []string
+	defer func() { db.uncache(keys...) }()
+	return db.Update(func(tx *bolt.Tx) error {
+		for _, ticket := range tickets {
+			jira.EnsureKey(&ticket)
+			keys = append(keys, ticket.Key)
+			if stored := db.get(tx, ticket.Key); stored != nil {
+				var existing jira.JiraIssue
+				if err := json.Unmarshal(stored, &existing); err != nil {
+					return fmt.Errorf("could not unmarshal stored ticket %s: %v", ticket.Key, err)
+				}
+				ticket = jira.Merge(existing, ticket)
+			}
+			analyzed := []jira.JiraIssue{ticket}
+			for _, analysis := range analyses {
+				analysis(analyzed...)
+			}
+			ticket = analyzed[0]
+			if err := db.put(tx, ticket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// TicketByKey returns a single ticket searched for by key, or nil if it is not stored. Tickets are read from the
+// cache, if enabled through CacheTickets, and cached once decoded.
+func (db *ShardedBolt) TicketByKey(key string) (*jira.JiraIssue, error) {
+	cache := db.currentCache()
+	var generation uint64
+	if cache != nil {
+		cached, ok, gen := cache.get(key)
+		if ok {
+			return &cached, nil
+		}
+		generation = gen
+	}
+	var ticket *jira.JiraIssue
+	err := db.View(func(tx *bolt.Tx) error {
+		v := db.get(tx, key)
+		if v == nil {
+			return nil
+		}
+		return json.Unmarshal(v, &ticket)
+	})
+	if err == nil && ticket != nil && cache != nil {
+		// a ticket written or deleted since the cache was read may have been decoded before the write.
+		cache.addUnchanged(*ticket, generation)
+	}
+	return ticket, err
+}
+
+// Tickets retrieves all the tickets, reading every bucket concurrently, sorted by key.
+func (db *ShardedBolt) Tickets() ([]jira.JiraIssue, error) {
+	buckets := db.buckets()
+	results := make([][]jira.JiraIssue, len(buckets))
+	errs := make([]error, len(buckets))
+	var wg sync.WaitGroup
+	for i, name := range buckets {
+		wg.Add(1)
+		go func(i int, name []byte) {
+			defer wg.Done()
+			errs[i] = db.View(func(tx *bolt.Tx) error {
+				return tx.Bucket(name).ForEach(func(k, v []byte) error {
+					var ticket jira.JiraIssue
+					if err := json.Unmarshal(v, &ticket); err != nil {
+						return err
+					}
+					results[i] = append(results[i], ticket)
+					return nil
+				})
+			})
+		}(i, name)
+	}
+	wg.Wait()
+	var tickets []jira.JiraIssue
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("could not read bucket %s: %v", buckets[i], err)
+		}
+		tickets = append(tickets, results[i]...)
+	}
+	sort.Slice(tickets, func(i, j int) bool {
+		return tickets[i].Key < tickets[j].Key
+	})
+	return tickets, nil
+}
+
+// TicketsExcludingProjects retrieves all the tickets, sorted by key, except those belonging to the projects matched
+// by the given key prefixes, alongside the number of excluded tickets.
+func (db *ShardedBolt) TicketsExcludingProjects(prefixes []string) ([]jira.JiraIssue, int, error) {
+	tickets, err := db.Tickets()
+	if err != nil {
+		return nil, 0, err
+	}
+	tickets, excluded := analyze.ExcludeProjects(prefixes, tickets...)
+	return tickets, excluded, nil
+}
+
+// TicketsAnalyzedBefore retrieves, sorted by key, the tickets the named scorer or analysis last computed the
+// fields of before cutoff or never did, so that stale analyses can be run again.
+func (db *ShardedBolt) TicketsAnalyzedBefore(analysis string, cutoff time.Time) ([]jira.JiraIssue, error) {
+	tickets, err := db.Tickets()
+	if err != nil {
+		return nil, err
+	}
+	var stale []jira.JiraIssue
+	for i := range tickets {
+		if tickets[i].AnalyzedBefore(analysis, cutoff) {
+			stale = append(stale, tickets[i])
+		}
+	}
+	return stale, nil
+}
+
+// Slice returns a ticket slice, in key order, given a low and high bound.
+func (db *ShardedBolt) Slice(l, h int) ([]jira.JiraIssue, error) {
+	if l >= h {
+		return nil, fmt.Errorf("low bound is greater than high bound")
+	}
+	if l < 0 || h < 0 {
+		return nil, fmt.Errorf("bounds are negative")
+	}
+	tickets, err := db.Tickets()
+	if err != nil {
+		return nil, err
+	}
+	if l > len(tickets) || h > len(tickets) {
+		return nil, fmt.Errorf("bounds greater than bucket size")
+	}
+	return tickets[l:h], nil
+}
+
+// Cursor is not supported as tickets are spread across several buckets.
+func (db *ShardedBolt) Cursor() (*bolt.Cursor, func() error, error) {
+	if db.shards <= 0 {
+		return db.Bolt.Cursor()
+	}
+	return nil, nil, ErrShardedCursor
+}
+
+// Size returns the total number of stored tickets across all buckets.
+func (db *ShardedBolt) Size() (int, error) {
+	return db.Count()
+}
+
+// Count returns the number of stored tickets from the statistics of all buckets, without loading any of them.
+func (db *ShardedBolt) Count() (int, error) {
+	var count int
+	err := db.View(func(tx *bolt.Tx) error {
+		for _, name := range db.buckets() {
+			count += tx.Bucket(name).Stats().KeyN
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Delete removes the tickets with the given keys from every bucket; keys of tickets which are not stored are
+// ignored.
+func (db *ShardedBolt) Delete(keys ...string) error {
+	defer db.uncache(keys...)
+	return db.Update(func(tx *bolt.Tx) error {
+		for _, key := range keys {
+			if err := tx.Bucket(db.bucketFor(key)).Delete([]byte(key)); err != nil {
+				return fmt.Errorf("could not delete ticket %s: %v", key, err)
+			}
+			if err := tx.Bucket([]byte(bucketName)).Delete([]byte(key)); err != nil {
+				return fmt.Errorf("could not delete ticket %s: %v", key, err)
+			}
+		}
+		return nil
+	})
+}
+
+// MoveLegacyTickets moves the tickets stored inside the single tickets bucket into their shards and returns
+// the number of moved tickets.
+func (db *ShardedBolt) MoveLegacyTickets() (int, error) {
+	if db.shards <= 0 {
+		return 0, nil
+	}
+	var moved int
+	err := db.Update(func(tx *bolt.Tx) error {
+		legacy := tx.Bucket([]byte(bucketName))
+		var keys [][]byte
+		err := legacy.ForEach(func(k, v []byte) error {
+			// a sharded copy is always at least as recent as the one left inside the single tickets bucket.
+			shard := tx.Bucket(db.bucketFor(string(k)))
+			if shard.Get(k) == nil {
+				if err := shard.Put(k, v); err != nil {
+					return err
+				}
+			}
+			keys = append(keys, append([]byte(nil), k...))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if err := legacy.Delete(k); err != nil {
+				return err
+			}
+		}
+		moved = len(keys)
+		return nil
+	})
+	return moved, err
+}
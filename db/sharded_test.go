@@ -0,0 +1,198 @@
+package db
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// keys returns the keys of tickets read from db, in the order they were read.
+func keys(t *testing.T, db *ShardedBolt) []string {
+	t.Helper()
+	tickets, err := db.Tickets()
+	if err != nil {
+		t.Fatalf("could not read tickets: %v", err)
+	}
+	result := make([]string, len(tickets))
+	for i, ticket := range tickets {
+		result[i] = ticket.Key
+	}
+	return result
+}
+
+func TestShardedRoundTrip(t *testing.T) {
+	path, remove := tempPath(t)
+	defer remove()
+	db, err := NewShardedBolt(path, 4)
+	if err != nil {
+		t.Fatalf("could not open sharded bolt db: %v", err)
+	}
+
+	tickets := closedTickets(make([]float64, 20)...)
+	if err := db.Insert(tickets...); err != nil {
+		t.Fatalf("could not insert tickets: %v", err)
+	}
+	got := keys(t, db)
+	if len(got) != len(tickets) || !sort.StringsAreSorted(got) {
+		t.Errorf("Tickets = %v, want all %d tickets sorted by key", got, len(tickets))
+	}
+	if ticket, err := db.TicketByKey("B-7"); err != nil || ticket == nil || ticket.Key != "B-7" {
+		t.Errorf("TicketByKey(B-7) = (%v, %v)", ticket, err)
+	}
+	var used int
+	db.View(func(tx *bolt.Tx) error {
+		for i := 0; i < 4; i++ {
+			if tx.Bucket(shardBucketName(i)).Stats().KeyN > 0 {
+				used++
+			}
+		}
+		return nil
+	})
+	if used < 2 {
+		t.Errorf("tickets were written to %d shards, want them spread across several", used)
+	}
+	db.Close()
+
+	if _, err := NewShardedBolt(path, 3); err == nil {
+		t.Error("expected an error reopening the database with a different number of shards")
+	}
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("could not reopen sharded bolt db: %v", err)
+	}
+	defer reopened.Close()
+	if got := keys(t, reopened); len(got) != len(tickets) {
+		t.Errorf("reopened database holds %d tickets, want %d", len(got), len(tickets))
+	}
+}
+
+func TestShardedReadsLegacyBucket(t *testing.T) {
+	path, remove := tempPath(t)
+	defer remove()
+	legacy, err := NewBolt(path)
+	if err != nil {
+		t.Fatalf("could not open bolt db: %v", err)
+	}
+	if err := legacy.Insert(closedTickets(10, 20, 30)...); err != nil {
+		t.Fatalf("could not insert tickets: %v", err)
+	}
+	legacy.Close()
+
+	db, err := NewShardedBolt(path, 4)
+	if err != nil {
+		t.Fatalf("could not open sharded bolt db: %v", err)
+	}
+	defer db.Close()
+	want := []string{"B-0", "B-1", "B-2"}
+	if got := keys(t, db); !reflect.DeepEqual(got, want) {
+		t.Errorf("tickets read before moving them = %v, want %v", got, want)
+	}
+
+	moved, err := db.MoveLegacyTickets()
+	if err != nil || moved != 3 {
+		t.Fatalf("MoveLegacyTickets = (%d, %v), want 3 moved tickets", moved, err)
+	}
+	if got := keys(t, db); !reflect.DeepEqual(got, want) {
+		t.Errorf("tickets read after moving them = %v, want %v", got, want)
+	}
+	db.View(func(tx *bolt.Tx) error {
+		if n := tx.Bucket([]byte(bucketName)).Stats().KeyN; n != 0 {
+			t.Errorf("%d tickets were left inside the single tickets bucket", n)
+		}
+		return nil
+	})
+}
+
+func TestShardedTicketsExcludingProjects(t *testing.T) {
+	path, remove := tempPath(t)
+	defer remove()
+	db, err := NewShardedBolt(path, 2)
+	if err != nil {
+		t.Fatalf("could not open sharded bolt db: %v", err)
+	}
+	defer db.Close()
+	tickets := append(closedTickets(10, 20), jira.JiraIssue{Key: "TEST-1"}, jira.JiraIssue{Key: "TEST-2"})
+	if err := db.Insert(tickets...); err != nil {
+		t.Fatalf("could not insert tickets: %v", err)
+	}
+
+	kept, excluded, err := db.TicketsExcludingProjects([]string{"TEST-"})
+	if err != nil {
+		t.Fatalf("could not read tickets: %v", err)
+	}
+	if excluded != 2 || len(kept) != 2 || kept[0].Key != "B-0" || kept[1].Key != "B-1" {
+		t.Errorf("TicketsExcludingProjects = (%v, %d), want B-0, B-1 and 2 excluded tickets", kept, excluded)
+	}
+}
+
+func TestShardedTicketsAnalyzedBefore(t *testing.T) {
+	path, remove := tempPath(t)
+	defer remove()
+	db, err := NewShardedBolt(path, 2)
+	if err != nil {
+		t.Fatalf("could not open sharded bolt db: %v", err)
+	}
+	defer db.Close()
+	cutoff := time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)
+	tickets := closedTickets(10, 20, 30)
+	tickets[0].MarkAnalyzed("sentiment", cutoff.Add(-time.Hour))
+	tickets[1].MarkAnalyzed("sentiment", cutoff.Add(time.Hour))
+	if err := db.Insert(tickets...); err != nil {
+		t.Fatalf("could not insert tickets: %v", err)
+	}
+
+	stale, err := db.TicketsAnalyzedBefore("sentiment", cutoff)
+	if err != nil {
+		t.Fatalf("could not read tickets: %v", err)
+	}
+	// the timestamps survive being persisted, and a ticket never scored is stale too.
+	if len(stale) != 2 || stale[0].Key != "B-0" || stale[1].Key != "B-2" {
+		t.Errorf("TicketsAnalyzedBefore = %v, want B-0 and B-2", stale)
+	}
+}
+
+func TestShardedUpsertAnalyzedConcurrently(t *testing.T) {
+	path, remove := tempPath(t)
+	defer remove()
+	db, err := NewShardedBolt(path, 2)
+	if err != nil {
+		t.Fatalf("could not open sharded bolt db: %v", err)
+	}
+	defer db.Close()
+	// the analysis result depends on the whole merged ticket, so a stale read would be written back.
+	countHistories := func(tickets ...jira.JiraIssue) {
+		for i := range tickets {
+			tickets[i].TimeToClose = float64(len(tickets[i].Changelog.Histories))
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ticket := jira.JiraIssue{Key: "U-1"}
+			ticket.Changelog.Histories = []jira.ChangelogHistory{{ID: strconv.Itoa(i)}}
+			if err := db.UpsertAnalyzed([]analyze.TicketAnalysis{countHistories}, ticket); err != nil {
+				t.Errorf("could not upsert ticket: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	ticket, err := db.TicketByKey("U-1")
+	if err != nil || ticket == nil {
+		t.Fatalf("TicketByKey(U-1) = (%v, %v)", ticket, err)
+	}
+	if len(ticket.Changelog.Histories) != 20 || ticket.TimeToClose != 20 {
+		t.Errorf("stored %d histories analyzed as %v, want all 20 updates kept", len(ticket.Changelog.Histories),
+			ticket.TimeToClose)
+	}
+}
@@ -0,0 +1,189 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MinPartSize defines the smallest size, in bytes, of every part but the last one of a multipart upload accepted
+// by both S3 and the XML API of GCS.
+const MinPartSize = 5 << 20
+
+// Uploader uploads an object to a bucket in parts, as done by the multipart uploads of S3 and of the XML API of
+// GCS. Implementations usually wrap the client of the cloud provider's SDK.
+type Uploader interface {
+	// CreateMultipartUpload starts the upload of the object stored under key and returns its id.
+	CreateMultipartUpload(ctx context.Context, bucket, key string) (string, error)
+	// UploadPart uploads the part of the object with the given number, counted from 1.
+	UploadPart(ctx context.Context, bucket, key, uploadID string, number int, part []byte) error
+	// CompleteMultipartUpload assembles the given number of uploaded parts into the object.
+	CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts int) error
+	// AbortMultipartUpload discards the parts uploaded so far.
+	AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error
+}
+
+// Sink creates the writers exports are streamed to, each one identified by a key. The export is only complete
+// once its writer has been closed without error.
+type Sink interface {
+	Create(ctx context.Context, key string) (io.WriteCloser, error)
+}
+
+// FileSink writes every export to the file named by its key inside Dir.
+type FileSink struct {
+	Dir string
+}
+
+// Create creates, or truncates, the file of the export stored under key.
+func (s FileSink) Create(ctx context.Context, key string) (io.WriteCloser, error) {
+	return os.Create(filepath.Join(s.Dir, filepath.FromSlash(key)))
+}
+
+// ObjectSink streams every export to the object stored under Prefix followed by its key inside Bucket, uploading
+// it in parts of PartSize bytes so that large exports are never held in memory as a whole.
+type ObjectSink struct {
+	Uploader Uploader
+	Bucket   string
+	Prefix   string
+	// PartSize defines the size of the uploaded parts; MinPartSize is used if it is smaller.
+	PartSize int
+}
+
+// Create starts the multipart upload of the export stored under key.
+func (s ObjectSink) Create(ctx context.Context, key string) (io.WriteCloser, error) {
+	if s.Uploader == nil {
+		return nil, errors.New("no uploader configured for object sink")
+	}
+	key = s.Prefix + key
+	uploadID, err := s.Uploader.CreateMultipartUpload(ctx, s.Bucket, key)
+	if err != nil {
+		return nil, fmt.Errorf("could not start upload of %s to bucket %s: %v", key, s.Bucket, err)
+	}
+	partSize := s.PartSize
+	if partSize < MinPartSize {
+		partSize = MinPartSize
+	}
+	return &objectWriter{
+		ctx:      ctx,
+		uploader: s.Uploader,
+		bucket:   s.Bucket,
+		key:      key,
+		uploadID: uploadID,
+		partSize: partSize,
+	}, nil
+}
+
+// objectWriter buffers the bytes written to it and uploads them as a part whenever a full part is buffered.
+// After the first failed upload, the whole upload is aborted and every following call returns the error.
+type objectWriter struct {
+	ctx      context.Context
+	uploader Uploader
+	bucket   string
+	key      string
+	uploadID string
+	partSize int
+
+	buf    bytes.Buffer
+	parts  int
+	err    error
+	closed bool
+}
+
+// Write buffers p, uploading every full part.
+func (w *objectWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.closed {
+		return 0, errors.New("write to closed object writer")
+	}
+	w.buf.Write(p)
+	for w.buf.Len() >= w.partSize {
+		if err := w.upload(w.buf.Next(w.partSize)); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close uploads the remaining buffered bytes as the last part and completes the upload. An empty export is
+// uploaded as a single empty part.
+func (w *objectWriter) Close() error {
+	if w.err != nil || w.closed {
+		return w.err
+	}
+	w.closed = true
+	if w.buf.Len() > 0 || w.parts == 0 {
+		if err := w.upload(w.buf.Next(w.buf.Len())); err != nil {
+			return err
+		}
+	}
+	if err := w.uploader.CompleteMultipartUpload(w.ctx, w.bucket, w.key, w.uploadID, w.parts); err != nil {
+		return w.abort(fmt.Errorf("could not complete upload of %s to bucket %s: %v", w.key, w.bucket, err))
+	}
+	return nil
+}
+
+// upload uploads part as the next part of the object, aborting the upload if it fails.
+func (w *objectWriter) upload(part []byte) error {
+	w.parts++
+	err := w.uploader.UploadPart(w.ctx, w.bucket, w.key, w.uploadID, w.parts, part)
+	if err != nil {
+		return w.abort(fmt.Errorf("could not upload part %d of %s to bucket %s: %v", w.parts, w.key, w.bucket, err))
+	}
+	return nil
+}
+
+// abort discards the upload and records err as the error returned by every following call.
+func (w *objectWriter) abort(err error) error {
+	w.err = err
+	if abortErr := w.uploader.AbortMultipartUpload(w.ctx, w.bucket, w.key, w.uploadID); abortErr != nil {
+		w.err = fmt.Errorf("%v; could not abort upload: %v", err, abortErr)
+	}
+	return w.err
+}
+
+// Uploaders holds the uploaders the commands exporting results pass to Open, by URL scheme (s3 or gs). No
+// uploader is registered by this repository, so the commands only accept s3:// and gs:// destinations once a build
+// registers the uploaders of the cloud SDKs it links, e.g. from an init function.
+var Uploaders = map[string]Uploader{}
+
+// Open returns the writer of the export found at dest: - is stdout, s3://bucket/key and gs://bucket/key are
+// objects uploaded through the uploader registered for their scheme, while anything else is a file path.
+func Open(ctx context.Context, dest string, uploaders map[string]Uploader) (io.WriteCloser, error) {
+	if dest == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
+	if !strings.HasPrefix(dest, "s3://") && !strings.HasPrefix(dest, "gs://") {
+		return FileSink{}.Create(ctx, dest)
+	}
+	u, err := url.Parse(dest)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse export destination %s: %v", dest, err)
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || key == "" {
+		return nil, fmt.Errorf("export destination %s does not name both a bucket and a key", dest)
+	}
+	uploader, ok := uploaders[u.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("no uploader configured for %s:// destinations", u.Scheme)
+	}
+	return ObjectSink{Uploader: uploader, Bucket: u.Host}.Create(ctx, key)
+}
+
+// nopCloser wraps a writer which must not be closed by the export, such as stdout.
+type nopCloser struct {
+	io.Writer
+}
+
+// Close does nothing.
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,92 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+// fakeUploader keeps the parts of a single multipart upload in memory, failing the upload of part failPart.
+type fakeUploader struct {
+	bucket, key string
+	parts       [][]byte
+	completed   int
+	aborted     bool
+	failPart    int
+}
+
+func (u *fakeUploader) CreateMultipartUpload(ctx context.Context, bucket, key string) (string, error) {
+	u.bucket, u.key = bucket, key
+	return "upload-1", nil
+}
+
+func (u *fakeUploader) UploadPart(ctx context.Context, bucket, key, uploadID string, number int, part []byte) error {
+	if number == u.failPart {
+		return errors.New("connection reset")
+	}
+	if number != len(u.parts)+1 {
+		return errors.New("part uploaded out of order")
+	}
+	u.parts = append(u.parts, append([]byte(nil), part...))
+	return nil
+}
+
+func (u *fakeUploader) CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts int) error {
+	u.completed = parts
+	return nil
+}
+
+func (u *fakeUploader) AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error {
+	u.aborted = true
+	return nil
+}
+
+func TestObjectSinkStreamsParts(t *testing.T) {
+	uploader := &fakeUploader{}
+	w, err := Open(context.Background(), "s3://exports/runs/tickets.ndjson", map[string]Uploader{"s3": uploader})
+	if err != nil {
+		t.Fatalf("could not open export: %v", err)
+	}
+	// 12 MiB written in odd sized chunks are uploaded as two full parts and a last, smaller, one.
+	data := bytes.Repeat([]byte("0123456789abcdef"), 12<<20/16)
+	if _, err := io.CopyBuffer(w, bytes.NewReader(data), make([]byte, 999)); err != nil {
+		t.Fatalf("could not write export: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("could not close export: %v", err)
+	}
+
+	if uploader.bucket != "exports" || uploader.key != "runs/tickets.ndjson" {
+		t.Errorf("uploaded to bucket %q under key %q, want exports and runs/tickets.ndjson", uploader.bucket,
+			uploader.key)
+	}
+	if len(uploader.parts) != 3 || len(uploader.parts[0]) != MinPartSize || uploader.completed != 3 {
+		t.Errorf("uploaded %d parts and completed %d, want 3 parts of which the first is %d bytes",
+			len(uploader.parts), uploader.completed, MinPartSize)
+	}
+	if !bytes.Equal(bytes.Join(uploader.parts, nil), data) {
+		t.Error("uploaded bytes differ from the written ones")
+	}
+}
+
+func TestObjectSinkAbortsFailedUpload(t *testing.T) {
+	uploader := &fakeUploader{failPart: 2}
+	w, err := ObjectSink{Uploader: uploader, Bucket: "exports", Prefix: "runs/"}.Create(context.Background(), "a.csv")
+	if err != nil {
+		t.Fatalf("could not create export: %v", err)
+	}
+	if _, err := w.Write(make([]byte, 2*MinPartSize)); err == nil {
+		t.Fatal("expected an error writing a part that fails to upload")
+	}
+	if err := w.Close(); err == nil || !uploader.aborted || uploader.completed != 0 {
+		t.Errorf("Close returned %v, aborted = %v, want the upload aborted and not completed", err, uploader.aborted)
+	}
+
+	for _, dest := range []string{"s3://exports", "gs://exports/a.csv"} {
+		if _, err := Open(context.Background(), dest, map[string]Uploader{"s3": uploader}); err == nil {
+			t.Errorf("expected an error opening %s", dest)
+		}
+	}
+}
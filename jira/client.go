@@ -2,6 +2,7 @@ package jira
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -18,8 +19,10 @@ import (
 // Client defines the client for Jira
 type Client struct {
 	*http.Client
-	URL  *url.URL
-	lock sync.RWMutex
+	URL      *url.URL
+	lock     sync.RWMutex
+	statuses []Status
+	retry    RetryPolicy
 }
 
 // SearchResponse defines the response payload retrieved through the search endpoint
@@ -51,8 +54,9 @@ type Session struct {
 // ClientOption defines an optional function to be applied on a Jira client.
 type ClientOption func(*Client) (*Client, error)
 
-// NewClient returns a new Jira Client.
-func NewClient(url *url.URL) (*Client, error) {
+// NewClient returns a new Jira Client configured with the given options; requests are sent once unless a retry
+// policy is set.
+func NewClient(url *url.URL, opts ...ClientOption) (*Client, error) {
 	cookieJar, err := cookiejar.New(nil)
 	if err != nil {
 		return nil, err
@@ -66,14 +70,20 @@ func NewClient(url *url.URL) (*Client, error) {
 		TLSHandshakeTimeout: 60 * time.Second,
 	}
 
-	return &Client{
+	client := &Client{
 		Client: &http.Client{
 			Timeout:   time.Minute * 3,
 			Jar:       cookieJar,
 			Transport: transport,
 		},
 		URL: url,
-	}, nil
+	}
+	for _, opt := range opts {
+		if client, err = opt(client); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
 }
 
 // setSearchPath sets the URL path for JQL search on a Jira client.
@@ -81,10 +91,10 @@ func (client *Client) setSearchPath(projectName string, paginationIndex, pageCou
 	client.lock.Lock()
 	client.URL.Path = "/jira/rest/api/2/search"
 	queryValues := make(url.Values)
-	queryValues.Add("jql", fmt.Sprintf("project=%s", projectName))
+	queryValues.Add("jql", NewJQLBuilder().Equals("project", projectName).String())
 	queryValues.Add("startAt", strconv.Itoa(paginationIndex*pageCount))
 	queryValues.Add("maxResults", strconv.Itoa(pageCount))
-	queryValues.Add("fields", "summary, created, description, attachment, comment, key, issuetype, timespent, priority, timeestimate, status, duedate, progress")
+	queryValues.Add("fields", "summary, created, description, attachment, comment, key, issuetype, timespent, priority, timeestimate, status, duedate, progress, environment, reporter, assignee, resolution, fixVersions, versions, timetracking, "+SprintField)
 	queryValues.Add("expand", "changelog")
 	client.URL.RawQuery = queryValues.Encode()
 	client.lock.Unlock()
@@ -131,7 +141,7 @@ func (client *Client) Tickets(
 	pageCount int) ([]JiraIssue, error) {
 
 	client.setSearchPath(projectName, paginationIndex, pageCount)
-	resp, err := client.Get(client.URL.String())
+	resp, err := client.get(context.Background(), client.URL.String())
 
 	if err != nil {
 		return nil, err
@@ -151,7 +161,7 @@ func (client *Client) Tickets(
 func (client *Client) TicketsCount(project string) (int, error) {
 	client.URL.Path = "/jira/rest/api/2/search"
 	client.URL.RawQuery = "jql=project=" + project
-	resp, err := client.Get(client.URL.String())
+	resp, err := client.get(context.Background(), client.URL.String())
 	if err != nil {
 		return -1, err
 	}
@@ -166,3 +176,35 @@ func (client *Client) TicketsCount(project string) (int, error) {
 	}
 	return searchResponse.Total, nil
 }
+
+// FetchStatuses returns all the statuses defined by the workflows of the Jira instance alongside their
+// categories. Statuses are fetched once and cached for the lifetime of the client.
+func (client *Client) FetchStatuses(ctx context.Context) ([]Status, error) {
+	client.lock.RLock()
+	cached := client.statuses
+	client.lock.RUnlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	statusURL := *client.URL
+	statusURL.Path = "/jira/rest/api/2/status"
+	statusURL.RawQuery = ""
+	resp, err := client.get(ctx, statusURL.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d received when getting workflow statuses", resp.StatusCode)
+	}
+	var statuses []Status
+	if err := json.NewDecoder(resp.Body).Decode(&statuses); err != nil {
+		return nil, err
+	}
+
+	client.lock.Lock()
+	client.statuses = statuses
+	client.lock.Unlock()
+	return statuses, nil
+}
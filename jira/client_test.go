@@ -0,0 +1,47 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestFetchStatuses(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/jira/rest/api/2/status" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `[
+			{"id": "1", "name": "Open", "statusCategory": {"key": "new"}},
+			{"id": "2", "name": "Shipped", "statusCategory": {"key": "done"}}
+		]`)
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(serverURL)
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		statuses, err := client.FetchStatuses(context.Background())
+		if err != nil {
+			t.Fatalf("could not fetch statuses: %v", err)
+		}
+		if len(statuses) != 2 || statuses[1].Name != "Shipped" || statuses[1].StatusCategory.Key != DoneStatusCategory {
+			t.Errorf("FetchStatuses = %+v, want Open and the done Shipped", statuses)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("statuses were requested %d times, want them cached after the first request", requests)
+	}
+}
@@ -0,0 +1,72 @@
+package jira
+
+import (
+	"strings"
+)
+
+// JQLBuilder builds JQL queries out of clauses joined by AND, escaping all the user provided values.
+type JQLBuilder struct {
+	clauses []string
+	orderBy []string
+}
+
+// NewJQLBuilder returns an empty JQL builder.
+func NewJQLBuilder() *JQLBuilder {
+	return &JQLBuilder{}
+}
+
+// Equals adds a clause matching the tickets whose field equals value.
+func (b *JQLBuilder) Equals(field, value string) *JQLBuilder {
+	b.clauses = append(b.clauses, quoteField(field)+" = "+quoteValue(value))
+	return b
+}
+
+// In adds a clause matching the tickets whose field equals any of the values.
+func (b *JQLBuilder) In(field string, values ...string) *JQLBuilder {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = quoteValue(v)
+	}
+	b.clauses = append(b.clauses, quoteField(field)+" IN ("+strings.Join(quoted, ", ")+")")
+	return b
+}
+
+// RawClause adds a clause as is, e.g. created >= startOfWeek(). The clause is NOT escaped, so it must never
+// hold user provided values; use the structured clauses for those instead.
+func (b *JQLBuilder) RawClause(clause string) *JQLBuilder {
+	b.clauses = append(b.clauses, "("+clause+")")
+	return b
+}
+
+// OrderBy sorts the results by the given fields, each optionally followed by ASC or DESC, e.g. "created DESC".
+func (b *JQLBuilder) OrderBy(fields ...string) *JQLBuilder {
+	b.orderBy = append(b.orderBy, fields...)
+	return b
+}
+
+// String returns the JQL query.
+func (b *JQLBuilder) String() string {
+	query := strings.Join(b.clauses, " AND ")
+	if len(b.orderBy) > 0 {
+		query += " ORDER BY " + strings.Join(b.orderBy, ", ")
+	}
+	return strings.TrimSpace(query)
+}
+
+// quoteValue returns value as a double quoted JQL string, escaping backslashes and double quotes.
+func quoteValue(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, `"`, `\"`, -1)
+	return `"` + value + `"`
+}
+
+// quoteField returns field as is if it only holds letters, digits and underscores, or quoted otherwise
+// (e.g. custom fields named with whitespaces).
+func quoteField(field string) string {
+	for _, r := range field {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return quoteValue(field)
+		}
+	}
+	return field
+}
@@ -0,0 +1,26 @@
+package jira
+
+import "testing"
+
+func TestJQLBuilder(t *testing.T) {
+	for _, tc := range []struct {
+		builder *JQLBuilder
+		want    string
+	}{
+		{
+			NewJQLBuilder().In("labels", `say "hi"`, `back\slash`, "plain"),
+			`labels IN ("say \"hi\"", "back\\slash", "plain")`,
+		},
+		{
+			NewJQLBuilder().Equals("project", "OPS").RawClause("created >= startOfWeek()").
+				In("Story Points", "3").OrderBy("priority DESC", "created ASC"),
+			`project = "OPS" AND (created >= startOfWeek()) AND "Story Points" IN ("3") ` +
+				`ORDER BY priority DESC, created ASC`,
+		},
+		{NewJQLBuilder(), ""},
+	} {
+		if got := tc.builder.String(); got != tc.want {
+			t.Errorf("query = %s, want %s", got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy defines how requests to Jira failing because of the network or with a retryable status are
+// retried, waiting an exponentially growing delay between attempts.
+type RetryPolicy struct {
+	// MaxAttempts holds the maximum number of times a request is sent; values below 1 send it once.
+	MaxAttempts int
+	// BaseDelay holds the delay before the first retry, doubled before each further one.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts; 0 leaves it uncapped.
+	MaxDelay time.Duration
+	// RetryStatuses holds the HTTP status codes of the responses that are retried.
+	RetryStatuses []int
+}
+
+// DefaultRetryPolicy defines a policy suited to flaky networks, retrying rate limited requests and gateway
+// errors up to 3 times.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+	RetryStatuses: []int{
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+// WithRetryPolicy makes the client retry its requests according to policy instead of sending them once.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(client *Client) (*Client, error) {
+		if policy.BaseDelay < 0 || policy.MaxDelay < 0 {
+			return nil, fmt.Errorf("retry delays cannot be negative")
+		}
+		client.retry = policy
+		return client, nil
+	}
+}
+
+// retryable states whether a response with the given status code is retried by the policy.
+func (p RetryPolicy) retryable(status int) bool {
+	for _, s := range p.RetryStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns the time waited before the given retry, starting at 1.
+func (p RetryPolicy) delay(retry int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < retry && (p.MaxDelay == 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// get sends a GET request to rawURL, retrying it according to the client's retry policy until ctx is done.
+// The response of the last attempt is returned, whatever its status code.
+func (client *Client) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		request, err := http.NewRequest("GET", rawURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(request.WithContext(ctx))
+		last := attempt >= client.retry.MaxAttempts
+		if err == nil && (last || !client.retry.retryable(resp.StatusCode)) {
+			return resp, nil
+		}
+		if last {
+			return nil, fmt.Errorf("request failed after %d attempts: %v", attempt, err)
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(client.retry.delay(attempt)):
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package jira
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// the server is unavailable for the first two requests and then answers every one.
+		if requests <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		policy       RetryPolicy
+		wantRequests int
+		wantStatus   int
+	}{
+		{RetryPolicy{}, 1, http.StatusServiceUnavailable},
+		{RetryPolicy{MaxAttempts: 2, RetryStatuses: []int{http.StatusServiceUnavailable}}, 2,
+			http.StatusServiceUnavailable},
+		{RetryPolicy{MaxAttempts: 5, RetryStatuses: []int{http.StatusServiceUnavailable}}, 3, http.StatusOK},
+		{RetryPolicy{MaxAttempts: 5, RetryStatuses: []int{http.StatusTooManyRequests}}, 1,
+			http.StatusServiceUnavailable},
+	} {
+		requests = 0
+		client, err := NewClient(serverURL, WithRetryPolicy(tc.policy))
+		if err != nil {
+			t.Fatalf("could not create client: %v", err)
+		}
+		resp, err := client.get(context.Background(), server.URL)
+		if err != nil {
+			t.Errorf("%+v: could not send request: %v", tc.policy, err)
+			continue
+		}
+		resp.Body.Close()
+		if requests != tc.wantRequests || resp.StatusCode != tc.wantStatus {
+			t.Errorf("%+v: sent %d requests ending with status %d, want %d requests ending with %d", tc.policy,
+				requests, resp.StatusCode, tc.wantRequests, tc.wantStatus)
+		}
+	}
+
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	for retry, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second,
+		4: 5 * time.Second, 10: 5 * time.Second} {
+		if got := policy.delay(retry); got != want {
+			t.Errorf("delay before retry %d = %v, want %v", retry, got, want)
+		}
+	}
+	if _, err := NewClient(serverURL, WithRetryPolicy(RetryPolicy{BaseDelay: -time.Second})); err == nil {
+		t.Error("expected an error for a negative retry delay")
+	}
+}
@@ -0,0 +1,34 @@
+package ticketguru
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// ImportKeyPrefix prefixes the keys synthesized for tickets imported without a Jira key.
+const ImportKeyPrefix = "IMPORT-"
+
+// importKeyLength defines the number of hex characters of the hash kept inside a synthesized key.
+const importKeyLength = 12
+
+// SynthesizeKey returns a stable key for a ticket lacking a Jira key, derived from the hash of its summary and
+// creation time, so that importing the same ticket twice yields the same key and it can be deduped.
+func SynthesizeKey(ticket JiraIssue) string {
+	h := sha1.New()
+	h.Write([]byte(strings.TrimSpace(ticket.Fields.Summary)))
+	h.Write([]byte{0})
+	h.Write([]byte(time.Time(ticket.Fields.Created).UTC().Format(time.RFC3339Nano)))
+	sum := hex.EncodeToString(h.Sum(nil))
+	return ImportKeyPrefix + strings.ToUpper(sum[:importKeyLength])
+}
+
+// EnsureKey assigns a synthesized key to the ticket if it has none and returns whether it did so.
+func EnsureKey(ticket *JiraIssue) bool {
+	if ticket.Key != "" {
+		return false
+	}
+	ticket.Key = SynthesizeKey(*ticket)
+	return true
+}
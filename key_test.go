@@ -0,0 +1,43 @@
+package ticketguru
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSynthesizeKey(t *testing.T) {
+	imported := func(summary string, created time.Time) JiraIssue {
+		var ticket JiraIssue
+		ticket.Fields.Summary = summary
+		ticket.Fields.Created = Time(created)
+		return ticket
+	}
+	created := time.Date(2018, 3, 1, 10, 0, 0, 0, time.UTC)
+	key := SynthesizeKey(imported("login fails", created))
+	if !strings.HasPrefix(key, ImportKeyPrefix) || len(key) != len(ImportKeyPrefix)+importKeyLength {
+		t.Fatalf("SynthesizeKey = %q, want %s followed by %d hex characters", key, ImportKeyPrefix, importKeyLength)
+	}
+	// the same ticket, even created in another time zone, always gets the same key.
+	if again := SynthesizeKey(imported(" login fails ", created.In(time.FixedZone("CET", 3600)))); again != key {
+		t.Errorf("SynthesizeKey of the same ticket = %q, want %q", again, key)
+	}
+	distinct := []JiraIssue{
+		imported("login fails on Safari", created),
+		imported("login fails", created.Add(time.Second)),
+	}
+	for _, other := range distinct {
+		if got := SynthesizeKey(other); got == key {
+			t.Errorf("SynthesizeKey of a distinct ticket = %q, the same as %q", got, key)
+		}
+	}
+
+	keyless := imported("login fails", created)
+	if !EnsureKey(&keyless) || keyless.Key != key {
+		t.Errorf("EnsureKey assigned %q, want %q", keyless.Key, key)
+	}
+	keyed := JiraIssue{Key: "JIRA-1"}
+	if EnsureKey(&keyed) || keyed.Key != "JIRA-1" {
+		t.Errorf("EnsureKey replaced the Jira key with %q", keyed.Key)
+	}
+}
@@ -0,0 +1,42 @@
+package ticketguru
+
+import (
+	"regexp"
+	"strings"
+)
+
+// jiraSyntaxRegexes match, in order, Jira {noformat} and {quote} blocks, [~user] mentions, bare [http://...]
+// links, time logging and transition smart-commit commands.
+var jiraSyntaxRegexes = []*regexp.Regexp{
+	regexp.MustCompile(`(?s)\{noformat\}.*?\{noformat\}`),
+	regexp.MustCompile(`(?s)\{quote\}.*?\{quote\}`),
+	regexp.MustCompile(`\[~[^\]]*\]`),
+	regexp.MustCompile(`\[(https?|ftp|mailto):[^\]|]*\]`),
+	regexp.MustCompile(`(?i)(^|\s)#time(\s+\d+(\.\d+)?[wdhm])+`),
+	regexp.MustCompile(`(?i)(^|\s)#(comment|resolve|close|done|reopen|start-progress|stop-progress)\b`),
+}
+
+// wikiLinkRegex matches [text|url] Jira wiki links, capturing their text.
+var wikiLinkRegex = regexp.MustCompile(`\[([^\]|~]+)\|[^\]]*\]`)
+
+// blanksRegex matches runs of spaces and tabs.
+var blanksRegex = regexp.MustCompile(`[ \t]+`)
+
+// danglingPunctuationRegex matches the blanks left in front of punctuation by removed markup.
+var danglingPunctuationRegex = regexp.MustCompile(` +([,.;:!?])`)
+
+// StripJiraSyntax returns the prose of s without its Jira markup: {noformat} and {quote} blocks, [~user]
+// mentions, bare links and smart-commit commands are removed, [text|url] links are replaced by their text and
+// runs of blanks left behind are collapsed.
+func StripJiraSyntax(s string) string {
+	s = wikiLinkRegex.ReplaceAllString(s, "$1")
+	for _, regex := range jiraSyntaxRegexes {
+		s = regex.ReplaceAllString(s, " ")
+	}
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		line = blanksRegex.ReplaceAllString(line, " ")
+		lines[i] = strings.TrimSpace(danglingPunctuationRegex.ReplaceAllString(line, "$1"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
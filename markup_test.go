@@ -0,0 +1,21 @@
+package ticketguru
+
+import "testing"
+
+func TestStripJiraSyntax(t *testing.T) {
+	for _, tc := range []struct {
+		text, want string
+	}{
+		{"Thanks [~jdoe], see [the docs|https://example.com/docs] for details.", "Thanks, see the docs for details."},
+		{"Crashes on save:\n{noformat}\nNullPointerException\n\tat Foo.bar\n{noformat}\nStill failing.",
+			"Crashes on save:\n\nStill failing."},
+		{"{quote}it used to work{quote} Not anymore.", "Not anymore."},
+		{"See [http://example.com/build/42] now", "See now"},
+		{"ABC-12 #time 1w 2d #comment fixed the typo", "ABC-12 fixed the typo"},
+		{"Nothing to strip here.", "Nothing to strip here."},
+	} {
+		if got := StripJiraSyntax(tc.text); got != tc.want {
+			t.Errorf("StripJiraSyntax(%q) = %q, want %q", tc.text, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package ticketguru
+
+import "time"
+
+// timeToCloseAnalysis names the analysis computing the time to close of tickets in their analysis timestamps.
+const timeToCloseAnalysis = "time_to_close"
+
+// Merge returns the freshly fetched version of a ticket merged into its stored version: all fields are taken
+// from fetched, the changelog histories of fetched not already stored (by history ID) are appended to the
+// stored ones and the locally computed scores and counts are preserved unless the summary, description or
+// comments of the ticket changed, in which case their analysis timestamps are dropped too. If new histories were
+// appended, the resolution of the ticket may have changed: the stored time to close is kept until recomputed, but
+// its analysis timestamp is dropped so that the next run on stale tickets recomputes it.
+func Merge(stored, fetched JiraIssue) JiraIssue {
+	merged := stored
+	merged.Expand = fetched.Expand
+	merged.ID = fetched.ID
+	merged.Self = fetched.Self
+	merged.Fields = fetched.Fields
+
+	histories := make([]ChangelogHistory, len(stored.Changelog.Histories))
+	copy(histories, stored.Changelog.Histories)
+	seen := make(map[string]bool, len(histories))
+	for _, h := range histories {
+		seen[historyKey(h)] = true
+	}
+	appended := false
+	for _, h := range fetched.Changelog.Histories {
+		if seen[historyKey(h)] {
+			continue
+		}
+		seen[historyKey(h)] = true
+		histories = append(histories, h)
+		appended = true
+	}
+	merged.Changelog = fetched.Changelog
+	merged.Changelog.Histories = histories
+	merged.Changelog.Total = len(histories)
+	if appended && merged.AnalyzedAt != nil {
+		analyzedAt := make(map[string]time.Time, len(merged.AnalyzedAt))
+		for name, at := range merged.AnalyzedAt {
+			if name != timeToCloseAnalysis {
+				analyzedAt[name] = at
+			}
+		}
+		merged.AnalyzedAt = analyzedAt
+	}
+
+	if textChanged(stored, fetched) {
+		merged.Sentiment = Sentiment{}
+		merged.GrammarCorrectness = GrammarCorrectness{}
+		merged.HasStackTrace = false
+		merged.HasStepsToReproduce = false
+		merged.SummaryDescWordsCount = 0
+		merged.SummaryWordsCount = 0
+		merged.DescriptionWordsCount = 0
+		merged.CommentWordsCount = 0
+		merged.WeightedCommentWordsCount = 0
+		merged.CodelessSummaryDescWordsCount = 0
+		merged.CodelessCommentWordsCount = 0
+		merged.HasWordCounts = false
+		merged.CustomFlags = nil
+		merged.IsSpam = false
+		merged.AnalyzedAt = nil
+	}
+	return merged
+}
+
+// historyKey identifies a changelog history by its ID or, for histories without one, by their creation time
+// and author.
+func historyKey(h ChangelogHistory) string {
+	if h.ID != "" {
+		return h.ID
+	}
+	return time.Time(h.Created).Format(time.RFC3339Nano) + "|" + h.Author.Name
+}
+
+// textChanged returns whether the summary, description or comments differ between two versions of a ticket.
+func textChanged(a, b JiraIssue) bool {
+	if a.Fields.Summary != b.Fields.Summary || a.Fields.Description != b.Fields.Description {
+		return true
+	}
+	ac, bc := a.Fields.Comments.Comments, b.Fields.Comments.Comments
+	if len(ac) != len(bc) {
+		return true
+	}
+	for i := range ac {
+		if ac[i].Body != bc[i].Body {
+			return true
+		}
+	}
+	return false
+}
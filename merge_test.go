@@ -0,0 +1,66 @@
+package ticketguru
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// historyIDs returns the IDs of the changelog histories of a ticket in order.
+func historyIDs(ticket JiraIssue) []string {
+	var ids []string
+	for _, h := range ticket.Changelog.Histories {
+		ids = append(ids, h.ID)
+	}
+	return ids
+}
+
+func TestMerge(t *testing.T) {
+	stored := JiraIssue{Key: "M-1", TimeToClose: 12}
+	stored.Fields.Summary = "login fails"
+	stored.Fields.Status.Name = "Open"
+	stored.Changelog.Histories = []ChangelogHistory{{ID: "1"}, {ID: "2"}}
+	stored.Sentiment = Sentiment{Score: 0.4, HasScore: true}
+	stored.HasStepsToReproduce = true
+	stored.MarkAnalyzed("sentiment", time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC))
+	stored.MarkAnalyzed("time_to_close", time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	fetched := JiraIssue{Key: "M-1"}
+	fetched.Fields.Summary = "login fails"
+	fetched.Fields.Status.Name = "Closed"
+	fetched.Changelog.Histories = []ChangelogHistory{{ID: "2"}, {ID: "3"}}
+
+	merged := Merge(stored, fetched)
+	if got, want := historyIDs(merged), []string{"1", "2", "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("merged histories = %v, want %v", got, want)
+	}
+	if merged.Changelog.Total != 3 {
+		t.Errorf("merged changelog total = %d, want 3", merged.Changelog.Total)
+	}
+	if merged.Fields.Status.Name != "Closed" {
+		t.Errorf("merged status = %q, want the fetched one", merged.Fields.Status.Name)
+	}
+	if !reflect.DeepEqual(merged.Sentiment, stored.Sentiment) || !merged.HasStepsToReproduce ||
+		merged.AnalyzedAt == nil {
+		t.Errorf("scores of a ticket whose text did not change were dropped: %+v", merged)
+	}
+	// the time to close is kept, but marked for recomputation as the new histories may have resolved the ticket.
+	if merged.TimeToClose != 12 {
+		t.Errorf("merged time to close = %v, want the stored 12", merged.TimeToClose)
+	}
+	if _, ok := merged.AnalyzedAt["time_to_close"]; ok {
+		t.Error("time to close of a ticket with new histories is not marked for recomputation")
+	}
+	if _, ok := stored.AnalyzedAt["time_to_close"]; !ok {
+		t.Error("Merge modified the stored analysis timestamps")
+	}
+
+	fetched.Fields.Summary = "login fails on Safari"
+	merged = Merge(stored, fetched)
+	if merged.Sentiment.HasScore || merged.HasStepsToReproduce || merged.AnalyzedAt != nil {
+		t.Errorf("scores of a ticket whose text changed were preserved: %+v", merged)
+	}
+	if len(stored.Changelog.Histories) != 2 {
+		t.Errorf("Merge modified the stored histories: %v", historyIDs(stored))
+	}
+}
@@ -0,0 +1,141 @@
+package plot
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/jira"
+	"github.com/wcharczuk/go-chart"
+	"github.com/wcharczuk/go-chart/drawing"
+)
+
+const (
+	heatmapCellSize    = 100
+	heatmapLabelMargin = 220
+	heatmapTitleHeight = 80
+	heatmapScaleWidth  = 40
+	heatmapScaleMargin = 40
+	heatmapScaleLabels = 60
+)
+
+// missingCorrelationColor colors the cells of correlations which could not be computed.
+var missingCorrelationColor = drawing.Color{R: 200, G: 200, B: 200, A: 255}
+
+// CorrelationHeatmap draws a color-coded heatmap of a square correlation matrix whose rows and columns are
+// index-aligned with labels, with a color scale going from -1 (blue) through 0 (white) to 1 (red).
+func CorrelationHeatmap(labels []string, matrix [][]float64) error {
+	if len(labels) == 0 {
+		return &RenderError{Chart: "Correlation Matrix", Err: ErrDegenerateData}
+	}
+	if len(matrix) != len(labels) {
+		return &RenderError{Chart: "Correlation Matrix", Points: len(matrix),
+			Err: fmt.Errorf("matrix has %d rows for %d labels", len(matrix), len(labels))}
+	}
+	for _, row := range matrix {
+		if len(row) != len(labels) {
+			return &RenderError{Chart: "Correlation Matrix", Points: len(matrix),
+				Err: errors.New("matrix is not square")}
+		}
+	}
+	h := heatmap{title: "Correlation Matrix", labels: labels, matrix: matrix}
+	filePath := chartPath("correlation_matrix.png")
+	return save(h, h.title, len(labels)*len(labels), filePath)
+}
+
+// CorrelationMatrix draws the heatmap of the pairwise correlations between the numeric features of the tickets.
+func CorrelationMatrix(tickets ...jira.JiraIssue) error {
+	return CorrelationHeatmap(analyze.CorrelationMatrix(tickets...))
+}
+
+// heatmap defines a renderable grid of colored boxes, one per matrix cell.
+type heatmap struct {
+	title  string
+	labels []string
+	matrix [][]float64
+}
+
+// Render draws the heatmap using the given renderer provider and writes the result to w.
+func (h heatmap) Render(rp chart.RendererProvider, w io.Writer) error {
+	n := len(h.labels)
+	gridSize := n * heatmapCellSize
+	width := heatmapLabelMargin + gridSize + heatmapScaleMargin + heatmapScaleWidth + heatmapScaleLabels
+	height := heatmapTitleHeight + gridSize + heatmapLabelMargin
+	r, err := rp(width, height)
+	if err != nil {
+		return err
+	}
+	font, err := chart.GetDefaultFont()
+	if err != nil {
+		return err
+	}
+	text := chart.Style{Show: true, Font: font, FontSize: 12, FontColor: chart.ColorBlack}
+
+	chart.Draw.Box(r, chart.Box{Top: 0, Left: 0, Right: width, Bottom: height},
+		chart.Style{FillColor: chart.ColorWhite, StrokeColor: chart.ColorWhite})
+
+	title := text
+	title.FontSize = 20
+	tb := chart.Draw.MeasureText(r, h.title, title)
+	chart.Draw.Text(r, h.title, (width-tb.Width())/2, heatmapTitleHeight/2+tb.Height()/2, title)
+
+	top, left := heatmapTitleHeight, heatmapLabelMargin
+	for i, row := range h.matrix {
+		for j, v := range row {
+			cell := chart.Box{
+				Top:    top + i*heatmapCellSize,
+				Left:   left + j*heatmapCellSize,
+				Bottom: top + (i+1)*heatmapCellSize,
+				Right:  left + (j+1)*heatmapCellSize,
+			}
+			style := chart.Style{FillColor: divergingColor(v), StrokeColor: chart.ColorWhite, StrokeWidth: 1}
+			chart.Draw.Box(r, cell, style)
+			value := "n/a"
+			if !math.IsNaN(v) {
+				value = fmt.Sprintf("%.2f", v)
+			}
+			vb := chart.Draw.MeasureText(r, value, text)
+			x, y := cell.Left+(heatmapCellSize-vb.Width())/2, cell.Top+(heatmapCellSize+vb.Height())/2
+			chart.Draw.Text(r, value, x, y, text)
+		}
+	}
+
+	rotated := text
+	rotated.TextRotationDegrees = 90
+	for i, label := range h.labels {
+		lb := chart.Draw.MeasureText(r, label, text)
+		chart.Draw.Text(r, label, left-lb.Width()-10, top+i*heatmapCellSize+(heatmapCellSize+lb.Height())/2, text)
+		chart.Draw.Text(r, label, left+i*heatmapCellSize+(heatmapCellSize-lb.Height())/2, top+gridSize+10, rotated)
+	}
+
+	scaleLeft := left + gridSize + heatmapScaleMargin
+	for y := 0; y < gridSize; y++ {
+		v := 1 - 2*float64(y)/float64(gridSize-1)
+		line := chart.Box{Top: top + y, Left: scaleLeft, Bottom: top + y + 1, Right: scaleLeft + heatmapScaleWidth}
+		chart.Draw.Box(r, line, chart.Style{FillColor: divergingColor(v), StrokeColor: divergingColor(v)})
+	}
+	for _, tick := range []float64{1, 0, -1} {
+		label := fmt.Sprintf("%.0f", tick)
+		lb := chart.Draw.MeasureText(r, label, text)
+		y := top + int((1-tick)/2*float64(gridSize-1))
+		chart.Draw.Text(r, label, scaleLeft+heatmapScaleWidth+10, y+lb.Height()/2, text)
+	}
+	return r.Save(w)
+}
+
+// divergingColor maps a correlation between -1 and 1 to a color going from blue through white to red.
+func divergingColor(v float64) drawing.Color {
+	if math.IsNaN(v) {
+		return missingCorrelationColor
+	}
+	v = math.Max(-1, math.Min(1, v))
+	fade := func(f float64) uint8 {
+		return uint8(255 - f*255)
+	}
+	if v < 0 {
+		return drawing.Color{R: fade(-v), G: fade(-v), B: 255, A: 255}
+	}
+	return drawing.Color{R: 255, G: fade(v), B: fade(v), A: 255}
+}
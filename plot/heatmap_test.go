@@ -0,0 +1,28 @@
+package plot
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/wcharczuk/go-chart"
+)
+
+func TestHeatmapRender(t *testing.T) {
+	h := heatmap{
+		title:  "Correlation Matrix",
+		labels: []string{"time_to_close", "comment_words", "sentiment"},
+		matrix: [][]float64{{1, 0.5, -0.8}, {0.5, 1, math.NaN()}, {-0.8, math.NaN(), 1}},
+	}
+	var buf bytes.Buffer
+	if err := h.Render(chart.PNG, &buf); err != nil {
+		t.Fatalf("could not render heatmap: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("\x89PNG")) {
+		t.Errorf("rendered heatmap is not a PNG image (%d bytes)", buf.Len())
+	}
+
+	if err := CorrelationHeatmap(h.labels, h.matrix[:2]); err == nil {
+		t.Error("expected an error for a matrix which is not square")
+	}
+}
@@ -0,0 +1,102 @@
+package plot
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+const (
+	// pdfMargin and pdfRowHeight define the page margins and the height of a table row, in points.
+	pdfMargin    = 40
+	pdfRowHeight = 20
+	pdfFont      = "Helvetica"
+)
+
+// ReportMetric defines a headline statistic laid out in the summary table of a PDF report.
+type ReportMetric struct {
+	Name  string
+	Value string
+}
+
+// PDFReport writes to w a landscape A4 PDF document made of a titled summary table of the metrics followed by one
+// page per PNG chart found at the given paths, each titled with its file name and scaled to fit the page. Text is
+// encoded in the Windows-1252 code page of the standard PDF fonts.
+func PDFReport(w io.Writer, title string, metrics []ReportMetric, charts []string) error {
+	pdf := gofpdf.New("L", "pt", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	encode := pdf.UnicodeTranslatorFromDescriptor("")
+	pageWidth, pageHeight := pdf.GetPageSize()
+
+	pdf.AddPage()
+	pdf.SetFont(pdfFont, "", 20)
+	pdf.CellFormat(0, 2*pdfRowHeight, encode(title), "", 1, "L", false, 0, "")
+	pdf.SetFont(pdfFont, "", 12)
+	for _, m := range metrics {
+		pdf.CellFormat(pageWidth/2-pdfMargin, pdfRowHeight, encode(m.Name), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, pdfRowHeight, encode(m.Value), "", 1, "L", false, 0, "")
+	}
+
+	for _, path := range charts {
+		image := pdf.RegisterImageOptions(path, gofpdf.ImageOptions{ImageType: "PNG"})
+		if err := pdf.Error(); err != nil {
+			return fmt.Errorf("could not embed chart %s: %v", path, err)
+		}
+		pdf.AddPage()
+		pdf.SetFont(pdfFont, "", 14)
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		pdf.CellFormat(0, pdfRowHeight, encode(name), "", 1, "L", false, 0, "")
+		top := pdf.GetY()
+		scale := math.Min((pageWidth-2*pdfMargin)/image.Width(), (pageHeight-pdfMargin-top)/image.Height())
+		width, height := image.Width()*scale, image.Height()*scale
+		pdf.ImageOptions(path, (pageWidth-width)/2, top, width, height, false, gofpdf.ImageOptions{ImageType: "PNG"},
+			0, "")
+	}
+	return pdf.Output(w)
+}
+
+// HeadlineMetrics returns the statistics laid out in the summary table of the PDF report and in the terminal
+// summary, weighing the quality of the tickets with weights.
+func HeadlineMetrics(weights analyze.PriorityWeights, tickets ...jira.JiraIssue) []ReportMetric {
+	var times []float64
+	for _, t := range tickets {
+		if t.TimeToClose > 0 {
+			times = append(times, t.TimeToClose)
+		}
+	}
+	metrics := []ReportMetric{
+		{Name: "Tickets", Value: strconv.Itoa(len(tickets))},
+		{Name: "Closed tickets", Value: strconv.Itoa(len(times))},
+	}
+	if len(times) > 0 {
+		var total float64
+		for _, t := range times {
+			total += t
+		}
+		percentiles := analyze.Percentiles(times, 50, 90)
+		metrics = append(metrics,
+			ReportMetric{Name: "Mean time-to-close", Value: fmt.Sprintf("%.1fh", total/float64(len(times)))},
+			ReportMetric{Name: "Median time-to-close", Value: fmt.Sprintf("%.1fh", percentiles[0])},
+			ReportMetric{Name: "90th percentile time-to-close", Value: fmt.Sprintf("%.1fh", percentiles[1])},
+		)
+	}
+	if mean, ok := analyze.MeanInterArrival(tickets...); ok {
+		metrics = append(metrics, ReportMetric{Name: "Mean inter-arrival time", Value: fmt.Sprintf("%.1fh",
+			mean.Hours())})
+	}
+	if quality := analyze.PriorityWeightedQuality(weights, tickets...); !math.IsNaN(quality) {
+		metrics = append(metrics, ReportMetric{Name: "Priority-weighted quality", Value: fmt.Sprintf("%.2f",
+			quality)})
+	}
+	_, noResponse := analyze.FirstResponseDistribution(tickets...)
+	metrics = append(metrics, ReportMetric{Name: "Tickets without a response", Value: strconv.Itoa(noResponse)})
+	return metrics
+}
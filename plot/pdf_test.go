@@ -0,0 +1,77 @@
+package plot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rsc.io/pdf"
+)
+
+func TestPDFReport(t *testing.T) {
+	defer useTempOutputDir(t)()
+	var charts []string
+	for _, name := range []string{"words.png", "comments.png"} {
+		err := scatter("Number of words", "Time-To-Close (hours)", "Words", chartPath(name), []float64{1, 2, 3},
+			[]float64{10, 30, 20}, []string{"R-1", "R-2", "R-3"})
+		if err != nil {
+			t.Fatalf("could not plot %s: %v", name, err)
+		}
+		charts = append(charts, filepath.Join(OutputDir, name))
+	}
+
+	path := filepath.Join(OutputDir, "report.pdf")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := []ReportMetric{
+		{Name: "Tickets", Value: "3"},
+		{Name: "Mean time-to-close (hours)", Value: "20.0h"},
+		{Name: "Équipe", Value: "Qualité"},
+	}
+	err = PDFReport(file, "report", metrics, charts)
+	file.Close()
+	if err != nil {
+		t.Fatalf("could not write PDF report: %v", err)
+	}
+
+	report, err := pdf.Open(path)
+	if err != nil {
+		t.Fatalf("could not parse PDF report: %v", err)
+	}
+	// the summary table and each chart are laid out on their own page.
+	if report.NumPage() != 3 {
+		t.Fatalf("report holds %d pages, want 3", report.NumPage())
+	}
+	for i, want := range []int{0, 1, 1} {
+		page := report.Page(i + 1)
+		xobjects := page.Resources().Key("XObject")
+		images := 0
+		pdf.Interpret(page.V.Key("Contents"), func(stk *pdf.Stack, op string) {
+			if op != "Do" {
+				return
+			}
+			if name := stk.Pop().Name(); xobjects.Key(name).Key("Subtype").Name() == "Image" {
+				images++
+			}
+		})
+		if images != want {
+			t.Errorf("page %d draws %d images, want %d", i+1, images, want)
+		}
+	}
+	// the text extracted from the summary page is laid out glyph by glyph, without its spaces.
+	var text strings.Builder
+	for _, s := range report.Page(1).Content().Text {
+		text.WriteString(s.S)
+	}
+	if !strings.Contains(text.String(), "Meantime-to-close(hours)20.0hÉquipeQualité") {
+		t.Errorf("summary page text %q does not lay out the metrics", text.String())
+	}
+
+	if err := PDFReport(ioutil.Discard, "report", nil, []string{filepath.Join(OutputDir, "missing.png")}); err == nil {
+		t.Error("expected an error for a missing chart")
+	}
+}
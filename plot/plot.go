@@ -1,27 +1,300 @@
 package plot
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/nclandrei/ticketguru/analyze"
 	"github.com/nclandrei/ticketguru/jira"
 	"github.com/wcharczuk/go-chart"
 	"github.com/wcharczuk/go-chart/drawing"
+	"github.com/wcharczuk/go-chart/util"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 const (
-	graphsFolder = "graphs"
+	// GraphsFolder defines the folder, relative to the working directory, where charts are written.
+	GraphsFolder = "graphs"
 )
 
+// ChartStore defines a storage where rendered charts can be persisted for later retrieval.
+type ChartStore interface {
+	PutChart(name string, png []byte) error
+}
+
+var (
+	chartStore        ChartStore
+	writeToFilesystem = true
+
+	// OutputDir defines the directory, absolute or relative to the working directory, where charts are written.
+	OutputDir = GraphsFolder
+
+	// MinPointsForTrend defines the minimum number of points a scatter plot needs for a trend line to be drawn.
+	MinPointsForTrend = 10
+
+	// ColorByPercentile makes scatter plots color points by the percentile rank of their y value rather than by
+	// its absolute value, so that a few extreme outliers do not compress the color range of all other points.
+	ColorByPercentile = false
+
+	// PointSidecar makes scatter plots write, next to each chart, a JSON file mapping the coordinates of every
+	// plotted point to the key of its ticket.
+	PointSidecar = false
+
+	// ScatterYMax fixes the upper bound of the Y axis of scatter plots, omitting the points above it, so that
+	// charts of different runs share the same range; 0 lets the range grow with the data.
+	ScatterYMax float64
+
+	// ScatterYLabel overrides the Y axis label of scatter plots if not empty.
+	ScatterYLabel = ""
+
+	// ExcludeCode makes the comments and fields complexity plots count words with code blocks stripped.
+	ExcludeCode = false
+
+	// TrimPct makes the attachments, steps to reproduce, stack traces and creation weekday barcharts draw the
+	// mean of each bar's times-to-close once the TrimPct fraction of the fastest and of the slowest of them are
+	// discarded, so that a few outliers do not skew it.
+	TrimPct float64
+
+	// ScatterPalette defines the palette used to color the points of scatter plots.
+	ScatterPalette Palette = chart.Viridis
+)
+
+// Palette maps a value within the [vmin, vmax] range to a color.
+type Palette func(v, vmin, vmax float64) drawing.Color
+
+// Palettes holds the palettes scatter plots can be colored with, indexed by name.
+var Palettes = map[string]Palette{
+	"viridis":   chart.Viridis,
+	"cividis":   Cividis,
+	"grayscale": Grayscale,
+}
+
+// PaletteByName returns the palette registered under name inside Palettes.
+func PaletteByName(name string) (Palette, error) {
+	palette, ok := Palettes[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown palette %q", name)
+	}
+	return palette, nil
+}
+
+// cividisStops holds evenly spaced colors of the colorblind-safe Cividis colormap.
+var cividisStops = []drawing.Color{
+	{R: 0, G: 34, B: 78, A: 255},
+	{R: 65, G: 77, B: 107, A: 255},
+	{R: 124, G: 123, B: 120, A: 255},
+	{R: 188, G: 175, B: 111, A: 255},
+	{R: 254, G: 232, B: 56, A: 255},
+}
+
+// Cividis returns the color of v on the Cividis colormap, which is perceived the same with color vision deficiencies.
+func Cividis(v, vmin, vmax float64) drawing.Color {
+	pos := normalize(v, vmin, vmax) * float64(len(cividisStops)-1)
+	i := int(pos)
+	if i >= len(cividisStops)-1 {
+		return cividisStops[len(cividisStops)-1]
+	}
+	from, to, frac := cividisStops[i], cividisStops[i+1], pos-float64(i)
+	lerp := func(a, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*frac)
+	}
+	return drawing.Color{R: lerp(from.R, to.R), G: lerp(from.G, to.G), B: lerp(from.B, to.B), A: 255}
+}
+
+// Grayscale returns a gray shade for v, dark for low values and light for high ones, suitable for print.
+// The lightest shade is kept off white so that points remain visible on the chart background.
+func Grayscale(v, vmin, vmax float64) drawing.Color {
+	shade := uint8(normalize(v, vmin, vmax) * 200)
+	return drawing.Color{R: shade, G: shade, B: shade, A: 255}
+}
+
+// normalize returns the position of v inside the [vmin, vmax] range, clamped between 0 and 1.
+func normalize(v, vmin, vmax float64) float64 {
+	if vmax <= vmin {
+		return 0
+	}
+	return math.Max(0, math.Min(1, (v-vmin)/(vmax-vmin)))
+}
+
+// StoreCharts makes all subsequently rendered charts be persisted inside store under their file name and,
+// only if keepFiles is true, on the filesystem as well.
+func StoreCharts(store ChartStore, keepFiles bool) {
+	chartStore = store
+	writeToFilesystem = keepFiles
+}
+
+// ErrDegenerateData is returned when a chart has no values or all of its values are identical, leaving
+// no axis range to render.
+var ErrDegenerateData = errors.New("degenerate data: no values or all values are identical")
+
+// RenderError describes a chart which could not be rendered.
+type RenderError struct {
+	Chart  string
+	Points int
+	Err    error
+}
+
+// Error returns the description of the render error.
+func (e *RenderError) Error() string {
+	return fmt.Sprintf("could not render chart %q with %d points: %v", e.Chart, e.Points, e.Err)
+}
+
 // Plot defines a standard analysis plotting function.
 type Plot func(...jira.JiraIssue) error
 
+// RenderErrors holds the errors of all the plotting functions which failed during a single RenderAll call.
+type RenderErrors []error
+
+// Error returns the descriptions of all the render errors.
+func (e RenderErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d charts could not be plotted:\n  %s", len(e), strings.Join(msgs, "\n  "))
+}
+
+// RenderAll runs the plotting functions on the same tickets with at most concurrency of them running at once,
+// returning the errors of all the failed ones as RenderErrors, or nil if all of them succeeded.
+func RenderAll(concurrency int, plots []Plot, tickets ...jira.JiraIssue) error {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	var (
+		wg   sync.WaitGroup
+		lock sync.Mutex
+		errs RenderErrors
+	)
+	sem := make(chan struct{}, concurrency)
+	for _, p := range plots {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(p Plot) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := p(tickets...); err != nil {
+				lock.Lock()
+				errs = append(errs, err)
+				lock.Unlock()
+			}
+		}(p)
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// chartPath returns the path of a chart file inside OutputDir.
+func chartPath(name string) string {
+	return filepath.Join(OutputDir, name)
+}
+
+// LatestRunLink holds the name of the symlink, inside the base output directory, pointing to the newest run.
+const LatestRunLink = "latest"
+
+// NewRunDir creates, inside base, a directory named after now formatted with layout (e.g. time.RFC3339) for
+// the charts of a single run, points the LatestRunLink symlink of base to it and returns its path.
+func NewRunDir(base, layout string, now time.Time) (string, error) {
+	name := now.UTC().Format(layout)
+	dir := filepath.Join(base, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("could not create run directory: %v", err)
+	}
+	// the symlink is created under a temporary name and renamed so that latest is replaced atomically.
+	link := filepath.Join(base, LatestRunLink)
+	tmp := link + ".tmp"
+	os.Remove(tmp)
+	if err := os.Symlink(name, tmp); err != nil {
+		return "", fmt.Errorf("could not link latest run directory: %v", err)
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("could not link latest run directory: %v", err)
+	}
+	return dir, nil
+}
+
+// SampleByPriority returns a reproducible weighted sample, without replacement, of at most n tickets where the
+// chance of a ticket being picked is proportional to the weight of its priority. Priorities missing from
+// weights weigh 1 and priorities with a non-positive weight are never picked. The same seed and tickets always
+// yield the same sample, in the original order of the tickets.
+func SampleByPriority(weights analyze.PriorityWeights, n int, seed int64, tickets ...jira.JiraIssue) []jira.JiraIssue {
+	type keyed struct {
+		index int
+		key   float64
+	}
+	rng := rand.New(rand.NewSource(seed))
+	var candidates []keyed
+	for i, ticket := range tickets {
+		weight, ok := weights.Weight(ticket)
+		if !ok {
+			weight = 1
+		}
+		u := rng.Float64()
+		if weight <= 0 {
+			continue
+		}
+		// Efraimidis-Spirakis: picking the n largest u^(1/w) keys samples proportionally to the weights.
+		candidates = append(candidates, keyed{i, math.Pow(u, 1/weight)})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].key > candidates[j].key
+	})
+	if n < len(candidates) {
+		candidates = candidates[:n]
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].index < candidates[j].index
+	})
+	sample := make([]jira.JiraIssue, len(candidates))
+	for i, c := range candidates {
+		sample[i] = tickets[c.index]
+	}
+	return sample
+}
+
+// SampledByPriority wraps a plotting function so that it only receives a weighted sample of n tickets drawn by
+// SampleByPriority; a non-positive n leaves the plotting function unchanged.
+func SampledByPriority(f Plot, weights analyze.PriorityWeights, n int, seed int64) Plot {
+	if n <= 0 {
+		return f
+	}
+	return func(tickets ...jira.JiraIssue) error {
+		return f(SampleByPriority(weights, n, seed, tickets...)...)
+	}
+}
+
 // Attachments draws a stacked barchart for attachments analysis.
 func Attachments(tickets ...jira.JiraIssue) error {
+	return barchart(
+		"Attachments analysis",
+		"Time-To-Close (hours)",
+		chartPath("attachments.png"),
+		attachmentTimes(tickets...),
+	)
+}
+
+// attachmentTimes returns the mean time-to-close of the closed high priority tickets, indexed by the label of
+// the attachment types they hold or "Without Attachments".
+func attachmentTimes(tickets ...jira.JiraIssue) map[string]float64 {
 	result := make(map[string]float64)
-	var withoutCount int
-	var withoutTime float64
-	typeCountM := make(map[jira.AttachmentType]int)
-	typeTimeM := make(map[jira.AttachmentType]float64)
+	var withoutTimes []float64
+	labelTimesM := make(map[string][]float64)
 	for _, ticket := range tickets {
 		highPriority := jira.IsHighPriority(ticket)
 		if ticket.TimeToClose <= 0 ||
@@ -30,65 +303,47 @@ func Attachments(tickets ...jira.JiraIssue) error {
 			continue
 		}
 		if len(ticket.Fields.Attachments) == 0 {
-			withoutCount++
-			withoutTime += ticket.TimeToClose
+			withoutTimes = append(withoutTimes, ticket.TimeToClose)
 			continue
 		}
+		// Each ticket is counted once per label no matter how many attachments of that label it has.
+		labels := make(map[string]bool)
 		for _, a := range ticket.Fields.Attachments {
-			typeCountM[a.Type]++
-			typeTimeM[a.Type] += ticket.TimeToClose
-		}
-	}
-	result["Without Attachments"] = withoutTime / float64(withoutCount)
-	for k, v := range typeCountM {
-		var score float64
-		switch k {
-		case jira.CodeAttachment:
-			score = typeTimeM[k] / float64(v)
-			result["Code"] = score
-			break
-		case jira.ArchiveAttachment:
-			score = typeTimeM[k] / float64(v)
-			result["Archive"] = score
-			break
-		case jira.ImageAttachment:
-			score = typeTimeM[k] / float64(v)
-			result["Image"] = score
-			break
-		case jira.ConfigAttachment:
-			score = typeTimeM[k] / float64(v)
-			result["Config"] = score
-			break
-		case jira.TextAttachment:
-			score = typeTimeM[k] / float64(v)
-			result["Text"] = score
-			break
-		case jira.SpreadsheetAttachment:
-			score = typeTimeM[k] / float64(v)
-			result["Spreadsheet"] = score
-			break
-		default:
-			score = typeTimeM[k] / float64(v)
-			result["Other"] = score
-			break
-		}
-	}
-	wd, err := os.Getwd()
-	if err != nil {
-		return err
+			labels[attachmentLabel(a.Type)] = true
+		}
+		for label := range labels {
+			labelTimesM[label] = append(labelTimesM[label], ticket.TimeToClose)
+		}
+	}
+	result["Without Attachments"] = meanTimeToClose(withoutTimes)
+	for label, times := range labelTimesM {
+		result[label] = meanTimeToClose(times)
+	}
+	return result
+}
+
+// AttachmentPercentiles produces a barchart grouping, for each attachment type, the 25th, 50th and 75th
+// percentiles of the time-to-close of the tickets holding attachments of that type. Types without any closed
+// ticket are left out.
+func AttachmentPercentiles(tickets ...jira.JiraIssue) error {
+	result := make(map[string]float64)
+	for t, times := range analyze.TimeToCloseByAttachmentType(tickets...) {
+		percentiles := analyze.Percentiles(times, 25, 50, 75)
+		for i, p := range []string{"p25", "p50", "p75"} {
+			result[attachmentLabel(t)+" "+p] = percentiles[i]
+		}
 	}
 	return barchart(
-		"Attachments analysis",
+		"Time-To-Close Percentiles By Attachment Type",
 		"Time-To-Close (hours)",
-		fmt.Sprintf("%s/%s/%s", wd, graphsFolder, "attachments.png"),
+		chartPath("attachment_percentiles.png"),
 		result,
 	)
 }
 
 // StepsToReproduce produces a barchart for presence of steps to reproduce in tickets.
 func StepsToReproduce(tickets ...jira.JiraIssue) error {
-	var withCount, withoutCount int
-	var withSum, withoutSum float64
+	var withTimes, withoutTimes []float64
 	for _, ticket := range tickets {
 		highPriority := jira.IsHighPriority(ticket)
 		if ticket.TimeToClose <= 0 ||
@@ -97,32 +352,25 @@ func StepsToReproduce(tickets ...jira.JiraIssue) error {
 			continue
 		}
 		if ticket.HasStepsToReproduce {
-			withCount++
-			withSum += ticket.TimeToClose
+			withTimes = append(withTimes, ticket.TimeToClose)
 		} else {
-			withoutCount++
-			withoutSum += ticket.TimeToClose
+			withoutTimes = append(withoutTimes, ticket.TimeToClose)
 		}
 	}
-	wd, err := os.Getwd()
-	if err != nil {
-		return err
-	}
 	return barchart(
 		"Steps To Reproduce Analysis",
 		"Time-To-Close (hours)",
-		fmt.Sprintf("%s/%s/%s", wd, graphsFolder, "steps_to_reproduce.png"),
+		chartPath("steps_to_reproduce.png"),
 		map[string]float64{
-			"With steps to reproduce":    withSum / float64(withCount),
-			"Without steps to reproduce": withoutSum / float64(withoutCount),
+			"With steps to reproduce":    meanTimeToClose(withTimes),
+			"Without steps to reproduce": meanTimeToClose(withoutTimes),
 		},
 	)
 }
 
 // Stacktraces produces a barchart for presence of stacktraces in tickets.
 func Stacktraces(tickets ...jira.JiraIssue) error {
-	var withCount, withoutCount int
-	var withSum, withoutSum float64
+	var withTimes, withoutTimes []float64
 	for _, ticket := range tickets {
 		highPriority := jira.IsHighPriority(ticket)
 		if ticket.TimeToClose <= 0 ||
@@ -131,24 +379,18 @@ func Stacktraces(tickets ...jira.JiraIssue) error {
 			continue
 		}
 		if ticket.HasStackTrace {
-			withCount++
-			withSum += ticket.TimeToClose
+			withTimes = append(withTimes, ticket.TimeToClose)
 		} else {
-			withoutCount++
-			withoutSum += ticket.TimeToClose
+			withoutTimes = append(withoutTimes, ticket.TimeToClose)
 		}
 	}
-	wd, err := os.Getwd()
-	if err != nil {
-		return err
-	}
 	return barchart(
 		"Stack Traces Analysis",
 		"Time-To-Close (hours)",
-		fmt.Sprintf("%s/%s/%s", wd, graphsFolder, "stack_traces.png"),
+		chartPath("stack_traces.png"),
 		map[string]float64{
-			"With stack traces":    withSum / float64(withCount),
-			"Without stack traces": withoutSum / float64(withoutCount),
+			"With stack traces":    meanTimeToClose(withTimes),
+			"Without stack traces": meanTimeToClose(withoutTimes),
 		},
 	)
 }
@@ -157,28 +399,31 @@ func Stacktraces(tickets ...jira.JiraIssue) error {
 func CommentsComplexity(tickets ...jira.JiraIssue) error {
 	var comms []float64
 	var times []float64
+	var keys []string
 	for _, ticket := range tickets {
 		highPriority := jira.IsHighPriority(ticket)
+		comments := analyze.CommentWords(ticket)
+		if ExcludeCode {
+			comments = analyze.CodelessCommentWords(ticket)
+		}
 		if highPriority &&
 			ticket.TimeToClose > 0 &&
 			ticket.TimeToClose < jira.MaxTimeToCloseH &&
-			ticket.CommentWordsCount > 0 &&
-			ticket.CommentWordsCount < jira.MaxCommWordCount {
-			comms = append(comms, float64(ticket.CommentWordsCount))
+			comments > 0 &&
+			comments < jira.MaxCommWordCount {
+			comms = append(comms, float64(comments))
 			times = append(times, ticket.TimeToClose)
+			keys = append(keys, ticket.Key)
 		}
 	}
-	wd, err := os.Getwd()
-	if err != nil {
-		return err
-	}
 	return scatter(
 		"Number of words in comments",
 		"Time-To-Close (hours)",
 		"Comments Complexity Analysis",
-		fmt.Sprintf("%s/%s/%s", wd, graphsFolder, "comment_complexity.png"),
+		chartPath("comment_complexity.png"),
 		comms,
 		times,
+		keys,
 	)
 }
 
@@ -186,22 +431,24 @@ func CommentsComplexity(tickets ...jira.JiraIssue) error {
 func FieldsComplexity(tickets ...jira.JiraIssue) error {
 	var fields []float64
 	var times []float64
+	var keys []string
 	for _, ticket := range tickets {
 		highPriority := jira.IsHighPriority(ticket)
+		words := analyze.SummaryDescWords(ticket)
+		if ExcludeCode {
+			words = analyze.CodelessSummaryDescWords(ticket)
+		}
 		if highPriority &&
 			ticket.TimeToClose > 0 &&
 			ticket.TimeToClose <= jira.MaxTimeToCloseH &&
-			ticket.SummaryDescWordsCount > 0 &&
-			ticket.SummaryDescWordsCount < jira.MaxSummaryDescWordCount {
-			fields = append(fields, float64(ticket.SummaryDescWordsCount))
+			words > 0 &&
+			words < jira.MaxSummaryDescWordCount {
+			fields = append(fields, float64(words))
 			times = append(times, ticket.TimeToClose)
+			keys = append(keys, ticket.Key)
 		}
 	}
-	wd, err := os.Getwd()
-	if err != nil {
-		return err
-	}
-	filePath := fmt.Sprintf("%s/%s/%s", wd, graphsFolder, "fields_complexity.png")
+	filePath := chartPath("fields_complexity.png")
 	return scatter(
 		"Number of words in summary and description",
 		"Time-To-Close (hours)",
@@ -209,6 +456,67 @@ func FieldsComplexity(tickets ...jira.JiraIssue) error {
 		filePath,
 		fields,
 		times,
+		keys,
+	)
+}
+
+// SummaryComplexity produces a scatter plot with trendline for summary complexity analysis.
+func SummaryComplexity(tickets ...jira.JiraIssue) error {
+	var counts []float64
+	var times []float64
+	var keys []string
+	for _, ticket := range tickets {
+		highPriority := jira.IsHighPriority(ticket)
+		words := analyze.SummaryWords(ticket)
+		if highPriority &&
+			ticket.TimeToClose > 0 &&
+			ticket.TimeToClose <= jira.MaxTimeToCloseH &&
+			words > 0 &&
+			words < jira.MaxSummaryDescWordCount {
+			counts = append(counts, float64(words))
+			times = append(times, ticket.TimeToClose)
+			keys = append(keys, ticket.Key)
+		}
+	}
+	filePath := chartPath("summary_complexity.png")
+	return scatter(
+		"Number of words in summary",
+		"Time-To-Close (hours)",
+		"Summary Complexity Analysis",
+		filePath,
+		counts,
+		times,
+		keys,
+	)
+}
+
+// DescriptionComplexity produces a scatter plot with trendline for description complexity analysis.
+func DescriptionComplexity(tickets ...jira.JiraIssue) error {
+	var counts []float64
+	var times []float64
+	var keys []string
+	for _, ticket := range tickets {
+		highPriority := jira.IsHighPriority(ticket)
+		words := analyze.DescriptionWords(ticket)
+		if highPriority &&
+			ticket.TimeToClose > 0 &&
+			ticket.TimeToClose <= jira.MaxTimeToCloseH &&
+			words > 0 &&
+			words < jira.MaxSummaryDescWordCount {
+			counts = append(counts, float64(words))
+			times = append(times, ticket.TimeToClose)
+			keys = append(keys, ticket.Key)
+		}
+	}
+	filePath := chartPath("description_complexity.png")
+	return scatter(
+		"Number of words in description",
+		"Time-To-Close (hours)",
+		"Description Complexity Analysis",
+		filePath,
+		counts,
+		times,
+		keys,
 	)
 }
 
@@ -216,6 +524,7 @@ func FieldsComplexity(tickets ...jira.JiraIssue) error {
 func GrammarCorrectness(tickets ...jira.JiraIssue) error {
 	var scores []float64
 	var times []float64
+	var keys []string
 	for _, ticket := range tickets {
 		highPriority := jira.IsHighPriority(ticket)
 		if highPriority &&
@@ -225,13 +534,10 @@ func GrammarCorrectness(tickets ...jira.JiraIssue) error {
 			ticket.GrammarCorrectness.Score < jira.MaxGrammarErrCount {
 			scores = append(scores, float64(ticket.GrammarCorrectness.Score))
 			times = append(times, ticket.TimeToClose)
+			keys = append(keys, ticket.Key)
 		}
 	}
-	wd, err := os.Getwd()
-	if err != nil {
-		return err
-	}
-	filePath := fmt.Sprintf("%s/%s/%s", wd, graphsFolder, "grammar_correctness.png")
+	filePath := chartPath("grammar_correctness.png")
 	return scatter(
 		"Number of grammar errors in summary, description and comments",
 		"Time-To-Close (hours)",
@@ -239,6 +545,7 @@ func GrammarCorrectness(tickets ...jira.JiraIssue) error {
 		filePath,
 		scores,
 		times,
+		keys,
 	)
 }
 
@@ -246,6 +553,7 @@ func GrammarCorrectness(tickets ...jira.JiraIssue) error {
 func SentimentAnalysis(tickets ...jira.JiraIssue) error {
 	var scores []float64
 	var times []float64
+	var keys []string
 	for _, ticket := range tickets {
 		highPriority := jira.IsHighPriority(ticket)
 		if highPriority &&
@@ -254,13 +562,10 @@ func SentimentAnalysis(tickets ...jira.JiraIssue) error {
 			ticket.Sentiment.HasScore {
 			scores = append(scores, ticket.Sentiment.Score)
 			times = append(times, ticket.TimeToClose)
+			keys = append(keys, ticket.Key)
 		}
 	}
-	wd, err := os.Getwd()
-	if err != nil {
-		return err
-	}
-	filePath := fmt.Sprintf("%s/%s/%s", wd, graphsFolder, "sentiment_analysis.png")
+	filePath := chartPath("sentiment_analysis.png")
 	return scatter(
 		"Sentiment score for summary, description and comments",
 		"Time-To-Close (hours)",
@@ -268,17 +573,550 @@ func SentimentAnalysis(tickets ...jira.JiraIssue) error {
 		filePath,
 		scores,
 		times,
+		keys,
+	)
+}
+
+// attachmentLabel returns the barchart label under which an attachment type is grouped.
+func attachmentLabel(t jira.AttachmentType) string {
+	switch t {
+	case jira.CodeAttachment:
+		return "Code"
+	case jira.ArchiveAttachment:
+		return "Archive"
+	case jira.ImageAttachment:
+		return "Image"
+	case jira.ConfigAttachment:
+		return "Config"
+	case jira.TextAttachment:
+		return "Text"
+	case jira.SpreadsheetAttachment:
+		return "Spreadsheet"
+	case jira.VideoAttachment:
+		return "Video"
+	default:
+		return "Other"
+	}
+}
+
+// meanTimeToClose returns the mean of times, trimmed by TrimPct, or 0 if there are no times.
+func meanTimeToClose(times []float64) float64 {
+	mean, err := analyze.TrimmedMean(times, TrimPct)
+	if err != nil {
+		return 0
+	}
+	return mean
+}
+
+// GrammarSentiment produces a scatter plot with trendline of grammar errors against sentiment scores.
+func GrammarSentiment(tickets ...jira.JiraIssue) error {
+	var grammar []float64
+	var sentiment []float64
+	var keys []string
+	for _, ticket := range tickets {
+		if ticket.GrammarCorrectness.HasScore &&
+			ticket.GrammarCorrectness.Score < jira.MaxGrammarErrCount &&
+			ticket.Sentiment.HasScore {
+			grammar = append(grammar, float64(ticket.GrammarCorrectness.Score))
+			sentiment = append(sentiment, ticket.Sentiment.Score)
+			keys = append(keys, ticket.Key)
+		}
+	}
+	filePath := chartPath("grammar_sentiment.png")
+	return scatter(
+		"Number of grammar errors in summary and description",
+		"Sentiment score for comments",
+		"Grammar And Sentiment Analysis",
+		filePath,
+		grammar,
+		sentiment,
+		keys,
+	)
+}
+
+// ResolutionTrend draws the rolling mean time-to-close of the tickets resolved in each time bucket, computed
+// over window buckets and weighted by volume if requested. Buckets holding fewer than minVolume tickets are
+// annotated with their number of tickets.
+func ResolutionTrend(bucket time.Duration, window int, volumeWeighted bool, minVolume int) Plot {
+	return func(tickets ...jira.JiraIssue) error {
+		buckets := analyze.ResolutionTrend(bucket, window, volumeWeighted, tickets...)
+		dates := make([]time.Time, len(buckets))
+		means := make([]float64, len(buckets))
+		lowVolume := chart.AnnotationSeries{Style: chart.Style{Show: true, FontSize: 10}}
+		for i, b := range buckets {
+			dates[i] = b.Start
+			means[i] = b.RollingMean
+			if b.Count < minVolume {
+				lowVolume.Annotations = append(lowVolume.Annotations, chart.Value2{
+					XValue: util.Time.ToFloat64(b.Start),
+					YValue: b.RollingMean,
+					Label:  fmt.Sprintf("n=%d", b.Count),
+				})
+			}
+		}
+		title := "Resolution Trend"
+		if volumeWeighted {
+			title = "Volume Weighted Resolution Trend"
+		}
+		return line(
+			title,
+			"Rolling mean Time-To-Close (hours)",
+			chartPath("resolution_trend.png"),
+			dates,
+			means,
+			lowVolume,
+		)
+	}
+}
+
+// SlowestTransitions draws a barchart of the n status transitions which take the longest on average.
+func SlowestTransitions(n int) Plot {
+	return func(tickets ...jira.JiraIssue) error {
+		durations := analyze.TransitionDurations(tickets...)
+		keys := make([]string, 0, len(durations))
+		for k := range durations {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return durations[keys[i]].Mean > durations[keys[j]].Mean
+		})
+		if len(keys) > n {
+			keys = keys[:n]
+		}
+		result := make(map[string]float64, len(keys))
+		for _, k := range keys {
+			result[k] = durations[k].Mean
+		}
+		return barchart(
+			"Slowest Status Transitions",
+			"Mean hours in status before transition",
+			chartPath("slowest_transitions.png"),
+			result,
+		)
+	}
+}
+
+// CumulativeResolved returns a plotting function that produces a line chart of the cumulative number of tickets
+// resolved over time, bucketed by the given duration.
+func CumulativeResolved(bucket time.Duration) Plot {
+	return func(tickets ...jira.JiraIssue) error {
+		dates, counts := analyze.CumulativeResolved(bucket, tickets...)
+		return line(
+			"Cumulative Resolved Tickets",
+			"Resolved tickets",
+			chartPath("cumulative_resolved.png"),
+			dates,
+			intsToFloats(counts),
+		)
+	}
+}
+
+// WeeklyThroughput produces a barchart of the number of tickets resolved during each ISO week.
+func WeeklyThroughput(tickets ...jira.JiraIssue) error {
+	weeks, counts := analyze.WeeklyThroughput(tickets...)
+	result := make(map[string]float64, len(weeks))
+	for i, week := range weeks {
+		year, number := week.ISOWeek()
+		result[fmt.Sprintf("%d-W%02d", year, number)] = float64(counts[i])
+	}
+	return barchart(
+		"Weekly Throughput",
+		"Resolved tickets",
+		chartPath("weekly_throughput.png"),
+		result,
+	)
+}
+
+// CreationDayOfWeek returns a plotting function that produces a barchart of the mean time-to-close of tickets
+// by the weekday, in loc, on which they were created, from Monday to Sunday.
+func CreationDayOfWeek(loc *time.Location) Plot {
+	return func(tickets ...jira.JiraIssue) error {
+		result := make(map[string]float64)
+		for day, times := range analyze.CreationDayOfWeek(loc, tickets...) {
+			// days are numbered from Monday so that the bars, sorted by label, follow the ISO week.
+			number := (int(day)+6)%7 + 1
+			result[fmt.Sprintf("%d %s", number, day.String()[:3])] = meanTimeToClose(times)
+		}
+		return barchart(
+			"Time-To-Close by Creation Weekday",
+			"Mean Time-To-Close (hours)",
+			chartPath("creation_weekday.png"),
+			result,
+		)
+	}
+}
+
+// MonthlyResolution produces a barchart of the mean time-to-close of tickets by the calendar month they were
+// created in.
+func MonthlyResolution(tickets ...jira.JiraIssue) error {
+	result := make(map[string]float64)
+	for month, stats := range analyze.MonthlyResolutionStats(tickets...) {
+		result[fmt.Sprintf("%02d %s", int(month), month.String()[:3])] = stats.Mean
+	}
+	return barchart(
+		"Time-To-Close by Creation Month",
+		"Mean Time-To-Close (hours)",
+		chartPath("monthly_resolution.png"),
+		result,
 	)
 }
 
-// barchart computes and saves a barchart given a variadic number of bars.
+// ActiveVsWaiting returns a plotting function that produces a barchart of the percentage of the resolution time of
+// all closed tickets spent in the waiting statuses and in all other, active, statuses.
+func ActiveVsWaiting(waitingStatuses []string) Plot {
+	return func(tickets ...jira.JiraIssue) error {
+		var active, waiting float64
+		for _, ticket := range tickets {
+			if ticket.TimeToClose <= 0 {
+				continue
+			}
+			a, w := analyze.ActiveVsWaiting(ticket, waitingStatuses)
+			active += a
+			waiting += w
+		}
+		result := map[string]float64{"Active": 0, "Waiting": 0}
+		if total := active + waiting; total > 0 {
+			result["Active"] = active / total * 100
+			result["Waiting"] = waiting / total * 100
+		}
+		return barchart(
+			"Active vs Waiting Resolution Time",
+			"Share of resolution time (%)",
+			chartPath("active_waiting.png"),
+			result,
+		)
+	}
+}
+
+// CommentLengthDistribution returns a plotting function that produces a barchart of the number of comments, across
+// all tickets, by their length in words, bucketed by bucketWords words; comments longer than essayWords words
+// share a single bucket.
+func CommentLengthDistribution(bucketWords, essayWords int) Plot {
+	return func(tickets ...jira.JiraIssue) error {
+		result := make(map[string]float64)
+		for _, ticket := range tickets {
+			for _, length := range analyze.CommentLengths(ticket) {
+				label := fmt.Sprintf(">%d", essayWords)
+				if int(length) <= essayWords {
+					low := int(length) / bucketWords * bucketWords
+					label = fmt.Sprintf("%d-%d", low, low+bucketWords-1)
+				}
+				result[label]++
+			}
+		}
+		return barchart(
+			"Comment Length Distribution",
+			"Number of comments",
+			chartPath("comment_length.png"),
+			result,
+		)
+	}
+}
+
+// ReadingTimeDistribution returns a plotting function that produces a barchart of the number of tickets by
+// estimated reading time at wpm words per minute, bucketed by bucketMinutes minutes.
+func ReadingTimeDistribution(wpm, bucketMinutes int) Plot {
+	return func(tickets ...jira.JiraIssue) error {
+		result := make(map[string]float64)
+		for _, minutes := range analyze.ReadingTimes(wpm, tickets...) {
+			low := int(minutes) / bucketMinutes * bucketMinutes
+			result[fmt.Sprintf("%d-%d min", low, low+bucketMinutes)]++
+		}
+		return barchart(
+			"Reading Time Distribution",
+			"Number of tickets",
+			chartPath("reading_time.png"),
+			result,
+		)
+	}
+}
+
+// FirstResponseDistribution returns a plotting function that produces a barchart of the number of tickets by
+// time to first response, bucketed by bucketHours hours, titled with its median, 90th and 99th percentiles.
+// Tickets without any response share a single bucket.
+func FirstResponseDistribution(bucketHours int) Plot {
+	return func(tickets ...jira.JiraIssue) error {
+		hours, noResponse := analyze.FirstResponseDistribution(tickets...)
+		result := make(map[string]float64)
+		for _, h := range hours {
+			low := int(h) / bucketHours * bucketHours
+			result[fmt.Sprintf("%d-%dh", low, low+bucketHours)]++
+		}
+		if noResponse > 0 {
+			result["No response"] = float64(noResponse)
+		}
+		title := "Time To First Response Distribution"
+		if len(hours) > 0 {
+			p := analyze.Percentiles(hours, 50, 90, 99)
+			title = fmt.Sprintf("%s (p50 %.1fh, p90 %.1fh, p99 %.1fh)", title, p[0], p[1], p[2])
+		}
+		return barchart(
+			title,
+			"Number of tickets",
+			chartPath("first_response.png"),
+			result,
+		)
+	}
+}
+
+// BacklogOverTime returns a plotting function that produces a line chart of the number of open tickets over
+// time, bucketed by the given duration.
+func BacklogOverTime(bucket time.Duration) Plot {
+	return func(tickets ...jira.JiraIssue) error {
+		dates, counts := analyze.BacklogOverTime(bucket, tickets...)
+		return line(
+			"Backlog Over Time",
+			"Open tickets",
+			chartPath("backlog.png"),
+			dates,
+			intsToFloats(counts),
+		)
+	}
+}
+
+// CreationRate returns a plotting function that produces a line chart of the number of tickets created during
+// each time bucket of the given duration, titled with the mean inter-arrival time of the tickets.
+func CreationRate(bucket time.Duration) Plot {
+	return func(tickets ...jira.JiraIssue) error {
+		dates, counts := analyze.CreationRate(bucket, tickets...)
+		title := "Creation Rate"
+		if mean, ok := analyze.MeanInterArrival(tickets...); ok {
+			title = fmt.Sprintf("%s (mean inter-arrival %.1fh)", title, mean.Hours())
+		}
+		return line(
+			title,
+			"Created tickets",
+			chartPath("creation_rate.png"),
+			dates,
+			intsToFloats(counts),
+		)
+	}
+}
+
+// ControlChart produces a line chart of the times-to-close of closed tickets in creation order, alongside their
+// mean and control limits, highlighting in red the points lying outside of the limits.
+func ControlChart(tickets ...jira.JiraIssue) error {
+	dates, values, mean, ucl, lcl := analyze.ControlChart(tickets...)
+	if len(dates) < 2 {
+		return &RenderError{Chart: "Resolution Control Chart", Points: len(dates), Err: ErrDegenerateData}
+	}
+	limit := func(name string, v float64, dash []float64) chart.Series {
+		return chart.TimeSeries{
+			Name: name,
+			Style: chart.Style{
+				Show:            true,
+				StrokeWidth:     2,
+				StrokeColor:     chart.ColorAlternateGray,
+				StrokeDashArray: dash,
+			},
+			XValues: []time.Time{dates[0], dates[len(dates)-1]},
+			YValues: []float64{v, v},
+		}
+	}
+	series := []chart.Series{
+		limit("Mean", mean, nil),
+		limit("UCL", ucl, []float64{10, 5}),
+		limit("LCL", lcl, []float64{10, 5}),
+	}
+	outliers := chart.TimeSeries{
+		Name:  "Out of control",
+		Style: chart.Style{Show: true, StrokeWidth: chart.Disabled, DotWidth: 8, DotColor: drawing.ColorRed},
+	}
+	for _, i := range analyze.OutOfControl(values, ucl, lcl) {
+		outliers.XValues = append(outliers.XValues, dates[i])
+		outliers.YValues = append(outliers.YValues, values[i])
+	}
+	if len(outliers.XValues) > 0 {
+		series = append(series, outliers)
+	}
+	return line(
+		fmt.Sprintf("Resolution Control Chart (mean %.1fh, UCL %.1fh, LCL %.1fh)", mean, ucl, lcl),
+		"Time-To-Close (hours)",
+		chartPath("control_chart.png"),
+		dates,
+		values,
+		series...,
+	)
+}
+
+// SentimentTrajectory produces a line chart of the sentiment score of each comment of a ticket over time,
+// given the scores index-aligned with its comments, annotated with the status changes of its changelog.
+func SentimentTrajectory(ticket jira.JiraIssue, scores []float64) error {
+	comments := ticket.Fields.Comments.Comments
+	if len(scores) != len(comments) {
+		return &RenderError{Chart: "Sentiment Trajectory", Points: len(scores),
+			Err: fmt.Errorf("%d scores for %d comments", len(scores), len(comments))}
+	}
+	type point struct {
+		at    time.Time
+		score float64
+	}
+	points := make([]point, len(comments))
+	for i, comment := range comments {
+		points[i] = point{time.Time(comment.Created), scores[i]}
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].at.Before(points[j].at)
+	})
+	dates := make([]time.Time, len(points))
+	vals := make([]float64, len(points))
+	for i, p := range points {
+		dates[i], vals[i] = p.at, p.score
+	}
+	statusChanges := chart.AnnotationSeries{Style: chart.Style{Show: true, FontSize: 10}}
+	for _, t := range analyze.StatusTimeline(ticket) {
+		statusChanges.Annotations = append(statusChanges.Annotations, chart.Value2{
+			XValue: util.Time.ToFloat64(t.At),
+			YValue: 0,
+			Label:  t.To,
+		})
+	}
+	return line(
+		fmt.Sprintf("Sentiment Trajectory of %s", ticket.Key),
+		"Comment sentiment score",
+		chartPath(fmt.Sprintf("sentiment_trajectory_%s.png", ticket.Key)),
+		dates,
+		vals,
+		statusChanges,
+	)
+}
+
+// WordinessAnalysis returns a plotting function that produces a scatter plot of the word count of a given
+// field (see analyze.WordinessFields) against time-to-close.
+func WordinessAnalysis(field string) (Plot, error) {
+	if _, err := analyze.FieldWordCount(jira.JiraIssue{}, field); err != nil {
+		return nil, err
+	}
+	return func(tickets ...jira.JiraIssue) error {
+		var counts []float64
+		var times []float64
+		var keys []string
+		for _, ticket := range tickets {
+			highPriority := jira.IsHighPriority(ticket)
+			if !highPriority ||
+				ticket.TimeToClose <= 0 ||
+				ticket.TimeToClose > jira.MaxTimeToCloseH {
+				continue
+			}
+			count, err := analyze.FieldWordCount(ticket, field)
+			if err != nil {
+				return err
+			}
+			counts = append(counts, float64(count))
+			times = append(times, ticket.TimeToClose)
+			keys = append(keys, ticket.Key)
+		}
+		fileName := fmt.Sprintf("wordiness_%s.png", strings.Replace(field, "+", "_", -1))
+		return scatter(
+			fmt.Sprintf("Number of words in %s", field),
+			"Time-To-Close (hours)",
+			"Wordiness Analysis",
+			chartPath(fileName),
+			counts,
+			times,
+			keys,
+		)
+	}, nil
+}
+
+// WordCountHistogram returns a plotting function that produces a barchart of the number of tickets by the word
+// count of a given field (see analyze.WordinessFields), bucketed by binSize words.
+func WordCountHistogram(field string, binSize int) (Plot, error) {
+	if _, err := analyze.WordCountHistogram(field, binSize); err != nil {
+		return nil, err
+	}
+	return func(tickets ...jira.JiraIssue) error {
+		histogram, err := analyze.WordCountHistogram(field, binSize, tickets...)
+		if err != nil {
+			return err
+		}
+		var width int
+		for low := range histogram {
+			if w := len(strconv.Itoa(low)); w > width {
+				width = w
+			}
+		}
+		result := make(map[string]float64, len(histogram))
+		for low, count := range histogram {
+			// lower bounds are zero padded so that the bins sort numerically
+			result[fmt.Sprintf("%0*d-%d", width, low, low+binSize-1)] = float64(count)
+		}
+		return barchart(
+			fmt.Sprintf("Word Count Distribution Of %s", field),
+			"Number of tickets",
+			chartPath(fmt.Sprintf("word_histogram_%s.png", strings.Replace(field, "+", "_", -1))),
+			result,
+		)
+	}, nil
+}
+
+// ReopenDistribution produces a barchart of the number of tickets by the number of times they were reopened.
+func ReopenDistribution(tickets ...jira.JiraIssue) error {
+	distribution := analyze.ReopenDistribution(tickets...)
+	var width int
+	for reopens := range distribution {
+		if w := len(strconv.Itoa(reopens)); w > width {
+			width = w
+		}
+	}
+	result := make(map[string]float64, len(distribution))
+	for reopens, count := range distribution {
+		result[fmt.Sprintf("%0*d", width, reopens)] = float64(count)
+	}
+	return barchart(
+		"Reopen Count Distribution",
+		"Number of tickets",
+		chartPath("reopen_distribution.png"),
+		result,
+	)
+}
+
+// ReopenRateByDescriptionLength returns a plotting function drawing a barchart of the reopen rate of resolved
+// tickets per bucket of bucketSize description words.
+func ReopenRateByDescriptionLength(bucketSize int) (Plot, error) {
+	if _, err := analyze.ReopenRateByDescriptionLength(bucketSize); err != nil {
+		return nil, err
+	}
+	return func(tickets ...jira.JiraIssue) error {
+		rates, err := analyze.ReopenRateByDescriptionLength(bucketSize, tickets...)
+		if err != nil {
+			return err
+		}
+		var width int
+		for low := range rates {
+			if w := len(strconv.Itoa(low)); w > width {
+				width = w
+			}
+		}
+		result := make(map[string]float64, len(rates))
+		for low, rate := range rates {
+			result[fmt.Sprintf("%0*d-%d", width, low, low+bucketSize-1)] = rate * 100
+		}
+		return barchart(
+			"Reopen Rate By Description Length",
+			"Reopened tickets (%)",
+			chartPath("reopen_rate_description.png"),
+			result,
+		)
+	}, nil
+}
+
+// barchart returns and saves a barchart given a variadic number of bars.
 func barchart(title, yAxis, filepath string, vals map[string]float64) error {
 	var bars []chart.Value
+	var values []float64
 	for k, v := range vals {
 		bars = append(bars, chart.Value{
 			Label: k,
 			Value: v,
 		})
+		values = append(values, v)
+	}
+	sort.Slice(bars, func(i, j int) bool {
+		return bars[i].Label < bars[j].Label
+	})
+	if isDegenerate(values) {
+		return &RenderError{Chart: title, Points: len(values), Err: ErrDegenerateData}
 	}
 	sbc := chart.BarChart{
 		Title: title,
@@ -312,17 +1150,39 @@ func barchart(title, yAxis, filepath string, vals map[string]float64) error {
 		Bars: bars,
 	}
 
-	file, err := os.Create(filepath)
-	if err != nil {
-		return err
-	}
-
-	return sbc.Render(chart.PNG, file)
+	return save(sbc, title, len(bars), filepath)
 }
 
-func scatter(xAxis, yAxis, title, filepath string, xs []float64, ys []float64) error {
-	viridisByY := func(xr, yr chart.Range, index int, x, y float64) drawing.Color {
-		return chart.Viridis(y, yr.GetMin(), yr.GetMax())
+// scatter computes and saves a scatter plot of ys against xs along with a trend line.
+func scatter(xAxis, yAxis, title, filepath string, xs []float64, ys []float64, keys []string) error {
+	yAxis = scatterYLabel(yAxis)
+	var yRange chart.Range
+	if ScatterYMax > 0 {
+		xs, ys, keys = clampPoints(ScatterYMax, xs, ys, keys)
+		yRange = &chart.ContinuousRange{Min: math.Min(0, minOf(ys)), Max: ScatterYMax}
+	}
+	if isDegenerate(xs) || isDegenerate(ys) {
+		return &RenderError{Chart: title, Points: len(xs), Err: ErrDegenerateData}
+	}
+	colorByY := func(xr, yr chart.Range, index int, x, y float64) drawing.Color {
+		return ScatterPalette(y, yr.GetMin(), yr.GetMax())
+	}
+	if ColorByPercentile {
+		ranks := percentileRanks(ys)
+		colorByY = func(xr, yr chart.Range, index int, x, y float64) drawing.Color {
+			return ScatterPalette(ranks[index], 0, 1)
+		}
+	}
+
+	points := chart.ContinuousSeries{
+		Style: chart.Style{
+			Show:             true,
+			StrokeWidth:      chart.Disabled,
+			DotWidth:         5,
+			DotColorProvider: colorByY,
+		},
+		XValues: xs,
+		YValues: ys,
 	}
 
 	s := chart.Chart{
@@ -351,6 +1211,129 @@ func scatter(xAxis, yAxis, title, filepath string, xs []float64, ys []float64) e
 			},
 			Style: chart.Style{Show: true},
 		},
+		YAxis: chart.YAxis{
+			Name: yAxis,
+			NameStyle: chart.Style{
+				Show:     true,
+				FontSize: 20,
+			},
+			Style: chart.Style{Show: true},
+			Range: yRange,
+		},
+		Series: []chart.Series{points, trendSeries(points)},
+	}
+
+	if err := save(s, title, len(xs), filepath); err != nil {
+		return err
+	}
+	if PointSidecar && writeToFilesystem {
+		return writeSidecar(filepath, xs, ys, keys)
+	}
+	return nil
+}
+
+// SidecarPoint maps the coordinates of a scatter plot point to the key of the ticket it represents.
+type SidecarPoint struct {
+	X   float64 `json:"x"`
+	Y   float64 `json:"y"`
+	Key string  `json:"key"`
+}
+
+// writeSidecar writes, next to the chart found at chartPath, a JSON file with the same name holding an entry
+// per plotted point.
+func writeSidecar(chartPath string, xs, ys []float64, keys []string) error {
+	points := make([]SidecarPoint, len(xs))
+	for i := range xs {
+		points[i] = SidecarPoint{X: xs[i], Y: ys[i], Key: keys[i]}
+	}
+	buf, err := json.MarshalIndent(points, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := strings.TrimSuffix(chartPath, filepath.Ext(chartPath)) + ".json"
+	return ioutil.WriteFile(path, buf, 0644)
+}
+
+// percentileRanks returns the percentile rank, between 0 and 1, of each value inside vals; equal values share
+// the average of their ranks.
+func percentileRanks(vals []float64) []float64 {
+	ranks := make([]float64, len(vals))
+	if len(vals) < 2 {
+		return ranks
+	}
+	indexes := make([]int, len(vals))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		return vals[indexes[i]] < vals[indexes[j]]
+	})
+	for i := 0; i < len(indexes); {
+		j := i
+		for j+1 < len(indexes) && vals[indexes[j+1]] == vals[indexes[i]] {
+			j++
+		}
+		rank := float64(i+j) / 2 / float64(len(vals)-1)
+		for k := i; k <= j; k++ {
+			ranks[indexes[k]] = rank
+		}
+		i = j + 1
+	}
+	return ranks
+}
+
+// trendSeries returns a linear regression trend line for the given points or, if there are fewer than
+// MinPointsForTrend points, an annotation stating that there is insufficient data for a trend.
+func trendSeries(points chart.ContinuousSeries) chart.Series {
+	if len(points.XValues) >= MinPointsForTrend {
+		return &chart.LinearRegressionSeries{
+			Style: chart.Style{
+				Show:        true,
+				StrokeWidth: 3,
+			},
+			InnerSeries: points,
+		}
+	}
+	annotation := chart.AnnotationSeries{
+		Style: chart.Style{Show: true},
+	}
+	if len(points.XValues) > 0 {
+		annotation.Annotations = []chart.Value2{{
+			XValue: points.XValues[0],
+			YValue: points.YValues[0],
+			Label:  "insufficient data for trend",
+		}}
+	}
+	return annotation
+}
+
+// line computes and saves a line chart of values over time, drawing any extra series on top of it.
+func line(title, yAxis, filepath string, dates []time.Time, vals []float64, extra ...chart.Series) error {
+	if len(dates) < 2 {
+		return &RenderError{Chart: title, Points: len(dates), Err: ErrDegenerateData}
+	}
+	c := chart.Chart{
+		Title: title,
+		TitleStyle: chart.Style{
+			Show: true,
+			Padding: chart.Box{
+				Bottom: 60,
+			},
+			FontSize: 25,
+		},
+		Background: chart.Style{
+			Show: true,
+			Padding: chart.Box{
+				Top:   50,
+				Right: 30,
+			},
+		},
+		Width:  2048,
+		Height: 1024,
+		XAxis: chart.XAxis{
+			Style:          chart.Style{Show: true},
+			ValueFormatter: chart.TimeDateValueFormatter,
+		},
 		YAxis: chart.YAxis{
 			Name: yAxis,
 			NameStyle: chart.Style{
@@ -360,23 +1343,100 @@ func scatter(xAxis, yAxis, title, filepath string, xs []float64, ys []float64) e
 			Style: chart.Style{Show: true},
 		},
 		Series: []chart.Series{
-			chart.ContinuousSeries{
+			chart.TimeSeries{
 				Style: chart.Style{
-					Show:             true,
-					StrokeWidth:      chart.Disabled,
-					DotWidth:         5,
-					DotColorProvider: viridisByY,
+					Show:        true,
+					StrokeWidth: 3,
 				},
-				XValues: xs,
-				YValues: ys,
+				XValues: dates,
+				YValues: vals,
 			},
 		},
 	}
+	c.Series = append(c.Series, extra...)
+	return save(c, title, len(dates), filepath)
+}
 
-	file, err := os.Create(filepath)
-	if err != nil {
-		return err
+// intsToFloats converts a slice of ints into a slice of float64s.
+func intsToFloats(ints []int) []float64 {
+	floats := make([]float64, len(ints))
+	for i, v := range ints {
+		floats[i] = float64(v)
+	}
+	return floats
+}
+
+// renderable defines a chart which can be rendered as an image.
+type renderable interface {
+	Render(chart.RendererProvider, io.Writer) error
+}
+
+// save renders a chart as PNG and writes it to the chart store, if any, and to path on the filesystem.
+// Render failures, including panics inside go-chart, are returned as a *RenderError.
+func save(c renderable, name string, points int, path string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &RenderError{Chart: name, Points: points, Err: fmt.Errorf("%v", r)}
+		}
+	}()
+	var buf bytes.Buffer
+	if err := c.Render(chart.PNG, &buf); err != nil {
+		return &RenderError{Chart: name, Points: points, Err: err}
+	}
+	if chartStore != nil {
+		if err := chartStore.PutChart(filepath.Base(path), buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	if !writeToFilesystem {
+		return nil
+	}
+	return ioutil.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// scatterYLabel returns the Y axis label of a scatter plot: ScatterYLabel if set or the plot's default otherwise.
+func scatterYLabel(defaultLabel string) string {
+	if ScatterYLabel != "" {
+		return ScatterYLabel
+	}
+	return defaultLabel
+}
+
+// clampPoints omits the points, and their keys, whose y value is above yMax.
+func clampPoints(yMax float64, xs, ys []float64, keys []string) ([]float64, []float64, []string) {
+	var keptXs, keptYs []float64
+	var keptKeys []string
+	for i := range ys {
+		if ys[i] > yMax {
+			continue
+		}
+		keptXs = append(keptXs, xs[i])
+		keptYs = append(keptYs, ys[i])
+		if i < len(keys) {
+			keptKeys = append(keptKeys, keys[i])
+		}
 	}
+	return keptXs, keptYs, keptKeys
+}
 
-	return s.Render(chart.PNG, file)
+// minOf returns the smallest of vals, or 0 if there are none.
+func minOf(vals []float64) float64 {
+	if len(vals) == 0 {
+		return 0
+	}
+	min := vals[0]
+	for _, v := range vals[1:] {
+		min = math.Min(min, v)
+	}
+	return min
+}
+
+// isDegenerate returns whether there are no values or all of them are identical.
+func isDegenerate(vals []float64) bool {
+	for _, v := range vals {
+		if v != vals[0] {
+			return false
+		}
+	}
+	return true
 }
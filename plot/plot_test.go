@@ -0,0 +1,478 @@
+package plot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nclandrei/ticketguru/analyze"
+	"github.com/nclandrei/ticketguru/jira"
+	"github.com/wcharczuk/go-chart"
+	"github.com/wcharczuk/go-chart/drawing"
+)
+
+// useTempOutputDir points OutputDir to a new temporary directory and returns a function restoring it and
+// removing the directory.
+func useTempOutputDir(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "plot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := OutputDir
+	OutputDir = dir
+	return func() {
+		OutputDir = previous
+		os.RemoveAll(dir)
+	}
+}
+
+// assertChart fails the test if no chart named name was written inside OutputDir.
+func assertChart(t *testing.T, name string) {
+	t.Helper()
+	info, err := os.Stat(filepath.Join(OutputDir, name))
+	if err != nil {
+		t.Fatalf("chart %s was not written: %v", name, err)
+	}
+	if info.Size() == 0 {
+		t.Errorf("chart %s is empty", name)
+	}
+}
+
+// closedTicket returns a high priority ticket closed after the given number of hours.
+func closedTicket(key string, hours float64, attachments ...jira.AttachmentType) jira.JiraIssue {
+	ticket := jira.JiraIssue{Key: key, TimeToClose: hours}
+	ticket.Fields.Priority.ID = "1"
+	for _, a := range attachments {
+		ticket.Fields.Attachments = append(ticket.Fields.Attachments, jira.Attachment{Type: a})
+	}
+	return ticket
+}
+
+func TestAttachmentLabel(t *testing.T) {
+	for typ, want := range map[jira.AttachmentType]string{
+		jira.CodeAttachment:        "Code",
+		jira.ImageAttachment:       "Image",
+		jira.SpreadsheetAttachment: "Spreadsheet",
+		jira.VideoAttachment:       "Video",
+		jira.OtherAttachment:       "Other",
+		jira.AttachmentType(0):     "Other",
+		jira.AttachmentType(42):    "Other",
+	} {
+		if got := attachmentLabel(typ); got != want {
+			t.Errorf("attachmentLabel(%d) = %q, want %q", typ, got, want)
+		}
+	}
+}
+
+func TestAttachmentPercentiles(t *testing.T) {
+	defer useTempOutputDir(t)()
+	err := AttachmentPercentiles(
+		closedTicket("AP-1", 10, jira.ImageAttachment, jira.CodeAttachment),
+		closedTicket("AP-2", 30, jira.ImageAttachment),
+		closedTicket("AP-3", 50, jira.VideoAttachment),
+		closedTicket("AP-4", 0, jira.ArchiveAttachment),
+	)
+	if err != nil {
+		t.Fatalf("could not plot attachment percentiles: %v", err)
+	}
+	assertChart(t, "attachment_percentiles.png")
+}
+
+func TestMeanTimeToCloseWithoutTimes(t *testing.T) {
+	if got := meanTimeToClose(nil); got != 0 {
+		t.Errorf("meanTimeToClose(nil) = %v, want 0", got)
+	}
+	if got := meanTimeToClose([]float64{2, 4}); got != 3 {
+		t.Errorf("meanTimeToClose([2 4]) = %v, want 3", got)
+	}
+
+	defer func(previous float64) { TrimPct = previous }(TrimPct)
+	TrimPct = 0.25
+	if got := meanTimeToClose([]float64{1, 2, 4, 100}); got != 3 {
+		t.Errorf("meanTimeToClose([1 2 4 100]) trimmed by a quarter = %v, want 3", got)
+	}
+}
+
+func TestAttachmentsWithMissingTypes(t *testing.T) {
+	defer useTempOutputDir(t)()
+	// no ticket lacks attachments and the only types present are unknown ones, so most averages have no values.
+	err := Attachments(
+		closedTicket("A-1", 10, jira.AttachmentType(42)),
+		closedTicket("A-2", 30, jira.OtherAttachment, jira.CodeAttachment),
+	)
+	if err != nil {
+		t.Fatalf("could not plot attachments: %v", err)
+	}
+	assertChart(t, "attachments.png")
+}
+
+func TestAttachmentTimesCountTicketOncePerType(t *testing.T) {
+	// the first ticket holding two images must not weigh twice in the Image average.
+	times := attachmentTimes(
+		closedTicket("A-1", 10, jira.ImageAttachment, jira.ImageAttachment),
+		closedTicket("A-2", 40, jira.ImageAttachment, jira.CodeAttachment),
+	)
+	if times["Image"] != 25 {
+		t.Errorf("Image average = %v, want 25", times["Image"])
+	}
+	if times["Code"] != 40 {
+		t.Errorf("Code average = %v, want 40", times["Code"])
+	}
+}
+
+func TestWordinessAnalysis(t *testing.T) {
+	if _, err := WordinessAnalysis("labels"); err == nil {
+		t.Error("expected an error for an unknown wordiness field")
+	}
+
+	defer useTempOutputDir(t)()
+	wordiness, err := WordinessAnalysis("summary+description")
+	if err != nil {
+		t.Fatalf("could not create wordiness plot: %v", err)
+	}
+	var tickets []jira.JiraIssue
+	for i, summary := range []string{"crash", "crash on start", "login fails with long passwords"} {
+		ticket := closedTicket("W-1", float64(10*(i+1)))
+		ticket.Fields.Summary = summary
+		tickets = append(tickets, ticket)
+	}
+	if err := wordiness(tickets...); err != nil {
+		t.Fatalf("could not plot wordiness: %v", err)
+	}
+	assertChart(t, "wordiness_summary_description.png")
+}
+
+// memoryStore holds the charts put into it in memory.
+type memoryStore struct {
+	mu     sync.Mutex
+	charts map[string][]byte
+}
+
+// PutChart stores the chart under its name.
+func (s *memoryStore) PutChart(name string, png []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.charts == nil {
+		s.charts = make(map[string][]byte)
+	}
+	s.charts[name] = png
+	return nil
+}
+
+func TestStoreCharts(t *testing.T) {
+	defer useTempOutputDir(t)()
+	store := &memoryStore{}
+	StoreCharts(store, false)
+	defer StoreCharts(nil, true)
+
+	if err := Attachments(closedTicket("C-1", 10), closedTicket("C-2", 30, jira.ImageAttachment)); err != nil {
+		t.Fatalf("could not plot attachments: %v", err)
+	}
+	png, ok := store.charts["attachments.png"]
+	if !ok {
+		t.Fatal("chart was not put into the store")
+	}
+	if !bytes.HasPrefix(png, []byte("\x89PNG")) {
+		t.Errorf("stored chart is not a PNG")
+	}
+	if _, err := os.Stat(filepath.Join(OutputDir, "attachments.png")); !os.IsNotExist(err) {
+		t.Errorf("chart was written to the filesystem although files are not kept")
+	}
+}
+
+// TestRenderAll is meant to be run with -race, as it renders several charts from the same tickets at once.
+func TestRenderAll(t *testing.T) {
+	defer useTempOutputDir(t)()
+	detailed := closedTicket("R-2", 30, jira.ImageAttachment)
+	detailed.HasStepsToReproduce = true
+	detailed.HasStackTrace = true
+	tickets := []jira.JiraIssue{closedTicket("R-1", 10), detailed}
+
+	plots := []Plot{Attachments, StepsToReproduce, Stacktraces}
+	if err := RenderAll(len(plots), plots, tickets...); err != nil {
+		t.Fatalf("could not render charts: %v", err)
+	}
+	for _, name := range []string{"attachments.png", "steps_to_reproduce.png", "stack_traces.png"} {
+		assertChart(t, name)
+	}
+
+	failing := func(...jira.JiraIssue) error { return fmt.Errorf("no data") }
+	err := RenderAll(2, append(plots, failing, failing), tickets...)
+	errs, ok := err.(RenderErrors)
+	if !ok || len(errs) != 2 {
+		t.Errorf("RenderAll error = %v, want the 2 errors of the failing charts", err)
+	}
+}
+
+func TestNewRunDir(t *testing.T) {
+	base, err := ioutil.TempDir("", "runs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(base)
+
+	first := time.Date(2018, 3, 1, 10, 0, 0, 0, time.UTC)
+	var dirs []string
+	for _, now := range []time.Time{first, first.Add(time.Hour)} {
+		dir, err := NewRunDir(base, time.RFC3339, now)
+		if err != nil {
+			t.Fatalf("could not create run directory: %v", err)
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			t.Fatalf("run directory %s was not created: %v", dir, err)
+		}
+		dirs = append(dirs, dir)
+	}
+	if want := filepath.Join(base, "2018-03-01T11:00:00Z"); dirs[0] == dirs[1] || dirs[1] != want {
+		t.Errorf("run directories = %v, want two distinct ones, the newer being %s", dirs, want)
+	}
+	target, err := os.Readlink(filepath.Join(base, LatestRunLink))
+	if err != nil {
+		t.Fatalf("could not read latest link: %v", err)
+	}
+	if target != filepath.Base(dirs[1]) {
+		t.Errorf("latest links to %s, want the newer run %s", target, filepath.Base(dirs[1]))
+	}
+}
+
+func TestSentimentTrajectory(t *testing.T) {
+	defer useTempOutputDir(t)()
+	day := func(d int) jira.Time {
+		return jira.Time(time.Date(2018, 3, d, 12, 0, 0, 0, time.UTC))
+	}
+	ticket := jira.JiraIssue{Key: "T-1"}
+	ticket.Fields.Created = day(1)
+	ticket.Fields.Comments.Comments = []jira.Comment{
+		{Body: "still broken", Created: day(2)},
+		{Body: "this is getting ridiculous", Created: day(4)},
+		{Body: "thanks, works now", Created: day(6)},
+	}
+	ticket.Changelog.Histories = []jira.ChangelogHistory{{
+		Created: day(5),
+		Items:   []jira.ChangelogHistoryItem{{Field: "status", FromString: "Open", ToString: "Resolved"}},
+	}}
+
+	if err := SentimentTrajectory(ticket, []float64{-0.2, -0.8, 0.9}); err != nil {
+		t.Fatalf("could not plot sentiment trajectory: %v", err)
+	}
+	assertChart(t, "sentiment_trajectory_T-1.png")
+
+	if _, ok := SentimentTrajectory(ticket, []float64{0.5}).(*RenderError); !ok {
+		t.Error("expected a render error for scores not matching the comments")
+	}
+}
+
+func TestTrendSeries(t *testing.T) {
+	previous := MinPointsForTrend
+	MinPointsForTrend = 3
+	defer func() { MinPointsForTrend = previous }()
+
+	few := chart.ContinuousSeries{XValues: []float64{1, 2}, YValues: []float64{3, 4}}
+	annotation, ok := trendSeries(few).(chart.AnnotationSeries)
+	if !ok {
+		t.Fatalf("trend of 2 points is a %T, want an annotation", trendSeries(few))
+	}
+	if len(annotation.Annotations) != 1 || annotation.Annotations[0].Label != "insufficient data for trend" {
+		t.Errorf("trend of 2 points annotated with %+v", annotation.Annotations)
+	}
+
+	enough := chart.ContinuousSeries{XValues: []float64{1, 2, 3}, YValues: []float64{3, 4, 5}}
+	if _, ok := trendSeries(enough).(*chart.LinearRegressionSeries); !ok {
+		t.Errorf("trend of 3 points is a %T, want a linear regression", trendSeries(enough))
+	}
+
+	if empty, ok := trendSeries(chart.ContinuousSeries{}).(chart.AnnotationSeries); !ok || len(empty.Annotations) != 0 {
+		t.Errorf("trend without points = %+v, want an empty annotation", trendSeries(chart.ContinuousSeries{}))
+	}
+}
+
+func TestPercentileRanksSpreadSkewedColors(t *testing.T) {
+	ys := []float64{4, 1, 1000, 2, 3, 3}
+	ranks := percentileRanks(ys)
+	want := []float64{0.8, 0, 1, 0.2, 0.5, 0.5}
+	for i := range want {
+		if math.Abs(ranks[i]-want[i]) > 1e-9 {
+			t.Fatalf("percentileRanks(%v) = %v, want %v", ys, ranks, want)
+		}
+	}
+
+	// the outlier compresses the absolute colors of all other points into one, unlike their percentile colors.
+	absolute := make(map[drawing.Color]bool)
+	percentile := make(map[drawing.Color]bool)
+	for i, y := range ys {
+		absolute[chart.Viridis(y, 1, 1000)] = true
+		percentile[chart.Viridis(ranks[i], 0, 1)] = true
+	}
+	if len(absolute) != 2 {
+		t.Errorf("absolute coloring assigned %d distinct colors, want 2", len(absolute))
+	}
+	if len(percentile) != 5 {
+		t.Errorf("percentile coloring assigned %d distinct colors, want 5", len(percentile))
+	}
+}
+
+func TestScatterWithIdenticalValues(t *testing.T) {
+	defer useTempOutputDir(t)()
+	err := scatter("Words", "Time-To-Close (hours)", "Identical", chartPath("identical.png"),
+		[]float64{1, 2, 3}, []float64{5, 5, 5}, []string{"I-1", "I-2", "I-3"})
+	renderErr, ok := err.(*RenderError)
+	if !ok || renderErr.Err != ErrDegenerateData {
+		t.Fatalf("scatter of identical values returned %v, want a degenerate data render error", err)
+	}
+	if renderErr.Chart != "Identical" || renderErr.Points != 3 {
+		t.Errorf("render error describes chart %q with %d points, want Identical with 3", renderErr.Chart,
+			renderErr.Points)
+	}
+}
+
+func TestControlChart(t *testing.T) {
+	defer useTempOutputDir(t)()
+	var tickets []jira.JiraIssue
+	for i := 0; i < 20; i++ {
+		ticket := closedTicket(fmt.Sprintf("CC-%d", i), 10+float64(i%3))
+		if i == 7 {
+			ticket.TimeToClose = 200
+		}
+		ticket.Fields.Created = jira.Time(time.Date(2018, 3, 1+i, 0, 0, 0, 0, time.UTC))
+		tickets = append(tickets, ticket)
+	}
+	if err := ControlChart(tickets...); err != nil {
+		t.Fatalf("could not plot control chart: %v", err)
+	}
+	assertChart(t, "control_chart.png")
+
+	if _, ok := ControlChart(tickets[0]).(*RenderError); !ok {
+		t.Error("expected a render error plotting the control chart of a single ticket")
+	}
+}
+
+func TestPointSidecar(t *testing.T) {
+	defer useTempOutputDir(t)()
+	PointSidecar = true
+	defer func() { PointSidecar = false }()
+
+	xs, ys, keys := []float64{1, 2, 3}, []float64{10, 30, 20}, []string{"P-1", "P-2", "P-3"}
+	if err := scatter("Words", "Time-To-Close (hours)", "Sidecar", chartPath("sidecar.png"), xs, ys, keys); err != nil {
+		t.Fatalf("could not plot scatter: %v", err)
+	}
+	buf, err := ioutil.ReadFile(filepath.Join(OutputDir, "sidecar.json"))
+	if err != nil {
+		t.Fatalf("sidecar was not written: %v", err)
+	}
+	var points []SidecarPoint
+	if err := json.Unmarshal(buf, &points); err != nil {
+		t.Fatalf("could not decode sidecar: %v", err)
+	}
+	want := []SidecarPoint{{X: 1, Y: 10, Key: "P-1"}, {X: 2, Y: 30, Key: "P-2"}, {X: 3, Y: 20, Key: "P-3"}}
+	if !reflect.DeepEqual(points, want) {
+		t.Errorf("sidecar points = %+v, want %+v", points, want)
+	}
+}
+
+func TestScatterYAxisOptions(t *testing.T) {
+	xs, ys, keys := clampPoints(100, []float64{1, 2, 3}, []float64{50, 900, 100}, []string{"Y-1", "Y-2", "Y-3"})
+	if !reflect.DeepEqual(xs, []float64{1, 3}) || !reflect.DeepEqual(ys, []float64{50, 100}) ||
+		!reflect.DeepEqual(keys, []string{"Y-1", "Y-3"}) {
+		t.Errorf("clampPoints kept %v, %v, %v; want the points at or below 100", xs, ys, keys)
+	}
+
+	// the sidecar lists the plotted points, so it tells the clamped ones were omitted from the chart.
+	defer useTempOutputDir(t)()
+	ScatterYMax, PointSidecar = 100, true
+	defer func() { ScatterYMax, PointSidecar = 0, false }()
+	err := scatter("Words", "Time-To-Close (hours)", "Clamped", chartPath("clamped.png"),
+		[]float64{1, 2, 3, 4}, []float64{50, 900, 100, 20}, []string{"Y-1", "Y-2", "Y-3", "Y-4"})
+	if err != nil {
+		t.Fatalf("could not plot scatter: %v", err)
+	}
+	buf, err := ioutil.ReadFile(filepath.Join(OutputDir, "clamped.json"))
+	if err != nil {
+		t.Fatalf("sidecar was not written: %v", err)
+	}
+	var points []SidecarPoint
+	if err := json.Unmarshal(buf, &points); err != nil {
+		t.Fatalf("could not decode sidecar: %v", err)
+	}
+	if len(points) != 3 {
+		t.Errorf("plotted points = %+v, want the 3 points at or below 100", points)
+	}
+
+	if got := scatterYLabel("Time-To-Close (hours)"); got != "Time-To-Close (hours)" {
+		t.Errorf("default Y axis label = %q", got)
+	}
+	ScatterYLabel = "Business hours to close"
+	defer func() { ScatterYLabel = "" }()
+	if got := scatterYLabel("Time-To-Close (hours)"); got != ScatterYLabel {
+		t.Errorf("Y axis label = %q, want %q", got, ScatterYLabel)
+	}
+}
+
+func TestGrayscalePalette(t *testing.T) {
+	palette, err := PaletteByName("Grayscale")
+	if err != nil {
+		t.Fatalf("could not find grayscale palette: %v", err)
+	}
+	previous := ScatterPalette
+	ScatterPalette = palette
+	defer func() { ScatterPalette = previous }()
+
+	for _, v := range []float64{-5, 0, 2.5, 7, 10, 15} {
+		c := ScatterPalette(v, 0, 10)
+		if c.R != c.G || c.G != c.B {
+			t.Errorf("grayscale color of %v = %+v, want an achromatic color", v, c)
+		}
+	}
+	if dark, light := ScatterPalette(0, 0, 10), ScatterPalette(10, 0, 10); dark.R >= light.R || light.R == 255 {
+		t.Errorf("grayscale shades range from %+v to %+v, want dark to off-white", dark, light)
+	}
+	if _, err := PaletteByName("rainbow"); err == nil {
+		t.Error("expected an error for an unknown palette")
+	}
+
+	defer useTempOutputDir(t)()
+	err = scatter("Words", "Time-To-Close (hours)", "Grayscale", chartPath("grayscale.png"),
+		[]float64{1, 2, 3}, []float64{4, 8, 6}, []string{"G-1", "G-2", "G-3"})
+	if err != nil {
+		t.Fatalf("could not render grayscale scatter: %v", err)
+	}
+	assertChart(t, "grayscale.png")
+}
+
+func TestSampleByPriority(t *testing.T) {
+	// one ticket out of ten is a blocker, but blockers weigh nine times as much as trivial tickets.
+	var tickets []jira.JiraIssue
+	for i := 0; i < 1000; i++ {
+		ticket := jira.JiraIssue{Key: fmt.Sprintf("S-%d", i)}
+		ticket.Fields.Priority.ID = "5"
+		if i%10 == 0 {
+			ticket.Fields.Priority.ID = "1"
+		}
+		tickets = append(tickets, ticket)
+	}
+	weights := analyze.PriorityWeights{"1": 9, "5": 1}
+
+	sample := SampleByPriority(weights, 100, 42, tickets...)
+	if len(sample) != 100 {
+		t.Fatalf("sampled %d tickets, want 100", len(sample))
+	}
+	var blockers int
+	for _, ticket := range sample {
+		if ticket.Fields.Priority.ID == "1" {
+			blockers++
+		}
+	}
+	if blockers <= 25 {
+		t.Errorf("sampled %d blockers out of 100, want them over-represented compared to their 10%% share", blockers)
+	}
+	if again := SampleByPriority(weights, 100, 42, tickets...); !reflect.DeepEqual(again, sample) {
+		t.Error("sampling with the same seed is not reproducible")
+	}
+}
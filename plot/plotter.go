@@ -0,0 +1,129 @@
+package plot
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// Plotter incrementally feeds charts with new data, caching the tickets and points they were already drawn
+// from so that each Flush only re-renders the charts affected by the data added since the previous one.
+// A Plotter is safe for concurrent use.
+type Plotter struct {
+	mu sync.Mutex
+
+	plots    map[string]registeredPlot
+	scatters map[string]*scatterSeries
+
+	tickets map[string]int
+	ordered []jira.JiraIssue
+	dirty   map[string]bool
+}
+
+// registeredPlot holds a plotting function alongside the predicate telling which tickets affect its chart.
+type registeredPlot struct {
+	plot    Plot
+	affects func(jira.JiraIssue) bool
+}
+
+// scatterSeries holds the cached points of a scatter plot fed through AddPoint.
+type scatterSeries struct {
+	title, xAxis, yAxis string
+	xs, ys              []float64
+	keys                []string
+}
+
+// NewPlotter returns a Plotter without any registered chart.
+func NewPlotter() *Plotter {
+	return &Plotter{
+		plots:    make(map[string]registeredPlot),
+		scatters: make(map[string]*scatterSeries),
+		tickets:  make(map[string]int),
+		dirty:    make(map[string]bool),
+	}
+}
+
+// Register adds a chart, drawn by f from all the tickets added so far, under name. Only the tickets for which
+// affects returns true mark the chart for re-rendering; every ticket does so if affects is nil.
+func (p *Plotter) Register(name string, f Plot, affects func(jira.JiraIssue) bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.plots[name] = registeredPlot{plot: f, affects: affects}
+	if len(p.ordered) > 0 {
+		p.dirty[name] = true
+	}
+}
+
+// RegisterScatter adds a scatter plot, written to name.png and fed through AddPoint, under name.
+func (p *Plotter) RegisterScatter(name, title, xAxis, yAxis string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.scatters[name] = &scatterSeries{title: title, xAxis: xAxis, yAxis: yAxis}
+}
+
+// AddTicket caches the given tickets, replacing the cached versions of tickets already added under the same key,
+// and marks the charts they affect for re-rendering. A replaced ticket marks the charts affected by either of its
+// versions, so that a chart it drops out of is drawn again without it.
+func (p *Plotter) AddTicket(tickets ...jira.JiraIssue) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, ticket := range tickets {
+		versions := []jira.JiraIssue{ticket}
+		if i, ok := p.tickets[ticket.Key]; ok {
+			versions = append(versions, p.ordered[i])
+			p.ordered[i] = ticket
+		} else {
+			p.tickets[ticket.Key] = len(p.ordered)
+			p.ordered = append(p.ordered, ticket)
+		}
+		for name, rp := range p.plots {
+			for _, version := range versions {
+				if rp.affects == nil || rp.affects(version) {
+					p.dirty[name] = true
+				}
+			}
+		}
+	}
+}
+
+// AddPoint appends a point, belonging to the ticket with the given key, to the scatter plot registered under
+// name and marks it for re-rendering.
+func (p *Plotter) AddPoint(name string, x, y float64, key string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.scatters[name]
+	if !ok {
+		return fmt.Errorf("no scatter plot registered as %s", name)
+	}
+	s.xs = append(s.xs, x)
+	s.ys = append(s.ys, y)
+	s.keys = append(s.keys, key)
+	p.dirty[name] = true
+	return nil
+}
+
+// Flush re-renders the charts affected by the data added since the previous Flush, returning the errors of all
+// the failed ones as RenderErrors. Failed charts are retried on the next Flush.
+func (p *Plotter) Flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var errs RenderErrors
+	for name := range p.dirty {
+		var err error
+		if rp, ok := p.plots[name]; ok {
+			err = rp.plot(p.ordered...)
+		} else if s, ok := p.scatters[name]; ok {
+			err = scatter(s.xAxis, s.yAxis, s.title, chartPath(name+".png"), s.xs, s.ys, s.keys)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delete(p.dirty, name)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package plot
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+func TestPlotterAddPointMatchesBatchRender(t *testing.T) {
+	defer useTempOutputDir(t)()
+	xs, ys := []float64{1, 2, 3, 4}, []float64{10, 40, 20, 30}
+	keys := []string{"P-1", "P-2", "P-3", "P-4"}
+
+	p := NewPlotter()
+	p.RegisterScatter("incremental", "Words", "Number of words", "Time-To-Close (hours)")
+	for i := range xs {
+		if err := p.AddPoint("incremental", xs[i], ys[i], keys[i]); err != nil {
+			t.Fatalf("could not add point: %v", err)
+		}
+		// the chart cannot be drawn until points differ, so only the later flushes must succeed.
+		if err := p.Flush(); err != nil && i > 0 {
+			t.Fatalf("could not flush after %d points: %v", i+1, err)
+		}
+	}
+	err := scatter("Number of words", "Time-To-Close (hours)", "Words", chartPath("batch.png"), xs, ys, keys)
+	if err != nil {
+		t.Fatalf("could not plot batch scatter: %v", err)
+	}
+
+	incremental, err := ioutil.ReadFile(filepath.Join(OutputDir, "incremental.png"))
+	if err != nil {
+		t.Fatalf("incremental chart was not written: %v", err)
+	}
+	batch, err := ioutil.ReadFile(filepath.Join(OutputDir, "batch.png"))
+	if err != nil {
+		t.Fatalf("batch chart was not written: %v", err)
+	}
+	if !bytes.Equal(incremental, batch) {
+		t.Error("incrementally fed chart differs from the batch render of the same points")
+	}
+
+	if err := p.AddPoint("unknown", 1, 1, "P-5"); err == nil {
+		t.Error("expected an error adding a point to an unregistered scatter plot")
+	}
+}
+
+func TestPlotterFlushesAffectedCharts(t *testing.T) {
+	renders := make(map[string][]int)
+	counting := func(name string, err error) Plot {
+		return func(tickets ...jira.JiraIssue) error {
+			renders[name] = append(renders[name], len(tickets))
+			return err
+		}
+	}
+	p := NewPlotter()
+	p.Register("all", counting("all", nil), nil)
+	p.Register("closed", counting("closed", nil), func(t jira.JiraIssue) bool { return t.TimeToClose > 0 })
+
+	p.AddTicket(closedTicket("F-1", 10), jira.JiraIssue{Key: "F-2"})
+	if err := p.Flush(); err != nil {
+		t.Fatalf("could not flush: %v", err)
+	}
+	// an open ticket only affects the chart drawn from every ticket, and a replaced ticket is not counted twice.
+	p.AddTicket(jira.JiraIssue{Key: "F-3"}, jira.JiraIssue{Key: "F-2"})
+	if err := p.Flush(); err != nil {
+		t.Fatalf("could not flush: %v", err)
+	}
+	if want := map[string][]int{"all": {2, 3}, "closed": {2}}; !reflect.DeepEqual(renders, want) {
+		t.Errorf("renders = %v, want %v", renders, want)
+	}
+	// a closed ticket reopened no longer satisfies the predicate, but the chart it was drawn in is still stale.
+	p.AddTicket(jira.JiraIssue{Key: "F-1"})
+	if err := p.Flush(); err != nil {
+		t.Fatalf("could not flush: %v", err)
+	}
+	if want := map[string][]int{"all": {2, 3, 3}, "closed": {2, 3}}; !reflect.DeepEqual(renders, want) {
+		t.Errorf("renders after reopening a ticket = %v, want %v", renders, want)
+	}
+
+	// a chart registered once tickets were added is rendered on the next flush, and retried until it succeeds.
+	p.Register("late", counting("late", fmt.Errorf("no data")), nil)
+	for i := 0; i < 2; i++ {
+		if errs, ok := p.Flush().(RenderErrors); !ok || len(errs) != 1 {
+			t.Errorf("flush %d returned %v, want the error of the failing chart", i, errs)
+		}
+	}
+	if got := renders["late"]; len(got) != 2 {
+		t.Errorf("failing chart was rendered %d times, want 2", len(got))
+	}
+}
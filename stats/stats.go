@@ -3,6 +3,7 @@ package stats
 import (
 	"errors"
 	"github.com/dgryski/go-onlinestats"
+	"github.com/nclandrei/ticketguru/analyze"
 	"github.com/nclandrei/ticketguru/jira"
 	"math"
 )
@@ -69,8 +70,36 @@ func Attachments(tickets ...jira.JiraIssue) (*TTestResult, error) {
 
 // StepsToReproduce performs Welch's T Test on steps to reproduce presence or not for all tickets.
 func StepsToReproduce(tickets ...jira.JiraIssue) (*TTestResult, error) {
-	var withTimes stats
-	var withoutTimes stats
+	withTimes, withoutTimes := stepsToReproduceSamples(tickets...)
+	return twoSampleWelchTTest(withTimes, withoutTimes)
+}
+
+// EffectOfSteps returns the mean times-to-close of high priority tickets with and without steps to reproduce,
+// the percentage by which the former differs from the latter and the p value of Welch's T Test on the two
+// groups. Means of empty groups, and the values depending on them, are NaN; so is the p value whenever the
+// test cannot be computed, e.g. as either group has fewer than two tickets.
+func EffectOfSteps(tickets ...jira.JiraIssue) (withMean, withoutMean, pctDifference, significance float64) {
+	withTimes, withoutTimes := stepsToReproduceSamples(tickets...)
+	withMean, withoutMean = math.NaN(), math.NaN()
+	if withTimes.Len() > 0 {
+		withMean = withTimes.Mean()
+	}
+	if withoutTimes.Len() > 0 {
+		withoutMean = withoutTimes.Mean()
+	}
+	pctDifference = math.NaN()
+	if !math.IsNaN(withMean) && !math.IsNaN(withoutMean) && withoutMean != 0 {
+		pctDifference = (withMean - withoutMean) / withoutMean * 100
+	}
+	significance = math.NaN()
+	if result, err := twoSampleWelchTTest(withTimes, withoutTimes); err == nil {
+		significance = result.P
+	}
+	return withMean, withoutMean, pctDifference, significance
+}
+
+// stepsToReproduceSamples splits the times-to-close of high priority tickets by steps to reproduce presence.
+func stepsToReproduceSamples(tickets ...jira.JiraIssue) (withTimes, withoutTimes stats) {
 	for _, t := range tickets {
 		highPriority := jira.IsHighPriority(t)
 		if t.TimeToClose <= 0 ||
@@ -84,7 +113,7 @@ func StepsToReproduce(tickets ...jira.JiraIssue) (*TTestResult, error) {
 			withoutTimes = append(withoutTimes, t.TimeToClose)
 		}
 	}
-	return twoSampleWelchTTest(withTimes, withoutTimes)
+	return withTimes, withoutTimes
 }
 
 // Stacktraces performs Welch's T Test on stack traces presence or not for all tickets.
@@ -113,12 +142,13 @@ func CommentsComplexity(tickets ...jira.JiraIssue) *SpearmanResult {
 	var times stats
 	for _, t := range tickets {
 		highPriority := jira.IsHighPriority(t)
+		comments := analyze.CommentWords(t)
 		if highPriority &&
 			t.TimeToClose > 0 &&
 			t.TimeToClose < jira.MaxTimeToCloseH &&
-			t.CommentWordsCount > 0 &&
-			t.CommentWordsCount < jira.MaxCommWordCount {
-			comms = append(comms, float64(t.CommentWordsCount))
+			comments > 0 &&
+			comments < jira.MaxCommWordCount {
+			comms = append(comms, float64(comments))
 			times = append(times, t.TimeToClose)
 		}
 	}
@@ -131,18 +161,57 @@ func FieldsComplexity(tickets ...jira.JiraIssue) *SpearmanResult {
 	var times stats
 	for _, t := range tickets {
 		highPriority := jira.IsHighPriority(t)
+		words := analyze.SummaryDescWords(t)
 		if highPriority &&
 			t.TimeToClose > 0 &&
 			t.TimeToClose <= jira.MaxTimeToCloseH &&
-			t.SummaryDescWordsCount > 0 &&
-			t.SummaryDescWordsCount < jira.MaxSummaryDescWordCount {
-			fields = append(fields, float64(t.SummaryDescWordsCount))
+			words > 0 &&
+			words < jira.MaxSummaryDescWordCount {
+			fields = append(fields, float64(words))
 			times = append(times, t.TimeToClose)
 		}
 	}
 	return twoSampleSpearmanRTest(fields, times)
 }
 
+// SummaryComplexity performs Spearman R's test on the complexity of summary and times-to-close.
+func SummaryComplexity(tickets ...jira.JiraIssue) *SpearmanResult {
+	var counts stats
+	var times stats
+	for _, t := range tickets {
+		highPriority := jira.IsHighPriority(t)
+		words := analyze.SummaryWords(t)
+		if highPriority &&
+			t.TimeToClose > 0 &&
+			t.TimeToClose <= jira.MaxTimeToCloseH &&
+			words > 0 &&
+			words < jira.MaxSummaryDescWordCount {
+			counts = append(counts, float64(words))
+			times = append(times, t.TimeToClose)
+		}
+	}
+	return twoSampleSpearmanRTest(counts, times)
+}
+
+// DescriptionComplexity performs Spearman R's test on the complexity of description and times-to-close.
+func DescriptionComplexity(tickets ...jira.JiraIssue) *SpearmanResult {
+	var counts stats
+	var times stats
+	for _, t := range tickets {
+		highPriority := jira.IsHighPriority(t)
+		words := analyze.DescriptionWords(t)
+		if highPriority &&
+			t.TimeToClose > 0 &&
+			t.TimeToClose <= jira.MaxTimeToCloseH &&
+			words > 0 &&
+			words < jira.MaxSummaryDescWordCount {
+			counts = append(counts, float64(words))
+			times = append(times, t.TimeToClose)
+		}
+	}
+	return twoSampleSpearmanRTest(counts, times)
+}
+
 // Sentiment performs Spearman R's test on sentiment scores and times-to-close.
 func Sentiment(tickets ...jira.JiraIssue) *SpearmanResult {
 	var scores stats
@@ -178,6 +247,54 @@ func Grammar(tickets ...jira.JiraIssue) *SpearmanResult {
 	return twoSampleSpearmanRTest(scores, times)
 }
 
+// Reassignments performs Spearman R's test on the number of assignee changes and times-to-close.
+func Reassignments(tickets ...jira.JiraIssue) *SpearmanResult {
+	var counts stats
+	var times stats
+	for _, t := range tickets {
+		highPriority := jira.IsHighPriority(t)
+		if highPriority &&
+			t.TimeToClose > 0 &&
+			t.TimeToClose <= jira.MaxTimeToCloseH {
+			counts = append(counts, float64(analyze.ReassignmentCount(t)))
+			times = append(times, t.TimeToClose)
+		}
+	}
+	return twoSampleSpearmanRTest(counts, times)
+}
+
+// Links performs Spearman R's test on the number of distinct links referenced by tickets and times-to-close.
+func Links(tickets ...jira.JiraIssue) *SpearmanResult {
+	var counts stats
+	var times stats
+	for _, t := range tickets {
+		highPriority := jira.IsHighPriority(t)
+		if highPriority &&
+			t.TimeToClose > 0 &&
+			t.TimeToClose <= jira.MaxTimeToCloseH {
+			counts = append(counts, float64(analyze.CountLinks(t)))
+			times = append(times, t.TimeToClose)
+		}
+	}
+	return twoSampleSpearmanRTest(counts, times)
+}
+
+// DescriptionCommentRatio performs Spearman R's test on the description-to-comments words ratio and times-to-close.
+func DescriptionCommentRatio(tickets ...jira.JiraIssue) *SpearmanResult {
+	var ratios stats
+	var times stats
+	for _, t := range tickets {
+		highPriority := jira.IsHighPriority(t)
+		if highPriority &&
+			t.TimeToClose > 0 &&
+			t.TimeToClose <= jira.MaxTimeToCloseH {
+			ratios = append(ratios, analyze.DescriptionCommentRatio(t))
+			times = append(times, t.TimeToClose)
+		}
+	}
+	return twoSampleSpearmanRTest(ratios, times)
+}
+
 // twoSampleSpearmanRTest returns the rank correlation coefficient and p value given two samples.
 func twoSampleSpearmanRTest(xs, ys stats) *SpearmanResult {
 	rs, p := onlinestats.Spearman(xs, ys)
@@ -0,0 +1,69 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/nclandrei/ticketguru/jira"
+)
+
+// closedTicket returns a high priority ticket closed after the given number of hours.
+func closedTicket(key string, hours float64) jira.JiraIssue {
+	ticket := jira.JiraIssue{Key: key, TimeToClose: hours}
+	ticket.Fields.Priority.ID = "1"
+	return ticket
+}
+
+func TestReassignments(t *testing.T) {
+	var tickets []jira.JiraIssue
+	for i := 0; i < 6; i++ {
+		ticket := closedTicket("R-1", float64(10*(i+1)))
+		for j := 0; j < i; j++ {
+			ticket.Changelog.Histories = append(ticket.Changelog.Histories, jira.ChangelogHistory{
+				Items: []jira.ChangelogHistoryItem{{Field: "assignee"}},
+			})
+		}
+		tickets = append(tickets, ticket)
+	}
+	// low priority and unclosed tickets are left out of the test.
+	low := closedTicket("R-2", 1)
+	low.Fields.Priority.ID = "5"
+	tickets = append(tickets, low, closedTicket("R-3", 0))
+
+	result := Reassignments(tickets...)
+	if math.Abs(result.Rs-1) > 1e-9 {
+		t.Errorf("Spearman R of monotonically growing reassignments and times = %v, want 1", result.Rs)
+	}
+}
+
+func TestEffectOfSteps(t *testing.T) {
+	var tickets []jira.JiraIssue
+	for i, hours := range []float64{10, 12, 11, 9, 13} {
+		ticket := closedTicket(fmt.Sprintf("S-%d", i), hours)
+		ticket.HasStepsToReproduce = true
+		tickets = append(tickets, ticket)
+	}
+	var without []jira.JiraIssue
+	for i, hours := range []float64{50, 55, 48, 52, 60} {
+		without = append(without, closedTicket(fmt.Sprintf("N-%d", i), hours))
+	}
+	tickets = append(tickets, without...)
+
+	withMean, withoutMean, pct, p := EffectOfSteps(tickets...)
+	if withMean != 11 || withoutMean != 53 {
+		t.Errorf("means with and without steps = %v and %v, want 11 and 53", withMean, withoutMean)
+	}
+	if want := (11.0 - 53) / 53 * 100; math.Abs(pct-want) > 1e-9 || pct >= 0 {
+		t.Errorf("percentage difference = %v, want %v", pct, want)
+	}
+	if math.IsNaN(p) || p >= 0.01 {
+		t.Errorf("p value = %v, want a significant difference", p)
+	}
+
+	// without any ticket holding steps, only the mean without them is known.
+	withMean, withoutMean, pct, p = EffectOfSteps(without...)
+	if !math.IsNaN(withMean) || withoutMean != 53 || !math.IsNaN(pct) || !math.IsNaN(p) {
+		t.Errorf("EffectOfSteps without steps = %v, %v, %v, %v; want NaN, 53, NaN, NaN", withMean, withoutMean, pct, p)
+	}
+}
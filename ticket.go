@@ -1,8 +1,10 @@
 package ticketguru
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"strconv"
 	"strings"
 	"time"
@@ -26,6 +28,29 @@ const (
 	MaxSummaryDescWordCount = 5000
 )
 
+// SprintField holds the key of the Jira custom field holding the sprints of a ticket; it differs across
+// Jira instances, so it can be overridden before fetching or decoding tickets.
+var SprintField = "customfield_10020"
+
+// FieldMapping maps standard Jira field keys (e.g. comment) to the key, or dot separated path of nested keys
+// (e.g. custom.discussion), holding them in non-standard exports. A mapped key is only used when the standard
+// key is missing from the payload.
+var FieldMapping = map[string]string{}
+
+// LoadFieldMapping replaces FieldMapping with the mapping found inside the JSON file at path.
+func LoadFieldMapping(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read field mapping file: %v", err)
+	}
+	mapping := make(map[string]string)
+	if err := json.Unmarshal(content, &mapping); err != nil {
+		return fmt.Errorf("could not parse field mapping file %s: %v", path, err)
+	}
+	FieldMapping = mapping
+	return nil
+}
+
 // Time holds the time formatted in Jira's specific format.
 type Time time.Time
 
@@ -59,25 +84,37 @@ func (t Time) MarshalJSON() ([]byte, error) {
 
 // JiraIssue defines a Jira ticket.
 type JiraIssue struct {
-	Key                   string    `json:"key" bson:"_id"`
-	Expand                string    `json:"_"`
-	ID                    string    `json:"-"`
-	Self                  string    `json:"-"`
-	Fields                Fields    `json:"fields"`
-	Changelog             Changelog `json:"changelog"`
-	TimeToClose           float64
-	Sentiment             Sentiment
-	GrammarCorrectness    GrammarCorrectness
-	HasStackTrace         bool
-	HasStepsToReproduce   bool
-	SummaryDescWordsCount int
-	CommentWordsCount     int
+	Key                           string    `json:"key" bson:"_id"`
+	Expand                        string    `json:"_"`
+	ID                            string    `json:"-"`
+	Self                          string    `json:"-"`
+	Fields                        Fields    `json:"fields"`
+	Changelog                     Changelog `json:"changelog"`
+	TimeToClose                   float64
+	Sentiment                     Sentiment
+	GrammarCorrectness            GrammarCorrectness
+	HasStackTrace                 bool
+	HasStepsToReproduce           bool
+	SummaryDescWordsCount         int
+	SummaryWordsCount             int
+	DescriptionWordsCount         int
+	CommentWordsCount             int
+	WeightedCommentWordsCount     float64
+	CodelessSummaryDescWordsCount int
+	CodelessCommentWordsCount     int
+	HasWordCounts                 bool
+	CustomFlags                   map[string]bool
+	IsSpam                        bool
+	// AnalyzedAt holds, indexed by scorer or analysis name, when each one last computed the ticket's fields.
+	AnalyzedAt map[string]time.Time `json:",omitempty"`
 }
 
 // Sentiment holds information regarding the sentiment analysis score and if the analysis has been conducted.
 type Sentiment struct {
 	Score    float64
 	HasScore bool
+	// Providers holds the scores of the same text computed by each named sentiment provider, if several ran.
+	Providers map[string]float64 `json:",omitempty"`
 }
 
 // GrammarCorrectness holds information regarding the grammar correctness score and if the analysis has been conducted.
@@ -88,17 +125,220 @@ type GrammarCorrectness struct {
 
 // Fields defines the fields retrieved via the REST API
 type Fields struct {
-	Summary      string       `json:"summary"`
-	Description  string       `json:"description,omitempty"`
-	TimeEstimate int          `json:"timeestimate,omitempty"`
-	TimeSpent    int          `json:"timespent,omitempty"`
-	Created      Time         `json:"created"`
-	Attachments  []Attachment `json:"attachment,omitempty"`
-	Status       Status       `json:"status,omitempty"`
-	DueDate      Time         `json:"duedate,omitempty"`
-	Comments     Comments     `json:"comment,omitempty"`
-	Priority     Priority     `json:"priority,omitempty"`
-	Type         Type         `json:"issuetype,omitempty"`
+	Summary         string       `json:"summary"`
+	Description     string       `json:"description,omitempty"`
+	TimeEstimate    int          `json:"timeestimate,omitempty"`
+	TimeSpent       int          `json:"timespent,omitempty"`
+	Created         Time         `json:"created"`
+	Attachments     []Attachment `json:"attachment,omitempty"`
+	Status          Status       `json:"status,omitempty"`
+	DueDate         Time         `json:"duedate,omitempty"`
+	Comments        Comments     `json:"comment,omitempty"`
+	Priority        Priority     `json:"priority,omitempty"`
+	Type            Type         `json:"issuetype,omitempty"`
+	Sprints         []Sprint     `json:"sprints,omitempty"`
+	Environment     string       `json:"environment,omitempty"`
+	Reporter        Author       `json:"reporter,omitempty"`
+	Assignee        Author       `json:"assignee,omitempty"`
+	Resolution      Resolution   `json:"resolution,omitempty"`
+	FixVersions     []Version    `json:"fixVersions,omitempty"`
+	AffectsVersions []Version    `json:"versions,omitempty"`
+	TimeTracking    TimeTracking `json:"timetracking,omitempty"`
+}
+
+// Resolution defines the way a Jira ticket was resolved, e.g. Fixed or Duplicate.
+type Resolution struct {
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// TimeTracking defines the time tracking object returned by Jira Cloud, with all durations in seconds.
+type TimeTracking struct {
+	OriginalEstimateSeconds  int `json:"originalEstimateSeconds,omitempty"`
+	RemainingEstimateSeconds int `json:"remainingEstimateSeconds,omitempty"`
+	TimeSpentSeconds         int `json:"timeSpentSeconds,omitempty"`
+}
+
+// UnmarshalJSON decodes the standard fields, remapped according to FieldMapping, fills the flat time estimate
+// and time spent from the timetracking object if missing and, if not already present, the sprints held under
+// the instance specific SprintField, skipping the ones which cannot be parsed.
+func (f *Fields) UnmarshalJSON(b []byte) error {
+	if len(FieldMapping) > 0 {
+		remapped, err := remapFields(b)
+		if err != nil {
+			return err
+		}
+		b = remapped
+	}
+	type fields Fields
+	var aux fields
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	*f = Fields(aux)
+	if f.TimeEstimate == 0 {
+		f.TimeEstimate = f.TimeTracking.RemainingEstimateSeconds
+	}
+	if f.TimeSpent == 0 {
+		f.TimeSpent = f.TimeTracking.TimeSpentSeconds
+	}
+	// the fields are only decoded again for the sprints if their key is present, as it is missing from the
+	// tickets of most instances.
+	if len(f.Sprints) > 0 || !bytes.Contains(b, []byte(strconv.Quote(SprintField))) {
+		return nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	sprints, ok := raw[SprintField]
+	if !ok || string(sprints) == "null" {
+		return nil
+	}
+	// Sprints are parsed leniently: a malformed sprint, or a sprint field of an unexpected shape, is skipped
+	// rather than failing the whole ticket.
+	var entries []json.RawMessage
+	if err := json.Unmarshal(sprints, &entries); err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		var sprint Sprint
+		if err := json.Unmarshal(entry, &sprint); err == nil {
+			f.Sprints = append(f.Sprints, sprint)
+		}
+	}
+	return nil
+}
+
+// remapFields copies the values found under the mapped keys of FieldMapping to their standard keys.
+func remapFields(b []byte) ([]byte, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return b, nil
+	}
+	var changed bool
+	for standard, path := range FieldMapping {
+		if _, ok := raw[standard]; ok {
+			continue
+		}
+		if value, ok := lookupPath(raw, strings.Split(path, ".")); ok {
+			raw[standard] = value
+			changed = true
+		}
+	}
+	if !changed {
+		return b, nil
+	}
+	return json.Marshal(raw)
+}
+
+// lookupPath returns the value found under a path of nested keys inside a decoded JSON object.
+func lookupPath(raw map[string]json.RawMessage, path []string) (json.RawMessage, bool) {
+	value, ok := raw[path[0]]
+	if !ok || len(path) == 1 {
+		return value, ok
+	}
+	var nested map[string]json.RawMessage
+	if err := json.Unmarshal(value, &nested); err != nil {
+		return nil, false
+	}
+	return lookupPath(nested, path[1:])
+}
+
+// Version defines a project version (release) a Jira ticket affects or is fixed in.
+type Version struct {
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Archived    bool   `json:"archived,omitempty"`
+	Released    bool   `json:"released,omitempty"`
+	ReleaseDate Time   `json:"releaseDate,omitempty"`
+}
+
+// Sprint defines an agile sprint a Jira ticket has been part of.
+type Sprint struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name,omitempty"`
+	State     string `json:"state,omitempty"`
+	StartDate Time   `json:"startDate,omitempty"`
+	EndDate   Time   `json:"endDate,omitempty"`
+}
+
+// UnmarshalJSON decodes a sprint from either the modern object format or the legacy greenhopper string
+// format (e.g. "com.atlassian.greenhopper.service.sprint.Sprint@1a2b[id=1,state=CLOSED,name=Sprint 1,...]").
+func (s *Sprint) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		var legacy string
+		if err := json.Unmarshal(b, &legacy); err != nil {
+			return err
+		}
+		return s.parseLegacy(legacy)
+	}
+	var aux struct {
+		ID        int    `json:"id"`
+		Name      string `json:"name"`
+		State     string `json:"state"`
+		StartDate string `json:"startDate"`
+		EndDate   string `json:"endDate"`
+	}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	return s.set(strconv.Itoa(aux.ID), aux.Name, aux.State, aux.StartDate, aux.EndDate)
+}
+
+// parseLegacy parses the greenhopper string format of a sprint.
+func (s *Sprint) parseLegacy(legacy string) error {
+	start, end := strings.Index(legacy, "["), strings.LastIndex(legacy, "]")
+	if start < 0 || end < start {
+		return fmt.Errorf("could not parse sprint %q: missing attribute list", legacy)
+	}
+	attrs := make(map[string]string)
+	var lastKey string
+	for _, part := range strings.Split(legacy[start+1:end], ",") {
+		eq := strings.Index(part, "=")
+		if eq <= 0 || strings.ContainsAny(part[:eq], " ") {
+			// Values such as sprint names may contain commas, so append to the previous attribute.
+			if lastKey != "" {
+				attrs[lastKey] += "," + part
+			}
+			continue
+		}
+		lastKey = part[:eq]
+		attrs[lastKey] = part[eq+1:]
+	}
+	return s.set(attrs["id"], attrs["name"], attrs["state"], attrs["startDate"], attrs["endDate"])
+}
+
+// set assigns the sprint fields from their string representations.
+func (s *Sprint) set(id, name, state, startDate, endDate string) error {
+	var err error
+	if s.ID, err = strconv.Atoi(id); err != nil {
+		return fmt.Errorf("could not parse sprint id %q: %v", id, err)
+	}
+	s.Name = name
+	s.State = strings.ToLower(state)
+	if s.StartDate, err = parseSprintTime(startDate); err != nil {
+		return err
+	}
+	s.EndDate, err = parseSprintTime(endDate)
+	return err
+}
+
+// parseSprintTime parses the different timestamp formats Jira uses for sprint dates.
+func parseSprintTime(s string) (Time, error) {
+	if s == "" || s == "<null>" || s == "null" {
+		return Time(time.Time{}), nil
+	}
+	for _, layout := range []string{timeFormat, "2006-01-02T15:04:05.000Z07:00", time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return Time(t), nil
+		}
+	}
+	return Time(time.Time{}), fmt.Errorf("could not parse sprint time %q", s)
 }
 
 // TicketKey returns the unique key of a Jira issue.
@@ -106,6 +346,21 @@ func (t *JiraIssue) TicketKey() string {
 	return t.Key
 }
 
+// MarkAnalyzed records that the named scorer or analysis computed the fields of a Jira issue at the given time.
+func (t *JiraIssue) MarkAnalyzed(analysis string, at time.Time) {
+	if t.AnalyzedAt == nil {
+		t.AnalyzedAt = make(map[string]time.Time)
+	}
+	t.AnalyzedAt[analysis] = at
+}
+
+// AnalyzedBefore returns whether the named scorer or analysis last computed the fields of a Jira issue before
+// cutoff or never did.
+func (t *JiraIssue) AnalyzedBefore(analysis string, cutoff time.Time) bool {
+	at, ok := t.AnalyzedAt[analysis]
+	return !ok || at.Before(cutoff)
+}
+
 // TicketBody returns the JSON encoded value of a Jira issue.
 func (t *JiraIssue) TicketBody() ([]byte, error) {
 	res, err := json.Marshal(t)
@@ -199,10 +454,21 @@ type Priority struct {
 
 // Status defines the Jira ticket status.
 type Status struct {
-	ID             string   `json:"id,omitempty"`
-	Description    string   `json:"description,omitempty"`
-	Name           string   `json:"name,omitempty"`
-	StatusCategory struct{} `json:"-"`
+	ID             string         `json:"id,omitempty"`
+	Description    string         `json:"description,omitempty"`
+	Name           string         `json:"name,omitempty"`
+	StatusCategory StatusCategory `json:"statusCategory,omitempty"`
+}
+
+// DoneStatusCategory represents the key of the status category shared by all terminal statuses.
+const DoneStatusCategory = "done"
+
+// StatusCategory defines the generic category (e.g. To Do, In Progress, Done) a Jira status belongs to
+// regardless of the workflow it is part of.
+type StatusCategory struct {
+	ID   int    `json:"id,omitempty"`
+	Key  string `json:"key,omitempty"`
+	Name string `json:"name,omitempty"`
 }
 
 // Comments defines the Jira field that holds the comments.
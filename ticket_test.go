@@ -0,0 +1,156 @@
+package ticketguru
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestFieldsUnmarshalSprints(t *testing.T) {
+	for name, payload := range map[string]string{
+		"objects": `{"summary": "s", "customfield_10020": [
+			{"id": 7, "name": "Sprint 7", "state": "CLOSED", "startDate": "2018-01-01T09:00:00.000Z",
+			 "endDate": "2018-01-15T09:00:00.000Z"}]}`,
+		"legacy": `{"summary": "s", "customfield_10020": [
+			"com.atlassian.greenhopper.service.sprint.Sprint@1a2b[id=7,rapidViewId=3,state=CLOSED,name=Sprint 7,startDate=2018-01-01T09:00:00.000Z,endDate=2018-01-15T09:00:00.000Z]"]}`,
+	} {
+		var fields Fields
+		if err := json.Unmarshal([]byte(payload), &fields); err != nil {
+			t.Errorf("%s: could not decode fields: %v", name, err)
+			continue
+		}
+		if len(fields.Sprints) != 1 {
+			t.Errorf("%s: decoded %d sprints, want 1", name, len(fields.Sprints))
+			continue
+		}
+		sprint := fields.Sprints[0]
+		if sprint.ID != 7 || sprint.Name != "Sprint 7" || sprint.State != "closed" {
+			t.Errorf("%s: decoded sprint %+v", name, sprint)
+		}
+		want := time.Date(2018, 1, 15, 9, 0, 0, 0, time.UTC)
+		if !time.Time(sprint.EndDate).Equal(want) {
+			t.Errorf("%s: sprint ends at %v, want %v", name, time.Time(sprint.EndDate), want)
+		}
+	}
+}
+
+func TestFieldsUnmarshalLegacySprintNameWithComma(t *testing.T) {
+	payload := `{"customfield_10020": ["Sprint@1[id=2,state=ACTIVE,name=Sprint 2, hardening,startDate=<null>,endDate=<null>]"]}`
+	var fields Fields
+	if err := json.Unmarshal([]byte(payload), &fields); err != nil {
+		t.Fatalf("could not decode fields: %v", err)
+	}
+	if got := fields.Sprints[0].Name; got != "Sprint 2, hardening" {
+		t.Errorf("sprint name = %q, want %q", got, "Sprint 2, hardening")
+	}
+	if !time.Time(fields.Sprints[0].StartDate).IsZero() {
+		t.Errorf("null start date decoded as %v", time.Time(fields.Sprints[0].StartDate))
+	}
+}
+
+func TestFieldsUnmarshalSkipsMalformedSprints(t *testing.T) {
+	payload := `{"summary": "s", "customfield_10020": [
+		{"id": 7, "name": "Sprint 7", "startDate": "next monday"},
+		"Sprint@1[rapidViewId=3,state=ACTIVE,name=Sprint 8]",
+		"Sprint@1[id=9,state=ACTIVE,name=Sprint 9,startDate=<null>,endDate=<null>]"]}`
+	var fields Fields
+	if err := json.Unmarshal([]byte(payload), &fields); err != nil {
+		t.Fatalf("could not decode fields with malformed sprints: %v", err)
+	}
+	if fields.Summary != "s" || len(fields.Sprints) != 1 || fields.Sprints[0].ID != 9 {
+		t.Errorf("decoded summary %q and sprints %+v, want s and only Sprint 9", fields.Summary, fields.Sprints)
+	}
+
+	if err := json.Unmarshal([]byte(`{"summary": "s", "customfield_10020": "Sprint 9"}`), &fields); err != nil {
+		t.Errorf("could not decode fields with a sprint field of an unexpected shape: %v", err)
+	}
+}
+
+func TestFieldsUnmarshalCustomSprintField(t *testing.T) {
+	previous := SprintField
+	SprintField = "customfield_10100"
+	defer func() { SprintField = previous }()
+
+	var fields Fields
+	if err := json.Unmarshal([]byte(`{"customfield_10100": [{"id": 3, "name": "Sprint 3"}]}`), &fields); err != nil {
+		t.Fatalf("could not decode fields: %v", err)
+	}
+	if len(fields.Sprints) != 1 || fields.Sprints[0].Name != "Sprint 3" {
+		t.Errorf("decoded sprints %+v from the custom sprint field", fields.Sprints)
+	}
+}
+
+func TestStatusUnmarshalCategory(t *testing.T) {
+	payload := `{"id": "10001", "name": "Shipped", "statusCategory": {"id": 3, "key": "done", "name": "Done"}}`
+	var status Status
+	if err := json.Unmarshal([]byte(payload), &status); err != nil {
+		t.Fatalf("could not decode status: %v", err)
+	}
+	want := StatusCategory{ID: 3, Key: DoneStatusCategory, Name: "Done"}
+	if status.StatusCategory != want {
+		t.Errorf("decoded status category %+v, want %+v", status.StatusCategory, want)
+	}
+}
+
+func TestFieldsUnmarshalVersions(t *testing.T) {
+	payload := `{"fixVersions": [{"id": "1", "name": "2.0", "released": true, "releaseDate": "2018-03-01"},
+		{"id": "2", "name": "2.1"}], "versions": [{"id": "0", "name": "1.9", "archived": true}]}`
+	var fields Fields
+	if err := json.Unmarshal([]byte(payload), &fields); err != nil {
+		t.Fatalf("could not decode fields: %v", err)
+	}
+	if len(fields.FixVersions) != 2 || fields.FixVersions[0].Name != "2.0" || fields.FixVersions[1].Name != "2.1" {
+		t.Fatalf("decoded fix versions %+v", fields.FixVersions)
+	}
+	want := time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)
+	if !fields.FixVersions[0].Released || !time.Time(fields.FixVersions[0].ReleaseDate).Equal(want) {
+		t.Errorf("decoded fix version %+v, want released on %v", fields.FixVersions[0], want)
+	}
+	if len(fields.AffectsVersions) != 1 || fields.AffectsVersions[0].Name != "1.9" || !fields.AffectsVersions[0].Archived {
+		t.Errorf("decoded affected versions %+v", fields.AffectsVersions)
+	}
+}
+
+func TestFieldsUnmarshalFieldMapping(t *testing.T) {
+	previous := FieldMapping
+	FieldMapping = map[string]string{"comment": "custom.discussion", "summary": "title"}
+	defer func() { FieldMapping = previous }()
+
+	payload := `{"title": "Login fails", "custom": {"discussion": {"comments": [{"id": "1", "body": "same here"}]}}}`
+	var fields Fields
+	if err := json.Unmarshal([]byte(payload), &fields); err != nil {
+		t.Fatalf("could not decode fields: %v", err)
+	}
+	if fields.Summary != "Login fails" {
+		t.Errorf("remapped summary = %q, want %q", fields.Summary, "Login fails")
+	}
+	if len(fields.Comments.Comments) != 1 || fields.Comments.Comments[0].Body != "same here" {
+		t.Errorf("remapped comments = %+v", fields.Comments.Comments)
+	}
+
+	// the standard key wins over the mapped one.
+	if err := json.Unmarshal([]byte(`{"summary": "standard", "title": "mapped"}`), &fields); err != nil {
+		t.Fatalf("could not decode fields: %v", err)
+	}
+	if fields.Summary != "standard" {
+		t.Errorf("summary = %q, want the standard key's value", fields.Summary)
+	}
+}
+
+func TestFieldsUnmarshalTimeTracking(t *testing.T) {
+	for name, payload := range map[string]string{
+		"flat": `{"timeestimate": 7200, "timespent": 3600}`,
+		"nested": `{"timetracking": {"originalEstimateSeconds": 10800, "remainingEstimateSeconds": 7200,
+			"timeSpentSeconds": 3600}}`,
+	} {
+		var fields Fields
+		if err := json.Unmarshal([]byte(payload), &fields); err != nil {
+			t.Errorf("%s: could not decode fields: %v", name, err)
+			continue
+		}
+		if fields.TimeEstimate != 7200 || fields.TimeSpent != 3600 {
+			t.Errorf("%s: time estimate and time spent = %d, %d; want 7200, 3600", name, fields.TimeEstimate,
+				fields.TimeSpent)
+		}
+	}
+}